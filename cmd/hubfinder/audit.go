@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/audit"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// runAudit implements "hubfinder audit", which checks every hub for
+// data-quality problems.
+func runAudit(args []string) error {
+	fs := flag.NewFlagSet("hubfinder audit", flag.ContinueOnError)
+	var inputs inputsFlag
+	fs.Var(&inputs, "in", "snapshot file or bbolt database to read hubs from (repeatable; reads Cloudant if omitted)")
+	oceanDistance := fs.Float64("ocean-distance", 200, "distance in km from land beyond which hubs are reported as in the ocean")
+	minDecimals := fs.Int("min-decimals", 2, "number of decimal places below which coordinates are reported as imprecise")
+	format := fs.String("format", "text", "report format: text (summary and findings) or json")
+	logLevel := fs.String("log-level", "warn", "minimum log level: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "log output format: text or json")
+	url := fs.String("url", baseURL, "without --in, Cloudant or CouchDB service URL")
+	dbName := fs.String("db", db, "without --in, database to read hubs from")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *oceanDistance <= 0 {
+		return usage(fmt.Errorf("--ocean-distance must be positive"))
+	}
+	if *minDecimals <= 0 {
+		return usage(fmt.Errorf("--min-decimals must be positive"))
+	}
+	if *format != "text" && *format != "json" {
+		return usage(fmt.Errorf("--format must be text or json, got %q", *format))
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		return usage(err)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	hubs, skipped, err := readHubs(ctx, inputs, repository.CloudantConfig{BaseURL: *url, DB: *dbName, Logger: logger}, true)
+	if err != nil {
+		return err
+	}
+	report := audit.Run(hubs, skipped, audit.Config{OceanDistanceKm: *oceanDistance, MinDecimals: *minDecimals})
+
+	if *format == "json" {
+		err = writeAuditJSON(os.Stdout, report)
+	} else {
+		err = printAuditReport(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("print report: %w", err)
+	}
+	if errs := report.Errors(); errs > 0 {
+		return fmt.Errorf("audit found %d error(s)", errs)
+	}
+	return nil
+}
+
+// printAuditReport writes the number of findings of every check followed
+// by the findings themselves.
+func printAuditReport(out io.Writer, report audit.Report) error {
+	fmt.Fprintf(out, "Audited %d hubs: %d finding(s), %d of them errors.\n\n", report.Hubs, len(report.Findings), report.Errors())
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Check\tSeverity\tFindings")
+	fmt.Fprintln(w, "-----\t--------\t--------")
+	for _, check := range audit.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", check, check.Severity(), report.Counts[check])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Findings) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Check\tSeverity\tID\tProblem")
+	fmt.Fprintln(w, "-----\t--------\t--\t-------")
+	for _, finding := range report.Findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", finding.Check, finding.Severity, finding.ID, finding.Message)
+	}
+	return w.Flush()
+}
+
+// writeAuditJSON writes report as indented JSON.
+func writeAuditJSON(out io.Writer, report audit.Report) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
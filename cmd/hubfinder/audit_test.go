@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/audit"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// fakeChangesFeed serves docs as the _changes feed of a Cloudant database,
+// one document per page.
+func fakeChangesFeed(t *testing.T, docs []map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{db}/_changes", func(w http.ResponseWriter, r *http.Request) {
+		since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+		results := []any{}
+		if since < len(docs) {
+			doc := docs[since]
+			results = append(results, map[string]any{"seq": strconv.Itoa(since + 1), "id": doc["_id"], "doc": doc})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"results":  results,
+			"last_seq": strconv.Itoa(since + len(results)),
+			"pending":  max(len(docs)-since-1, 0),
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadHubs_AuditsCloudantOutOfRange(t *testing.T) {
+	server := fakeChangesFeed(t, []map[string]any{
+		{"_id": "bud", "lat": 47.4394, "lon": 19.2618, "name": "Budapest Liszt Ferenc"},
+		{"_id": "swapped", "lat": 120.5, "lon": 45.2, "name": "Swapped"},
+		{"_id": "far", "lat": 95.5, "lon": 190.25, "name": "Nowhere"},
+		{"_id": "text", "lat": "north", "lon": 19.25, "name": "Text"},
+		{"_id": "_design/view1", "language": "javascript"},
+	})
+
+	hubs, skipped, err := readHubs(context.Background(), nil, repository.CloudantConfig{BaseURL: server.URL, DB: "airportdb"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hubs) != 3 || len(skipped) != 1 {
+		t.Fatalf("got %d hubs and %d skipped rows, want 3 and 1", len(hubs), len(skipped))
+	}
+
+	report := audit.Run(hubs, skipped, audit.Config{})
+	want := map[audit.Check]string{
+		audit.CheckSwapped:      "swapped",
+		audit.CheckOutOfRange:   "far",
+		audit.CheckMalformedRow: "text",
+	}
+	for check, id := range want {
+		if report.Counts[check] != 1 {
+			t.Errorf("got %d %s findings, want 1", report.Counts[check], check)
+		}
+		for _, finding := range report.Findings {
+			if finding.Check == check && finding.ID != id {
+				t.Errorf("got %s finding for %s, want %s", check, finding.ID, id)
+			}
+		}
+	}
+	if got := report.Errors(); got != 3 {
+		t.Errorf("got %d errors, want 3", got)
+	}
+}
+
+func TestReadHubs_SkipsCloudantOutOfRange(t *testing.T) {
+	server := fakeChangesFeed(t, []map[string]any{
+		{"_id": "bud", "lat": 47.4394, "lon": 19.2618, "name": "Budapest Liszt Ferenc"},
+		{"_id": "swapped", "lat": 120.5, "lon": 45.2, "name": "Swapped"},
+	})
+
+	hubs, skipped, err := readHubs(context.Background(), nil, repository.CloudantConfig{BaseURL: server.URL, DB: "airportdb"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hubs) != 1 || hubs[0].ID != "bud" {
+		t.Errorf("got hubs %v, want only bud", hubs)
+	}
+	if len(skipped) != 1 || skipped[0].ID != "swapped" {
+		t.Errorf("got skipped rows %v, want only swapped", skipped)
+	}
+}
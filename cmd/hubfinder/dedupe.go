@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/dedupe"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// runDedupe implements "hubfinder dedupe", which reports hubs that are
+// likely duplicates and optionally writes the data set without them.
+func runDedupe(args []string) error {
+	fs := flag.NewFlagSet("hubfinder dedupe", flag.ContinueOnError)
+	var inputs inputsFlag
+	fs.Var(&inputs, "in", "snapshot file or bbolt database to read hubs from, in priority order (repeatable; reads Cloudant if omitted)")
+	radius := fs.Float64("radius", 2, "distance in km within which similarly named hubs are candidates")
+	similarity := fs.Float64("name-similarity", 0.8, "minimum name similarity of nearby candidates, from 0 to 1")
+	format := fs.String("format", "text", "report format: text or json")
+	out := fs.String("out", "", "snapshot file to write the hubs to with the duplicates removed")
+	logLevel := fs.String("log-level", "warn", "minimum log level: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "log output format: text or json")
+	url := fs.String("url", baseURL, "without --in, Cloudant or CouchDB service URL")
+	dbName := fs.String("db", db, "without --in, database to read hubs from")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *radius <= 0 {
+		return usage(fmt.Errorf("--radius must be positive"))
+	}
+	if *similarity <= 0 || *similarity > 1 {
+		return usage(fmt.Errorf("--name-similarity must be above 0 and at most 1"))
+	}
+	if *format != "text" && *format != "json" {
+		return usage(fmt.Errorf("--format must be text or json, got %q", *format))
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		return usage(err)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	hubs, skipped, err := readHubs(ctx, inputs, repository.CloudantConfig{BaseURL: *url, DB: *dbName, Logger: logger}, false)
+	if err != nil {
+		return err
+	}
+	for _, warning := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: skipped malformed %s\n", warning)
+	}
+	report, err := dedupe.Find(hubs, dedupe.Config{RadiusKm: *radius, NameSimilarity: *similarity})
+	if err != nil {
+		return fmt.Errorf("find duplicates: %w", err)
+	}
+
+	if *format == "json" {
+		err = writeDedupeJSON(os.Stdout, report)
+	} else {
+		err = printDedupeReport(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("print report: %w", err)
+	}
+
+	if *out != "" {
+		merged := report.Merge(hubs)
+		if err := repository.WriteSnapshot(*out, &repository.Snapshot{CreatedAt: time.Now().UTC(), Hubs: merged}); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d hubs to %s\n", len(merged), *out)
+	}
+	return nil
+}
+
+// printDedupeReport writes report as one table of candidate pairs per
+// group, marking the hub that would be kept.
+func printDedupeReport(out io.Writer, report dedupe.Report) error {
+	fmt.Fprintf(out, "Examined %d hubs: %d group(s) of likely duplicates, %d hub(s) to remove.\n",
+		report.Hubs, len(report.Groups), report.Duplicates())
+
+	for g, group := range report.Groups {
+		fmt.Fprintf(out, "\nGroup %d: keeping %s (%s)\n", g+1, group.Hubs[0].ID, group.Hubs[0].Name)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Hub\tDuplicate\tDistance (km)\tName similarity\tReasons")
+		fmt.Fprintln(w, "---\t---------\t-------------\t---------------\t-------")
+		for _, candidate := range group.Candidates {
+			reasons := make([]string, len(candidate.Reasons))
+			for i, reason := range candidate.Reasons {
+				reasons[i] = string(reason)
+			}
+			fmt.Fprintf(w, "%s (%s)\t%s (%s)\t%.3f\t%.2f\t%s\n",
+				candidate.A.ID, candidate.A.Name,
+				candidate.B.ID, candidate.B.Name,
+				candidate.DistanceKm,
+				candidate.NameSimilarity,
+				strings.Join(reasons, ", "),
+			)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDedupeJSON writes report as indented JSON.
+func writeDedupeJSON(out io.Writer, report dedupe.Report) error {
+	if report.Groups == nil {
+		report.Groups = []dedupe.Group{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
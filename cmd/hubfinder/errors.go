@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// Exit codes reported by main, so scripts can tell bad input from backend
+// outages.
+const (
+	exitFailure     = 1
+	exitUsage       = 2
+	exitUnavailable = 3
+	exitBackend     = 4
+	exitData        = 5
+	exitNotFound    = 6
+	exitConflict    = 7
+	exitInterrupted = 130
+)
+
+// usageError marks errors caused by invalid flags or input.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// usage marks err as caused by invalid flags or input.
+func usage(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err: err}
+}
+
+// exitCode maps the error returned by run to the process exit code.
+func exitCode(err error) int {
+	var (
+		usageErr   *usageError
+		backendErr *repository.BackendError
+		malformed  *repository.MalformedRowError
+	)
+	switch {
+	case errors.As(err, &usageErr), finder.IsInvalidArgument(err), errors.Is(err, repository.ErrMissingID):
+		return exitUsage
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitUnavailable
+	case errors.Is(err, repository.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return exitConflict
+	case errors.As(err, &backendErr):
+		if backendErr.Retryable {
+			return exitUnavailable
+		}
+		return exitBackend
+	case errors.As(err, &malformed):
+		return exitData
+	default:
+		return exitFailure
+	}
+}
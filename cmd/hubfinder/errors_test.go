@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"usage", usage(errors.New("--limit cannot be negative")), exitUsage},
+		{"invalid latitude", fmt.Errorf("find nearby hubs: %w", geo.ErrInvalidLatitude), exitUsage},
+		{"negative offset", fmt.Errorf("find nearby hubs: %w", finder.ErrNegativeOffset), exitUsage},
+		{"interrupted", fmt.Errorf("find nearby hubs: %w", context.Canceled), exitInterrupted},
+		{"timeout", fmt.Errorf("find nearby hubs: %w", context.DeadlineExceeded), exitUnavailable},
+		{"not found", fmt.Errorf("get hub: %w", repository.ErrNotFound), exitNotFound},
+		{"conflict", fmt.Errorf("update hub: %w", repository.ErrConflict), exitConflict},
+		{"missing ID", fmt.Errorf("add hub: %w", repository.ErrMissingID), exitUsage},
+		{"retryable backend", &repository.BackendError{Op: "post search", StatusCode: 503, Retryable: true, Err: errors.New("down")}, exitUnavailable},
+		{"permanent backend", &repository.BackendError{Op: "post search", StatusCode: 401, Err: errors.New("unauthorized")}, exitBackend},
+		{"malformed row", &repository.MalformedRowError{RowWarning: repository.RowWarning{ID: "x", Reason: "missing lat"}}, exitData},
+		{"other", errors.New("boom"), exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.expected {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunInvalidFlagsAreUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"--limit", "-1"},
+		{"--unit", "furlong"},
+		{"--location", "north pole"},
+		{"--no-such-flag"},
+		{"--location", "47.5 19.0", "--radius", "10", "--snapshot", "does-not-exist.json"},
+		{"serve", "--timeout", "-1s"},
+		{"snapshot"},
+		{"sync"},
+		{"sync", "--store", "hubs.json", "--batch-size", "0"},
+		{"hub"},
+		{"hub", "rename"},
+		{"hub", "add", "--id", "X", "--name", "X"},
+		{"hub", "add", "--id", "X", "--name", "X", "--location", "north pole"},
+		{"hub", "update", "--id", "X"},
+		{"hub", "delete"},
+		{"import"},
+		{"import", "--file", "does-not-exist.csv"},
+		{"import", "--file", "airports.csv", "--concurrency", "0"},
+		{"init-db", "--no-such-flag"},
+		{"init-db", "--backend", "lucene"},
+		{"--backend", "lucene", "--location", "47.5 19.0", "--radius", "10"},
+	}
+
+	for _, args := range tests {
+		if got := exitCode(run(args)); got != exitUsage {
+			t.Errorf("run(%q) exit code = %d, want %d", args, got, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/coord"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// writeFlags are the connection flags shared by the commands that write to
+// a database. Credentials come from the environment, using the Cloudant
+// SDK variables such as CLOUDANT_APIKEY or CLOUDANT_USERNAME and
+// CLOUDANT_PASSWORD (see connectionConfig).
+type writeFlags struct {
+	url       *string
+	db        *string
+	logLevel  *string
+	logFormat *string
+}
+
+func addWriteFlags(fs *flag.FlagSet) writeFlags {
+	return writeFlags{
+		url:       fs.String("url", baseURL, "Cloudant service URL"),
+		db:        fs.String("db", db, "database to write to"),
+		logLevel:  fs.String("log-level", "warn", "minimum log level: debug, info, warn or error"),
+		logFormat: fs.String("log-format", "text", "log output format: text or json"),
+	}
+}
+
+// config returns the repository configuration selected by the flags and
+// sets up logging.
+func (w writeFlags) config() (repository.CloudantConfig, error) {
+	logger, err := newLogger(os.Stderr, *w.logLevel, *w.logFormat)
+	if err != nil {
+		return repository.CloudantConfig{}, usage(err)
+	}
+	slog.SetDefault(logger)
+
+	return connectionConfig(repository.CloudantConfig{
+		BaseURL: *w.url,
+		DB:      *w.db,
+		Logger:  logger,
+	})
+}
+
+// open creates the Cloudant repository selected by the flags.
+func (w writeFlags) open() (*repository.CloudantRepository, error) {
+	cfg, err := w.config()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repository.NewCloudantRepository(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create repository: %w", err)
+	}
+	return repo, nil
+}
+
+// runHub implements "hubfinder hub add|update|delete", which edit single
+// hubs in the database.
+func runHub(args []string) error {
+	if len(args) == 0 {
+		return usage(fmt.Errorf("hub: expected add, update or delete"))
+	}
+	switch args[0] {
+	case "add":
+		return runHubAdd(args[1:])
+	case "update":
+		return runHubUpdate(args[1:])
+	case "delete":
+		return runHubDelete(args[1:])
+	default:
+		return usage(fmt.Errorf("hub: unknown command %q, expected add, update or delete", args[0]))
+	}
+}
+
+func runHubAdd(args []string) error {
+	fs := flag.NewFlagSet("hubfinder hub add", flag.ContinueOnError)
+	conn := addWriteFlags(fs)
+	id := fs.String("id", "", "ID of the new hub (required)")
+	name := fs.String("name", "", "name of the new hub (required)")
+	location := fs.String("location", "", "position of the new hub in any notation accepted by --location (required)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *id == "" || *name == "" || *location == "" {
+		return usage(fmt.Errorf("--id, --name and --location are required"))
+	}
+	position, err := coord.Parse(*location)
+	if err != nil {
+		return usage(fmt.Errorf("--location: %w", err))
+	}
+
+	repo, err := conn.open()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	rev, err := repo.Put(ctx, model.Hub{ID: *id, Name: *name, Lat: position.Lat, Lon: position.Lon}, "")
+	if err != nil {
+		return fmt.Errorf("add hub: %w", err)
+	}
+	fmt.Printf("Added hub %s at revision %s\n", *id, rev)
+	return nil
+}
+
+func runHubUpdate(args []string) error {
+	fs := flag.NewFlagSet("hubfinder hub update", flag.ContinueOnError)
+	conn := addWriteFlags(fs)
+	id := fs.String("id", "", "ID of the hub to update (required)")
+	name := fs.String("name", "", "new name (unchanged if empty)")
+	location := fs.String("location", "", "new position in any notation accepted by --location (unchanged if empty)")
+	rev := fs.String("rev", "", "revision the update is based on; fails if the hub has changed since (defaults to the current revision)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *id == "" {
+		return usage(fmt.Errorf("--id is required"))
+	}
+	if *name == "" && *location == "" {
+		return usage(fmt.Errorf("nothing to update; pass --name or --location"))
+	}
+	var position coord.Position
+	if *location != "" {
+		parsed, err := coord.Parse(*location)
+		if err != nil {
+			return usage(fmt.Errorf("--location: %w", err))
+		}
+		position = parsed
+	}
+
+	repo, err := conn.open()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *rev == "" {
+		if *rev, err = repo.Revision(ctx, *id); err != nil {
+			return fmt.Errorf("update hub: %w", err)
+		}
+	}
+	hub, err := repo.GetHub(ctx, *id)
+	var malformed *repository.MalformedRowError
+	switch {
+	case errors.As(err, &malformed) && *name != "" && *location != "":
+		// Both fields are replaced, so the update repairs the document.
+		hub = model.Hub{ID: *id}
+	case err != nil:
+		return fmt.Errorf("update hub: %w", err)
+	}
+	if *name != "" {
+		hub.Name = *name
+	}
+	if *location != "" {
+		hub.Lat, hub.Lon = position.Lat, position.Lon
+	}
+
+	newRev, err := repo.Put(ctx, hub, *rev)
+	if err != nil {
+		return fmt.Errorf("update hub: %w", err)
+	}
+	fmt.Printf("Updated hub %s to revision %s\n", *id, newRev)
+	return nil
+}
+
+func runHubDelete(args []string) error {
+	fs := flag.NewFlagSet("hubfinder hub delete", flag.ContinueOnError)
+	conn := addWriteFlags(fs)
+	id := fs.String("id", "", "ID of the hub to delete (required)")
+	rev := fs.String("rev", "", "revision to delete; fails if the hub has changed since (defaults to the current revision)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *id == "" {
+		return usage(fmt.Errorf("--id is required"))
+	}
+
+	repo, err := conn.open()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *rev == "" {
+		if *rev, err = repo.Revision(ctx, *id); err != nil {
+			return fmt.Errorf("delete hub: %w", err)
+		}
+	}
+	if err := repo.Delete(ctx, *id, *rev); err != nil {
+		return fmt.Errorf("delete hub: %w", err)
+	}
+	fmt.Printf("Deleted hub %s\n", *id)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/hubimport"
+)
+
+// runImport implements "hubfinder import", which loads hubs from a CSV
+// file into the database.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("hubfinder import", flag.ContinueOnError)
+	conn := addWriteFlags(fs)
+	file := fs.String("file", "", "CSV file with name, lat and lon columns and optional id, type, country, routes and passenger_class columns (required)")
+	batchSize := fs.Int("batch-size", 500, "number of hubs written per _bulk_docs request")
+	concurrency := fs.Int("concurrency", 4, "number of requests in flight at once")
+	dryRun := fs.Bool("dry-run", false, "only validate the file")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *file == "" {
+		return usage(fmt.Errorf("--file is required"))
+	}
+	if *batchSize <= 0 {
+		return usage(fmt.Errorf("--batch-size must be positive"))
+	}
+	if *concurrency <= 0 {
+		return usage(fmt.Errorf("--concurrency must be positive"))
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return usage(fmt.Errorf("--file: %w", err))
+	}
+	defer in.Close()
+	records, err := hubimport.ReadCSV(in)
+	if err != nil {
+		return usage(fmt.Errorf("--file: %w", err))
+	}
+
+	if *dryRun {
+		invalid := 0
+		for _, record := range records {
+			if record.Err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid: %s\n", hubimport.Problem{Line: record.Line, ID: record.Hub.ID, Err: record.Err})
+				invalid++
+			}
+		}
+		fmt.Printf("Checked %d record(s): %d valid, %d invalid.\n", len(records), len(records)-invalid, invalid)
+		if invalid > 0 {
+			return usage(fmt.Errorf("%d invalid record(s) in %s", invalid, *file))
+		}
+		return nil
+	}
+
+	repo, err := conn.open()
+	if err != nil {
+		return err
+	}
+	importer, err := hubimport.New(hubimport.Config{
+		Target:      repo,
+		BatchSize:   *batchSize,
+		Concurrency: *concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := importer.Import(ctx, records)
+	for _, problem := range report.Problems {
+		fmt.Fprintf(os.Stderr, "Not imported: %s\n", problem)
+	}
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	fmt.Printf("Imported %d record(s): %d written, %d unchanged, %d invalid, %d conflict(s), %d failed.\n",
+		report.Records, report.Written, report.Unchanged, report.Invalid, report.Conflicts, report.Failed)
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("%d record(s) were not imported; fix them and run the import again", len(report.Problems))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// runInitDB implements "hubfinder init-db", which creates the database and
+// the index the other commands query.
+func runInitDB(args []string) error {
+	fs := flag.NewFlagSet("hubfinder init-db", flag.ContinueOnError)
+	conn := addWriteFlags(fs)
+	backend := fs.String("backend", "search", "query backend to create the index for: search, mango or geo")
+	replace := fs.Bool("replace", false, "overwrite an existing index that differs from the expected one")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+
+	var err error
+	var repo repository.Provisioner
+	switch *backend {
+	case "search":
+		if repo, err = conn.open(); err != nil {
+			return err
+		}
+	case "mango":
+		cfg, err := conn.config()
+		if err != nil {
+			return err
+		}
+		cfg.Ddoc, cfg.Index = "", ""
+		if repo, err = repository.NewMangoRepository(cfg); err != nil {
+			return fmt.Errorf("create repository: %w", err)
+		}
+	case "geo":
+		cfg, err := conn.config()
+		if err != nil {
+			return err
+		}
+		cfg.Ddoc, cfg.Index = "", ""
+		if repo, err = repository.NewGeoRepository(cfg); err != nil {
+			return fmt.Errorf("create repository: %w", err)
+		}
+	default:
+		return usage(fmt.Errorf("--backend must be search, mango or geo, got %q", *backend))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := repo.Provision(ctx, repository.ProvisionOptions{Replace: *replace})
+	if report.CreatedDatabase {
+		fmt.Printf("Created database %s\n", *conn.db)
+	}
+	for _, name := range report.Created {
+		fmt.Printf("Created index %s\n", name)
+	}
+	for _, name := range report.Replaced {
+		fmt.Printf("Replaced index %s\n", name)
+	}
+	for _, mismatch := range report.Mismatches {
+		fmt.Fprintf(os.Stderr, "Mismatch: %s\n", mismatch)
+	}
+	if errors.Is(err, repository.ErrIndexMismatch) {
+		return fmt.Errorf("init-db: %w; rerun with --replace to overwrite it", err)
+	}
+	if err != nil {
+		return fmt.Errorf("init-db: %w", err)
+	}
+	if !report.CreatedDatabase && len(report.Created) == 0 && len(report.Replaced) == 0 {
+		fmt.Printf("Database %s is up to date\n", *conn.db)
+	}
+	return nil
+}
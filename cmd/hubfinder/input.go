@@ -6,8 +6,12 @@ import (
 	"math"
 	"strconv"
 	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/coord"
 )
 
+const locationPrompt = "location (e.g. 47.4925, 19.0403 or 47°29'33\"N 19°02'25\"E; UTM, MGRS, geohash and plus codes also work)"
+
 func readFloatUntilValid(scanner *bufio.Scanner, variableName string, minValue, maxValue float64) float64 {
 	for {
 		fmt.Printf("Please enter the %s: ", variableName)
@@ -25,6 +29,23 @@ func readFloatUntilValid(scanner *bufio.Scanner, variableName string, minValue,
 	return 0
 }
 
+func readLocationUntilValid(scanner *bufio.Scanner) coord.Position {
+	for {
+		fmt.Printf("Please enter the %s: ", locationPrompt)
+		if !scanner.Scan() {
+			fmt.Println("No more input available.")
+			break
+		}
+		result, err := coord.Parse(scanner.Text())
+		if err != nil {
+			fmt.Printf("%v. Please try again.\n", err)
+			continue
+		}
+		return result
+	}
+	return coord.Position{}
+}
+
 func parseAndValidateFloat(input string, minValue, maxValue float64) (float64, error) {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
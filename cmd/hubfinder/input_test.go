@@ -218,3 +218,59 @@ func TestReadFloatUntilValid(t *testing.T) {
 		})
 	}
 }
+
+func TestReadLocationUntilValid(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedLat float64
+		expectedLon float64
+	}{
+		{
+			name:        "decimal on first try",
+			input:       "47.4925, 19.0403\n",
+			expectedLat: 47.4925,
+			expectedLon: 19.0403,
+		},
+		{
+			name:        "DMS",
+			input:       "47°29'33\"N 19°02'25\"E\n",
+			expectedLat: 47.4925,
+			expectedLon: 19.040278,
+		},
+		{
+			name:        "decimal with plus signs",
+			input:       "+47.5, +19.0\n",
+			expectedLat: 47.5,
+			expectedLon: 19.0,
+		},
+		{
+			name:        "decimal with plus signs and space",
+			input:       "+47.5 +19.0\n",
+			expectedLat: 47.5,
+			expectedLon: 19.0,
+		},
+		{
+			name:        "invalid then valid",
+			input:       "abc\n47.5\n91, 19\n-33.946 151.177\n",
+			expectedLat: -33.946,
+			expectedLon: 151.177,
+		},
+		{
+			name:        "no input",
+			input:       "",
+			expectedLat: 0,
+			expectedLon: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := bufio.NewScanner(strings.NewReader(tt.input))
+			result := readLocationUntilValid(scanner)
+			if math.Abs(result.Lat-tt.expectedLat) > 1e-6 || math.Abs(result.Lon-tt.expectedLon) > 1e-6 {
+				t.Errorf("got (%f, %f), want (%f, %f)", result.Lat, result.Lon, tt.expectedLat, tt.expectedLon)
+			}
+		})
+	}
+}
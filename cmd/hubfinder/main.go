@@ -3,13 +3,19 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
-	"text/tabwriter"
+	"time"
 
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/coord"
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/units"
 )
 
 const (
@@ -17,54 +23,337 @@ const (
 	db      = "airportdb"
 	ddoc    = "view1"
 	index   = "geo"
+
+	// maxRadiusKm is the Earth's equatorial circumference.
+	maxRadiusKm = 40075.0
 )
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-func run() error {
+func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "serve":
+			return runServe(args[1:])
+		case "snapshot":
+			return runSnapshot(args[1:])
+		case "sync":
+			return runSync(args[1:])
+		case "hub":
+			return runHub(args[1:])
+		case "import":
+			return runImport(args[1:])
+		case "init-db":
+			return runInitDB(args[1:])
+		case "dedupe":
+			return runDedupe(args[1:])
+		case "audit":
+			return runAudit(args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("hubfinder", flag.ContinueOnError)
+	logLevel := fs.String("log-level", "warn", "minimum log level: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "log output format: text or json")
+	malformedRows := fs.String("malformed-rows", "warn", "handling of malformed backend rows: skip, warn or fail")
+	coerceStrings := fs.Bool("coerce-strings", false, "accept latitude and longitude values stored as strings")
+	limit := fs.Int("limit", 0, "maximum number of hubs to show, closest first (0 shows all)")
+	offset := fs.Int("offset", 0, "number of closest hubs to skip, for paging through results")
+	location := fs.String("location", "", "search location in decimal degrees, DMS, UTM, MGRS, geohash or plus code (prompted if empty)")
+	radius := fs.String("radius", "", "search radius in --unit (prompted if empty)")
+	unitName := fs.String("unit", "km", "distance unit for the radius and results: km, mi, nmi or m")
+	coordsName := fs.String("coords", "decimal", "coordinate notation in results: decimal or dms")
+	snapshotPath := fs.String("snapshot", "", "snapshot file to answer from when Cloudant is unavailable (see hubfinder snapshot)")
+	backendTimeout := fs.Duration("backend-timeout", 10*time.Second, "with --snapshot, time after which Cloudant is treated as unavailable")
+	storePath := fs.String("store", "", "bbolt database to answer from instead of Cloudant (see hubfinder sync)")
+	url := fs.String("url", baseURL, "Cloudant or CouchDB service URL")
+	dbName := fs.String("db", db, "database to query")
+	backend := fs.String("backend", "search", "Cloudant query backend: search (Lucene search index), mango (_find with a JSON index) or geo (geospatial index)")
+	var merges mergeFlag
+	fs.Var(&merges, "merge", "additional source as name=path to a bbolt database or snapshot file, merged into the results (repeatable)")
+	sourceFailure := fs.String("source-failure", "fail", "with --merge, handling of failed sources: fail or partial")
+	duplicateRadius := fs.Float64("duplicate-radius", 0, "with --merge, distance in km within which similarly named hubs from different sources are duplicates (0 matches IDs only)")
+	travelMode := fs.String("travel-mode", "", "estimate travel times by car, rail or walking (not estimated if empty)")
+	detourFactor := fs.Float64("detour-factor", 0, "with --travel-mode, ratio of route length to straight-line distance (0 uses the mode's default)")
+	osrmURL := fs.String("osrm-url", "", "with --travel-mode car or walking, OSRM-compatible routing engine to ask for travel times")
+	sortName := fs.String("sort", "distance", "result order as key[:asc|desc], where key is distance, name, bearing, type, score or travel-time (requires --travel-mode)")
+	summary := fs.Bool("summary", false, "print counts by country and type and the distance distribution instead of the hubs")
+	bins := fs.Int("bins", 10, "with --summary, number of bars in the distance histogram")
+	scoreWeights := fs.String("score-weights", "", "score hubs with weights as factor=weight pairs of distance, type, routes and passenger_class, or default (scored with the defaults for --sort score)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *limit < 0 {
+		return usage(fmt.Errorf("--limit cannot be negative"))
+	}
+	if *offset < 0 {
+		return usage(fmt.Errorf("--offset cannot be negative"))
+	}
+	if *summary && (*limit != 0 || *offset != 0) {
+		return usage(fmt.Errorf("--summary cannot be combined with --limit or --offset"))
+	}
+	if *bins < 1 || *bins > finder.MaxHistogramBins {
+		return usage(fmt.Errorf("--bins must be between 1 and %d", finder.MaxHistogramBins))
+	}
+	if *duplicateRadius < 0 {
+		return usage(fmt.Errorf("--duplicate-radius cannot be negative"))
+	}
+	sortBy, order, err := finder.ParseSort(*sortName)
+	if err != nil {
+		return usage(fmt.Errorf("--sort: %w", err))
+	}
+	if sortBy == finder.SortTravelTime && *travelMode == "" {
+		return usage(fmt.Errorf("--sort travel-time requires --travel-mode"))
+	}
+	if sortBy == finder.SortScore && *scoreWeights == "" {
+		*scoreWeights = "default"
+	}
+	scorer, err := newScorer(*scoreWeights)
+	if err != nil {
+		return err
+	}
+
+	unit, err := units.ParseUnit(*unitName)
+	if err != nil {
+		return usage(err)
+	}
+	notation, err := coord.ParseNotation(*coordsName)
+	if err != nil {
+		return usage(err)
+	}
+	maxRadius := unit.FromKm(maxRadiusKm)
+
+	var position coord.Position
+	if *location != "" {
+		parsed, err := coord.Parse(*location)
+		if err != nil {
+			return usage(fmt.Errorf("--location: %w", err))
+		}
+		position = parsed
+	}
+	var radiusKm float64
+	if *radius != "" {
+		parsed, err := parseAndValidateFloat(*radius, 0, maxRadius)
+		if err != nil {
+			return usage(fmt.Errorf("--radius: %w", err))
+		}
+		radiusKm = unit.ToKm(parsed)
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		return usage(err)
+	}
+	slog.SetDefault(logger)
+
+	rowPolicy, err := repository.ParseRowPolicy(*malformedRows)
+	if err != nil {
+		return usage(err)
+	}
+	failurePolicy, err := repository.ParseFailurePolicy(*sourceFailure)
+	if err != nil {
+		return usage(err)
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	repo, err := repository.NewCloudantRepository(repository.CloudantConfig{
-		BaseURL: baseURL,
-		DB:      db,
-		Ddoc:    ddoc,
-		Index:   index,
-	})
+	var repo repository.Repository
+	primaryName := "cloudant"
+	if *storePath != "" {
+		if *snapshotPath != "" {
+			return usage(fmt.Errorf("--store and --snapshot cannot be combined"))
+		}
+		local, err := openLocalStore(*storePath)
+		if err != nil {
+			return err
+		}
+		defer local.Close()
+		repo = local
+		primaryName = "store"
+	} else {
+		cloudant, err := newBackendRepository(*backend, repository.CloudantConfig{
+			BaseURL:       *url,
+			DB:            *dbName,
+			Logger:        logger,
+			RowPolicy:     rowPolicy,
+			CoerceStrings: *coerceStrings,
+		})
+		if err != nil {
+			return err
+		}
+		if repo, err = withSnapshotFallback(cloudant, *snapshotPath, *backendTimeout, logger); err != nil {
+			return err
+		}
+	}
+	repo, merged, err := withMergedSources(repo, primaryName, merges, repository.MultiConfig{
+		FailurePolicy:     failurePolicy,
+		DuplicateRadiusKm: *duplicateRadius,
+	}, logger)
 	if err != nil {
-		return fmt.Errorf("create repository: %w", err)
+		return err
 	}
+	defer merged.Close()
 
-	f := finder.New(repo)
+	travel, err := newTravelEstimator(*travelMode, *detourFactor, *osrmURL, logger)
+	if err != nil {
+		return err
+	}
+	opts := []finder.Option{finder.WithLogger(logger)}
+	if travel != nil {
+		opts = append(opts, finder.WithTravelEstimator(travel))
+	}
+	if scorer != nil {
+		opts = append(opts, finder.WithScorer(scorer))
+	}
+	f := finder.New(repo, opts...)
 	scanner := bufio.NewScanner(os.Stdin)
 
 	fmt.Println("This program finds transport hubs within a specified radius from a given point.")
 
-	lat := readFloatUntilValid(scanner, "latitude", -90.0, 90.0)
-	lon := readFloatUntilValid(scanner, "longitude", -180.0, 180.0)
-	radiusKm := readFloatUntilValid(scanner, "radius in kilometers", 0, 40075)
+	if *location == "" {
+		position = readLocationUntilValid(scanner)
+	}
+	if *radius == "" {
+		radiusKm = unit.ToKm(readFloatUntilValid(scanner, "radius in "+unit.Name(), 0, maxRadius))
+	}
+	lat, lon := position.Lat, position.Lon
+
+	query := finder.Query{
+		Lat:      lat,
+		Lon:      lon,
+		RadiusKm: radiusKm,
+		Limit:    *limit,
+		Offset:   *offset,
+		SortBy:   sortBy,
+		Order:    order,
+	}
+
+	if *summary {
+		result, err := f.Summarize(ctx, query, *bins)
+		if err != nil {
+			return fmt.Errorf("summarize nearby hubs: %w", err)
+		}
+		printWarnings(result.Warnings, result.SourceWarnings, result.Stale, result.SnapshotAge)
 
-	hubs, err := f.FindNearby(ctx, lat, lon, radiusKm)
+		fmt.Printf("\nSummary of %d transport hub(s):\n\n", result.Count)
+		if err := printSummary(os.Stdout, result, unit); err != nil {
+			return fmt.Errorf("print summary: %w", err)
+		}
+		return nil
+	}
+
+	result, err := f.Search(ctx, query)
 	if err != nil {
 		return fmt.Errorf("find nearby hubs: %w", err)
 	}
+	hubs := result.Hubs
+	printWarnings(result.Warnings, result.SourceWarnings, result.Stale, result.SnapshotAge)
 
 	fmt.Printf("\nFound %d transport hub(s):\n\n", len(hubs))
+	if err := printHubs(os.Stdout, hubs, unit, notation); err != nil {
+		return fmt.Errorf("print results: %w", err)
+	}
+
+	if result.HasMore {
+		fmt.Printf("\nMore hubs are available; rerun with --offset %d to see the next page.\n", result.NextOffset)
+	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Name\tDistance (km)\tLatitude\tLongitude")
-	fmt.Fprintln(w, "----\t-------------\t--------\t---------")
+	return nil
+}
 
-	for _, hub := range hubs {
-		fmt.Fprintf(w, "%s\t%.2f\t%.6f\t%.6f\n", hub.Name, hub.DistanceKm, hub.Lat, hub.Lon)
+// printWarnings reports malformed rows, failed sources and stale data on
+// stderr.
+func printWarnings(warnings []repository.RowWarning, sourceWarnings []repository.SourceWarning, stale bool, snapshotAge time.Duration) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipped malformed %s\n", warning)
+	}
+	for _, warning := range sourceWarnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s; results are incomplete\n", warning)
 	}
+	if stale {
+		fmt.Fprintln(os.Stderr, staleWarning(snapshotAge))
+	}
+}
 
-	w.Flush()
+// newBackendRepository creates the repository for the named query backend:
+// "search" for the Lucene search index, "mango" for Mango _find queries or
+// "geo" for the geospatial index.
+func newBackendRepository(backend string, cfg repository.CloudantConfig) (repository.Repository, error) {
+	switch backend {
+	case "search":
+		return newRepository(cfg)
+	case "mango":
+		cfg, err := connectionConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Ddoc, cfg.Index = "", ""
+		repo, err := repository.NewMangoRepository(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("create repository: %w", err)
+		}
+		return repo, nil
+	case "geo":
+		cfg, err := connectionConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Ddoc, cfg.Index = "", ""
+		repo, err := repository.NewGeoRepository(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("create repository: %w", err)
+		}
+		return repo, nil
+	default:
+		return nil, usage(fmt.Errorf("--backend must be search, mango or geo, got %q", backend))
+	}
+}
 
-	return nil
+// newRepository creates the Cloudant repository for the airport database,
+// filling in the connection settings cfg leaves empty.
+func newRepository(cfg repository.CloudantConfig) (*repository.CloudantRepository, error) {
+	cfg, err := connectionConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repository.NewCloudantRepository(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create repository: %w", err)
+	}
+	return repo, nil
+}
+
+// connectionConfig fills in the connection settings cfg leaves empty: the
+// public airport database, and credentials from the environment variables
+// of the Cloudant SDK if any are set.
+func connectionConfig(cfg repository.CloudantConfig) (repository.CloudantConfig, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = baseURL
+	}
+	if cfg.DB == "" {
+		cfg.DB = db
+	}
+	if cfg.Ddoc == "" {
+		cfg.Ddoc = ddoc
+	}
+	if cfg.Index == "" {
+		cfg.Index = index
+	}
+	if cfg.Authenticator == nil {
+		authenticator, err := core.GetAuthenticatorFromEnvironment("cloudant")
+		if err != nil {
+			return cfg, usage(fmt.Errorf("read Cloudant credentials: %w", err))
+		}
+		cfg.Authenticator = authenticator
+	}
+	return cfg, nil
 }
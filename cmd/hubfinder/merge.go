@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// mergeFlag collects the repeatable --merge name=path flag.
+type mergeFlag []mergeSource
+
+type mergeSource struct {
+	name, path string
+}
+
+func (f *mergeFlag) String() string {
+	parts := make([]string, len(*f))
+	for i, source := range *f {
+		parts[i] = source.name + "=" + source.path
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *mergeFlag) Set(value string) error {
+	name, path, ok := strings.Cut(value, "=")
+	if !ok || name == "" || path == "" {
+		return fmt.Errorf("want name=path, got %q", value)
+	}
+	*f = append(*f, mergeSource{name: name, path: path})
+	return nil
+}
+
+// withMergedSources combines primary, named primaryName, with the sources
+// in merges: bbolt databases for .db and .bolt files and snapshot files
+// otherwise. The primary source takes priority, followed by the others in
+// order. With no merges, primary is returned unchanged. The returned closer
+// releases the opened sources.
+func withMergedSources(primary repository.Repository, primaryName string, merges mergeFlag, cfg repository.MultiConfig, logger *slog.Logger) (repository.Repository, io.Closer, error) {
+	if len(merges) == 0 {
+		return primary, closers(nil), nil
+	}
+
+	var opened closers
+	cfg.Sources = []repository.Source{{Name: primaryName, Repository: primary}}
+	cfg.Logger = logger
+	for _, merge := range merges {
+		var repo repository.Repository
+		if isBoltPath(merge.path) {
+			store, err := repository.OpenBoltRepository(merge.path)
+			if err != nil {
+				opened.Close()
+				return nil, nil, fmt.Errorf("--merge %s: %w", merge.name, err)
+			}
+			opened = append(opened, store)
+			repo = store
+		} else {
+			snapshot, err := repository.LoadSnapshot(merge.path)
+			if err != nil {
+				opened.Close()
+				return nil, nil, usage(fmt.Errorf("--merge %s: %w", merge.name, err))
+			}
+			repo = repository.NewMemoryRepository(snapshot.Hubs)
+		}
+		cfg.Sources = append(cfg.Sources, repository.Source{Name: merge.name, Repository: repo})
+	}
+
+	multi, err := repository.NewMultiRepository(cfg)
+	if err != nil {
+		opened.Close()
+		return nil, nil, usage(fmt.Errorf("--merge: %w", err))
+	}
+	return multi, opened, nil
+}
+
+// closers closes each of its elements.
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/coord"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/units"
+)
+
+// printHubs writes hubs as a table, with distances in unit and coordinates
+// in the given notation. Type, Travel time, Score and Source columns are
+// added when any hub has a type, an estimated travel time, a score or a
+// source. Scores are followed by the value of each of their factors.
+func printHubs(out io.Writer, hubs []model.HubWithDistance, unit units.Unit, notation coord.Notation) error {
+	withType := slices.ContainsFunc(hubs, func(hub model.HubWithDistance) bool { return hub.Type != "" })
+	withScore := slices.ContainsFunc(hubs, func(hub model.HubWithDistance) bool { return len(hub.ScoreFactors) > 0 })
+	withSource := slices.ContainsFunc(hubs, func(hub model.HubWithDistance) bool { return hub.Source != "" })
+	withTravel := slices.ContainsFunc(hubs, func(hub model.HubWithDistance) bool { return hub.TravelTime > 0 })
+
+	headers := []string{"Name"}
+	if withType {
+		headers = append(headers, "Type")
+	}
+	headers = append(headers, fmt.Sprintf("Distance (%s)", unit.Symbol()))
+	if withTravel {
+		headers = append(headers, "Travel time")
+	}
+	headers = append(headers, "Latitude", "Longitude")
+	if withScore {
+		headers = append(headers, "Score", "Factors")
+	}
+	if withSource {
+		headers = append(headers, "Source")
+	}
+	rules := make([]string, len(headers))
+	for i, header := range headers {
+		rules[i] = strings.Repeat("-", utf8.RuneCountInString(header))
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	fmt.Fprintln(w, strings.Join(rules, "\t"))
+
+	for _, hub := range hubs {
+		fields := []string{hub.Name}
+		if withType {
+			fields = append(fields, hub.Type)
+		}
+		fields = append(fields, fmt.Sprintf("%.2f", unit.FromKm(hub.DistanceKm)))
+		if withTravel {
+			fields = append(fields, formatTravelTime(hub.TravelTime))
+		}
+		fields = append(fields, coord.FormatLatitude(hub.Lat, notation), coord.FormatLongitude(hub.Lon, notation))
+		if withScore {
+			fields = append(fields, fmt.Sprintf("%.2f", hub.Score), formatScoreFactors(hub.ScoreFactors))
+		}
+		if withSource {
+			fields = append(fields, hub.Source)
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// maxBarWidth is the length of the longest bar of a summary histogram.
+const maxBarWidth = 40
+
+// printSummary writes the statistics of a search: the nearest and farthest
+// hubs, the distance percentiles and histogram with distances in unit, and
+// the hub counts per country and type.
+func printSummary(out io.Writer, summary finder.Summary, unit units.Unit) error {
+	distance := func(km float64) string {
+		return fmt.Sprintf("%.2f %s", unit.FromKm(km), unit.Symbol())
+	}
+	if summary.Count == 0 {
+		_, err := fmt.Fprintln(out, "No hubs to summarize.")
+		return err
+	}
+
+	rule := func(header string) string {
+		return strings.Repeat("-", utf8.RuneCountInString(header))
+	}
+	distanceHeader := fmt.Sprintf("Distance (%s)", unit.Symbol())
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Nearest:\t%s (%s)\n", summary.Nearest.Name, distance(summary.Nearest.DistanceKm))
+	fmt.Fprintf(w, "Farthest:\t%s (%s)\n", summary.Farthest.Name, distance(summary.Farthest.DistanceKm))
+	fmt.Fprintf(w, "Mean distance:\t%s\n", distance(summary.MeanDistanceKm))
+
+	fmt.Fprintf(w, "\nPercentile\t%s\n", distanceHeader)
+	fmt.Fprintf(w, "%s\t%s\n", rule("Percentile"), rule(distanceHeader))
+	for _, p := range summary.Percentiles {
+		fmt.Fprintf(w, "p%g\t%.2f\n", p.Percent, unit.FromKm(p.DistanceKm))
+	}
+
+	// Bars are scaled to the fullest bin, rounding up so that no hub goes
+	// unseen.
+	largest := 0
+	for _, bin := range summary.Histogram {
+		largest = max(largest, bin.Count)
+	}
+	countWidth := len(strconv.Itoa(largest))
+	fmt.Fprintf(w, "\n%s\tHubs\n", distanceHeader)
+	fmt.Fprintf(w, "%s\t----\n", rule(distanceHeader))
+	for _, bin := range summary.Histogram {
+		bar := strings.Repeat("#", (bin.Count*maxBarWidth+largest-1)/largest)
+		fmt.Fprintf(w, "%.2f-%.2f\t%s\n", unit.FromKm(bin.MinKm), unit.FromKm(bin.MaxKm),
+			strings.TrimRight(fmt.Sprintf("%-*d %s", countWidth, bin.Count, bar), " "))
+	}
+
+	for _, group := range []struct {
+		header string
+		counts []finder.GroupCount
+	}{
+		{"Country", summary.ByCountry},
+		{"Type", summary.ByType},
+	} {
+		fmt.Fprintf(w, "\n%s\tHubs\n", group.header)
+		fmt.Fprintf(w, "%s\t----\n", rule(group.header))
+		for _, count := range group.counts {
+			fmt.Fprintf(w, "%s\t%d\n", cmp.Or(count.Key, "(unknown)"), count.Count)
+		}
+	}
+
+	return w.Flush()
+}
+
+// formatScoreFactors lists the value of every factor of a score.
+func formatScoreFactors(factors []model.ScoreFactor) string {
+	parts := make([]string, len(factors))
+	for i, factor := range factors {
+		parts[i] = fmt.Sprintf("%s %.2f", factor.Name, factor.Value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTravelTime writes a travel time in hours and minutes, rounded to
+// the nearest minute.
+func formatTravelTime(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case d < 30*time.Second:
+		return "<1m"
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+	}
+}
+
+// formatAge describes a snapshot age in the largest whole unit that fits.
+func formatAge(age time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case age < time.Minute:
+		return "less than a minute"
+	case age < time.Hour:
+		return plural(int(age/time.Minute), "minute")
+	case age < 48*time.Hour:
+		return plural(int(age/time.Hour), "hour")
+	default:
+		return plural(int(age/(24*time.Hour)), "day")
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/coord"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/units"
+)
+
+func TestPrintHubs(t *testing.T) {
+	hubs := []model.HubWithDistance{
+		{Hub: model.Hub{ID: "bud", Name: "Budapest", Lat: 47.4925, Lon: 19.040278}, DistanceKm: 18.52},
+		{Hub: model.Hub{ID: "syd", Name: "Sydney", Lat: -33.945833, Lon: 151.176944}, DistanceKm: 1.852},
+	}
+
+	tests := []struct {
+		name     string
+		unit     units.Unit
+		notation coord.Notation
+		expected string
+	}{
+		{
+			name:     "kilometers and decimal",
+			unit:     units.Kilometers,
+			notation: coord.NotationDecimal,
+			expected: "" +
+				"Name      Distance (km)  Latitude    Longitude\n" +
+				"----      -------------  --------    ---------\n" +
+				"Budapest  18.52          47.492500   19.040278\n" +
+				"Sydney    1.85           -33.945833  151.176944\n",
+		},
+		{
+			name:     "nautical miles and DMS",
+			unit:     units.NauticalMiles,
+			notation: coord.NotationDMS,
+			expected: "" +
+				"Name      Distance (nmi)  Latitude      Longitude\n" +
+				"----      --------------  --------      ---------\n" +
+				"Budapest  10.00           47°29'33.0\"N  19°02'25.0\"E\n" +
+				"Sydney    1.00            33°56'45.0\"S  151°10'37.0\"E\n",
+		},
+		{
+			name:     "meters",
+			unit:     units.Meters,
+			notation: coord.NotationDecimal,
+			expected: "" +
+				"Name      Distance (m)  Latitude    Longitude\n" +
+				"----      ------------  --------    ---------\n" +
+				"Budapest  18520.00      47.492500   19.040278\n" +
+				"Sydney    1852.00       -33.945833  151.176944\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printHubs(&buf, hubs, tt.unit, tt.notation); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("got:\n%s\nwant:\n%s", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintHubsWithSource(t *testing.T) {
+	hubs := []model.HubWithDistance{
+		{Hub: model.Hub{ID: "bud", Name: "Budapest", Lat: 47.4925, Lon: 19.040278, Source: "cloudant"}, DistanceKm: 18.52},
+		{Hub: model.Hub{ID: "syd", Name: "Sydney", Lat: -33.945833, Lon: 151.176944, Source: "extra"}, DistanceKm: 1.852},
+	}
+	expected := "" +
+		"Name      Distance (km)  Latitude    Longitude   Source\n" +
+		"----      -------------  --------    ---------   ------\n" +
+		"Budapest  18.52          47.492500   19.040278   cloudant\n" +
+		"Sydney    1.85           -33.945833  151.176944  extra\n"
+
+	var buf bytes.Buffer
+	if err := printHubs(&buf, hubs, units.Kilometers, coord.NotationDecimal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+func TestPrintHubsWithType(t *testing.T) {
+	hubs := []model.HubWithDistance{
+		{Hub: model.Hub{ID: "bud", Name: "Budapest", Lat: 47.4925, Lon: 19.040278, Type: "large_airport"}, DistanceKm: 18.52},
+		{Hub: model.Hub{ID: "syd", Name: "Sydney", Lat: -33.945833, Lon: 151.176944}, DistanceKm: 1.852},
+	}
+	expected := "" +
+		"Name      Type           Distance (km)  Latitude    Longitude\n" +
+		"----      ----           -------------  --------    ---------\n" +
+		"Budapest  large_airport  18.52          47.492500   19.040278\n" +
+		"Sydney                   1.85           -33.945833  151.176944\n"
+
+	var buf bytes.Buffer
+	if err := printHubs(&buf, hubs, units.Kilometers, coord.NotationDecimal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+func TestPrintHubsWithScore(t *testing.T) {
+	hubs := []model.HubWithDistance{
+		{
+			Hub:        model.Hub{ID: "bud", Name: "Budapest", Lat: 47.4925, Lon: 19.040278},
+			DistanceKm: 18.52,
+			Score:      0.615,
+			ScoreFactors: []model.ScoreFactor{
+				{Name: "distance", Value: 0.73, Weight: 0.5},
+				{Name: "routes", Value: 0.5, Weight: 0.5},
+			},
+		},
+	}
+	expected := "" +
+		"Name      Distance (km)  Latitude   Longitude  Score  Factors\n" +
+		"----      -------------  --------   ---------  -----  -------\n" +
+		"Budapest  18.52          47.492500  19.040278  0.61   distance 0.73, routes 0.50\n"
+
+	var buf bytes.Buffer
+	if err := printHubs(&buf, hubs, units.Kilometers, coord.NotationDecimal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+func TestPrintHubsWithTravelTime(t *testing.T) {
+	hubs := []model.HubWithDistance{
+		{Hub: model.Hub{ID: "bud", Name: "Budapest", Lat: 47.4925, Lon: 19.040278}, DistanceKm: 18.52, TravelTime: 22 * time.Minute},
+		{Hub: model.Hub{ID: "syd", Name: "Sydney", Lat: -33.945833, Lon: 151.176944}, DistanceKm: 1.852, TravelTime: 95 * time.Minute},
+	}
+	expected := "" +
+		"Name      Distance (km)  Travel time  Latitude    Longitude\n" +
+		"----      -------------  -----------  --------    ---------\n" +
+		"Budapest  18.52          22m          47.492500   19.040278\n" +
+		"Sydney    1.85           1h 35m       -33.945833  151.176944\n"
+
+	var buf bytes.Buffer
+	if err := printHubs(&buf, hubs, units.Kilometers, coord.NotationDecimal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+func TestFormatTravelTime(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "<1m"},
+		{29 * time.Second, "<1m"},
+		{30 * time.Second, "1m"},
+		{59*time.Minute + 29*time.Second, "59m"},
+		{59*time.Minute + 30*time.Second, "1h 00m"},
+		{26*time.Hour + 5*time.Minute, "26h 05m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTravelTime(tt.d); got != tt.expected {
+			t.Errorf("formatTravelTime(%v) = %q, want %q", tt.d, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{0, "less than a minute"},
+		{59 * time.Second, "less than a minute"},
+		{time.Minute, "1 minute"},
+		{45 * time.Minute, "45 minutes"},
+		{90 * time.Minute, "1 hour"},
+		{47 * time.Hour, "47 hours"},
+		{72 * time.Hour, "3 days"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.expected {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.age, got, tt.expected)
+		}
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	nearest := model.HubWithDistance{Hub: model.Hub{ID: "bud", Name: "Budapest"}, DistanceKm: 16}
+	farthest := model.HubWithDistance{Hub: model.Hub{ID: "vie", Name: "Vienna"}, DistanceKm: 216}
+	summary := finder.Summary{
+		Count:          3,
+		ByCountry:      []finder.GroupCount{{Key: "HU", Count: 2}, {Key: "", Count: 1}},
+		ByType:         []finder.GroupCount{{Key: "large_airport", Count: 3}},
+		Nearest:        &nearest,
+		Farthest:       &farthest,
+		MeanDistanceKm: 100,
+		Percentiles:    []finder.Percentile{{Percent: 50, DistanceKm: 68}, {Percent: 90, DistanceKm: 186.4}},
+		Histogram: []finder.HistogramBin{
+			{MinKm: 0, MaxKm: 125, Count: 2},
+			{MinKm: 125, MaxKm: 250, Count: 1},
+		},
+	}
+
+	expected := "" +
+		"Nearest:        Budapest (16.00 km)\n" +
+		"Farthest:       Vienna (216.00 km)\n" +
+		"Mean distance:  100.00 km\n" +
+		"\n" +
+		"Percentile  Distance (km)\n" +
+		"----------  -------------\n" +
+		"p50         68.00\n" +
+		"p90         186.40\n" +
+		"\n" +
+		"Distance (km)  Hubs\n" +
+		"-------------  ----\n" +
+		"0.00-125.00    2 ########################################\n" +
+		"125.00-250.00  1 ####################\n" +
+		"\n" +
+		"Country    Hubs\n" +
+		"-------    ----\n" +
+		"HU         2\n" +
+		"(unknown)  1\n" +
+		"\n" +
+		"Type           Hubs\n" +
+		"----           ----\n" +
+		"large_airport  3\n"
+
+	var buf bytes.Buffer
+	if err := printSummary(&buf, summary, units.Kilometers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf.String(); got != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", got, expected)
+	}
+}
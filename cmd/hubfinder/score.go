@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+)
+
+// newScorer returns the weighted scorer for --score-weights, which is either
+// "default" or a list of factor=weight pairs. It returns nil if weights is
+// empty.
+func newScorer(weights string) (finder.Scorer, error) {
+	if weights == "" {
+		return nil, nil
+	}
+	var cfg finder.WeightedConfig
+	if weights != "default" {
+		parsed, err := finder.ParseWeights(weights)
+		if err != nil {
+			return nil, usage(fmt.Errorf("--score-weights: %w", err))
+		}
+		if parsed == (finder.Weights{}) {
+			return nil, usage(fmt.Errorf("--score-weights: at least one weight must be positive"))
+		}
+		cfg.Weights = parsed
+	}
+	scorer, err := finder.NewWeightedScorer(cfg)
+	if err != nil {
+		return nil, usage(fmt.Errorf("--score-weights: %w", err))
+	}
+	return scorer, nil
+}
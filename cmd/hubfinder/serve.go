@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/grpcserver"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// runServe implements "hubfinder serve", which answers hub lookups over gRPC
+// until interrupted.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("hubfinder serve", flag.ContinueOnError)
+	grpcAddr := fs.String("grpc-addr", ":50051", "address the gRPC API listens on")
+	metricsAddr := fs.String("metrics-addr", "", "address serving Prometheus metrics on /metrics (disabled if empty)")
+	traceExporter := fs.String("trace-exporter", telemetry.ExporterNone, "OpenTelemetry span exporter: none, stdout (JSON on stderr) or otlp (OTLP/HTTP, configured by the OTEL_EXPORTER_OTLP_* environment variables)")
+	timeout := fs.Duration("timeout", 30*time.Second, "deadline for calls whose client did not set one (0 disables)")
+	logLevel := fs.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "log output format: text or json")
+	malformedRows := fs.String("malformed-rows", "warn", "handling of malformed backend rows: skip, warn or fail")
+	coerceStrings := fs.Bool("coerce-strings", false, "accept latitude and longitude values stored as strings")
+	snapshotPath := fs.String("snapshot", "", "snapshot file to answer from when Cloudant is unavailable (see hubfinder snapshot)")
+	backendTimeout := fs.Duration("backend-timeout", 10*time.Second, "with --snapshot, time after which Cloudant is treated as unavailable")
+	storePath := fs.String("store", "", "bbolt database to answer from instead of Cloudant (see hubfinder sync)")
+	url := fs.String("url", baseURL, "Cloudant or CouchDB service URL")
+	dbName := fs.String("db", db, "database to query")
+	backend := fs.String("backend", "search", "Cloudant query backend: search (Lucene search index), mango (_find with a JSON index) or geo (geospatial index)")
+	cacheTTL := fs.Duration("cache-ttl", 0, "time for which bounds query results are reused (caching disabled if 0)")
+	cacheSize := fs.Int("cache-size", 1000, "with --cache-ttl, maximum number of cached bounds query results")
+	scoreWeights := fs.String("score-weights", "", "score hubs with weights as factor=weight pairs of distance, type, routes and passenger_class, or default (not scored if empty)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *timeout < 0 {
+		return usage(fmt.Errorf("--timeout cannot be negative"))
+	}
+	if *cacheTTL < 0 {
+		return usage(fmt.Errorf("--cache-ttl cannot be negative"))
+	}
+	if *cacheSize <= 0 {
+		return usage(fmt.Errorf("--cache-size must be positive"))
+	}
+	switch *traceExporter {
+	case telemetry.ExporterNone, telemetry.ExporterStdout, telemetry.ExporterOTLP:
+	default:
+		return usage(fmt.Errorf("--trace-exporter must be none, stdout or otlp, got %q", *traceExporter))
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		return usage(err)
+	}
+	slog.SetDefault(logger)
+
+	rowPolicy, err := repository.ParseRowPolicy(*malformedRows)
+	if err != nil {
+		return usage(err)
+	}
+	scorer, err := newScorer(*scoreWeights)
+	if err != nil {
+		return err
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	metrics, err := telemetry.NewMetrics(reg)
+	if err != nil {
+		return err
+	}
+
+	// The tracer provider stays nil without an exporter, so spans go to
+	// the global provider.
+	var tracerProvider trace.TracerProvider
+	tp, err := telemetry.NewTracerProvider(context.Background(), *traceExporter, "hubfinder", os.Stderr)
+	if err != nil {
+		return err
+	}
+	if tp != nil {
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tp.Shutdown(shutdownCtx); err != nil {
+				logger.Warn("flushing spans failed", "error", err)
+			}
+		}()
+		tracerProvider = tp
+	}
+
+	var repo repository.Repository
+	if *storePath != "" {
+		if *snapshotPath != "" {
+			return usage(fmt.Errorf("--store and --snapshot cannot be combined"))
+		}
+		local, err := openLocalStore(*storePath)
+		if err != nil {
+			return err
+		}
+		defer local.Close()
+		repo = local
+	} else {
+		cloudant, err := newBackendRepository(*backend, repository.CloudantConfig{
+			BaseURL:        *url,
+			DB:             *dbName,
+			Logger:         logger,
+			RowPolicy:      rowPolicy,
+			CoerceStrings:  *coerceStrings,
+			Metrics:        metrics,
+			TracerProvider: tracerProvider,
+		})
+		if err != nil {
+			return err
+		}
+		if repo, err = withSnapshotFallback(cloudant, *snapshotPath, *backendTimeout, logger); err != nil {
+			return err
+		}
+	}
+	if *cacheTTL > 0 {
+		if repo, err = repository.NewCachedRepository(repository.CacheConfig{
+			Repository: repo,
+			TTL:        *cacheTTL,
+			MaxEntries: *cacheSize,
+			Metrics:    metrics,
+		}); err != nil {
+			return err
+		}
+	}
+	opts := []finder.Option{finder.WithLogger(logger), finder.WithMetrics(metrics), finder.WithTracerProvider(tracerProvider)}
+	if scorer != nil {
+		opts = append(opts, finder.WithScorer(scorer))
+	}
+	f := finder.New(repo, opts...)
+
+	listener, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", *grpcAddr, err)
+	}
+	gs := grpc.NewServer()
+	grpcserver.New(f, grpcserver.WithLogger(logger), grpcserver.WithDefaultTimeout(*timeout)).Register(gs)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 2)
+	go func() {
+		logger.Info("gRPC API listening", "addr", listener.Addr().String())
+		errs <- gs.Serve(listener)
+	}()
+
+	var metricsServer *http.Server
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", telemetry.Handler(reg))
+		metricsServer = &http.Server{Addr: *metricsAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			logger.Info("metrics listening", "addr", *metricsAddr)
+			if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("serve metrics: %w", err)
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	case err = <-errs:
+	}
+
+	gs.GracefulStop()
+	if metricsServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = metricsServer.Shutdown(shutdownCtx)
+	}
+	if err != nil {
+		return fmt.Errorf("serve gRPC: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// runSnapshot implements "hubfinder snapshot", which saves every hub in the
+// database to a local file for use with --snapshot.
+func runSnapshot(args []string) error {
+	fs := flag.NewFlagSet("hubfinder snapshot", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write the snapshot to (required)")
+	logLevel := fs.String("log-level", "warn", "minimum log level: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "log output format: text or json")
+	malformedRows := fs.String("malformed-rows", "warn", "handling of malformed backend rows: skip, warn or fail")
+	coerceStrings := fs.Bool("coerce-strings", false, "accept latitude and longitude values stored as strings")
+	url := fs.String("url", baseURL, "Cloudant or CouchDB service URL")
+	dbName := fs.String("db", db, "database to save")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *out == "" {
+		return usage(fmt.Errorf("--out is required"))
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		return usage(err)
+	}
+	slog.SetDefault(logger)
+
+	rowPolicy, err := repository.ParseRowPolicy(*malformedRows)
+	if err != nil {
+		return usage(err)
+	}
+
+	repo, err := newRepository(repository.CloudantConfig{
+		BaseURL:       *url,
+		DB:            *dbName,
+		Logger:        logger,
+		RowPolicy:     rowPolicy,
+		CoerceStrings: *coerceStrings,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	createdAt := time.Now().UTC()
+	result, err := repo.GetByBoundsDetailed(ctx, -90, 90, -180, 180)
+	if err != nil {
+		return fmt.Errorf("fetch hubs: %w", err)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipped malformed %s\n", warning)
+	}
+
+	if err := repository.WriteSnapshot(*out, &repository.Snapshot{CreatedAt: createdAt, Hubs: result.Hubs}); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d hubs to %s\n", len(result.Hubs), *out)
+	return nil
+}
+
+// withSnapshotFallback wraps repo so that it answers from the snapshot at
+// path when Cloudant is unavailable or slower than timeout. An empty path
+// returns repo unchanged.
+func withSnapshotFallback(repo repository.Repository, path string, timeout time.Duration, logger *slog.Logger) (repository.Repository, error) {
+	if path == "" {
+		return repo, nil
+	}
+	snapshot, err := repository.LoadSnapshot(path)
+	if err != nil {
+		return nil, usage(fmt.Errorf("--snapshot: %w", err))
+	}
+	return repository.NewFallbackRepository(repository.FallbackConfig{
+		Primary:        repo,
+		Snapshot:       snapshot,
+		PrimaryTimeout: timeout,
+		Logger:         logger,
+	})
+}
+
+// staleWarning describes results served from a snapshot.
+func staleWarning(age time.Duration) string {
+	return fmt.Sprintf("Warning: Cloudant is unavailable; showing stale data from a snapshot taken %s ago.", formatAge(age))
+}
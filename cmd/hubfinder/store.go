@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/hubsync"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// syncStore is a hubsync.Store that can report its size and be closed.
+type syncStore interface {
+	hubsync.Store
+	Len() int
+	Close() error
+}
+
+// fileSyncStore adapts hubsync.FileStore, which holds no open resources.
+type fileSyncStore struct {
+	*hubsync.FileStore
+}
+
+func (fileSyncStore) Close() error { return nil }
+
+// isBoltPath reports whether path names a bbolt database rather than a
+// snapshot file, judging by its extension.
+func isBoltPath(path string) bool {
+	switch filepath.Ext(path) {
+	case ".db", ".bolt":
+		return true
+	}
+	return false
+}
+
+// openSyncStore opens the sync target at path: a bbolt database for .db and
+// .bolt files and a snapshot file otherwise.
+func openSyncStore(path string) (syncStore, error) {
+	if isBoltPath(path) {
+		store, err := repository.OpenBoltRepository(path)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	store, err := hubsync.OpenFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return fileSyncStore{store}, nil
+}
+
+// openLocalStore opens the bbolt database at path for use with --store.
+func openLocalStore(path string) (*repository.BoltRepository, error) {
+	if !isBoltPath(path) {
+		return nil, usage(fmt.Errorf("--store: %s is not a .db or .bolt file", path))
+	}
+	return repository.OpenBoltRepository(path)
+}
+
+// readBatchSize is the number of changes readHubs asks Cloudant for at a
+// time.
+const readBatchSize = 1000
+
+// inputsFlag collects the repeatable --in flag.
+type inputsFlag []string
+
+func (f *inputsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *inputsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// readHubs reads every hub of the snapshot files and bbolt databases in
+// inputs, in order, or of the Cloudant database in cfg if there are none.
+// Cloudant is read through its changes feed, so that documents outside the
+// search index, such as those with out-of-range coordinates, are seen too.
+// Those are returned as hubs if keepOutOfRange is set and as malformed rows
+// otherwise, together with the other rows Cloudant could not decode.
+func readHubs(ctx context.Context, inputs []string, cfg repository.CloudantConfig, keepOutOfRange bool) ([]model.Hub, []repository.RowWarning, error) {
+	if len(inputs) == 0 {
+		repo, err := newRepository(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		hubs, skipped, err := repository.ReadAllChanges(ctx, repo, repository.ChangesOptions{
+			Limit:          readBatchSize,
+			KeepOutOfRange: keepOutOfRange,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("fetch hubs: %w", err)
+		}
+		return hubs, skipped, nil
+	}
+
+	var hubs []model.Hub
+	for _, path := range inputs {
+		if !isBoltPath(path) {
+			snapshot, err := repository.LoadSnapshot(path)
+			if err != nil {
+				return nil, nil, usage(fmt.Errorf("--in: %w", err))
+			}
+			hubs = append(hubs, snapshot.Hubs...)
+			continue
+		}
+		store, err := repository.OpenBoltRepository(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--in: %w", err)
+		}
+		stored, err := store.GetByBounds(ctx, -90, 90, -180, 180)
+		store.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		hubs = append(hubs, stored...)
+	}
+	return hubs, nil, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/hubsync"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// runSync implements "hubfinder sync", which applies the database changes
+// feed to a local snapshot file or bbolt database, either once or
+// continuously.
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("hubfinder sync", flag.ContinueOnError)
+	storePath := fs.String("store", "", "snapshot file, or bbolt database if it ends in .db or .bolt, to keep up to date; created if missing (required)")
+	continuous := fs.Bool("continuous", false, "keep following the changes feed until interrupted")
+	batchSize := fs.Int("batch-size", 500, "maximum number of changes read per request")
+	pollTimeout := fs.Duration("poll-timeout", 25*time.Second, "with --continuous, how long each request waits for new changes")
+	logLevel := fs.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logFormat := fs.String("log-format", "text", "log output format: text or json")
+	coerceStrings := fs.Bool("coerce-strings", false, "accept latitude and longitude values stored as strings")
+	url := fs.String("url", baseURL, "Cloudant or CouchDB service URL")
+	dbName := fs.String("db", db, "database to follow")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usage(err)
+	}
+	if *storePath == "" {
+		return usage(fmt.Errorf("--store is required"))
+	}
+	if *batchSize <= 0 {
+		return usage(fmt.Errorf("--batch-size must be positive"))
+	}
+	if *pollTimeout <= 0 {
+		return usage(fmt.Errorf("--poll-timeout must be positive"))
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		return usage(err)
+	}
+	slog.SetDefault(logger)
+
+	source, err := newRepository(repository.CloudantConfig{
+		BaseURL:       *url,
+		DB:            *dbName,
+		Logger:        logger,
+		CoerceStrings: *coerceStrings,
+	})
+	if err != nil {
+		return err
+	}
+	store, err := openSyncStore(*storePath)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer store.Close()
+	syncer, err := hubsync.New(hubsync.Config{
+		Source:      source,
+		Store:       store,
+		BatchSize:   *batchSize,
+		PollTimeout: *pollTimeout,
+		Logger:      logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var stats hubsync.Stats
+	if *continuous {
+		stats, err = syncer.Run(ctx)
+	} else {
+		stats, err = syncer.SyncOnce(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	fmt.Printf("Synced %d change(s): %d upserted, %d deleted, %d skipped. %s now holds %d hubs at sequence %s.\n",
+		stats.Upserted+stats.Deleted+stats.Skipped, stats.Upserted, stats.Deleted, stats.Skipped,
+		*storePath, store.Len(), stats.Seq)
+	return nil
+}
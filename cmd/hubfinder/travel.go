@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/osrm"
+)
+
+// osrmProfiles maps travel modes to the routing profiles of an OSRM
+// server. Rail has no OSRM profile.
+var osrmProfiles = map[string]string{
+	"car":     "driving",
+	"walking": "foot",
+}
+
+// newTravelEstimator returns the estimator for the named travel mode, or
+// nil if mode is empty. detourFactor overrides the mode's default when
+// positive. With osrmURL set, travel times come from that routing engine,
+// and the mode's profile is only used for hubs it cannot route to.
+func newTravelEstimator(mode string, detourFactor float64, osrmURL string, logger *slog.Logger) (finder.TravelEstimator, error) {
+	if mode == "" {
+		if osrmURL != "" || detourFactor != 0 {
+			return nil, usage(fmt.Errorf("--osrm-url and --detour-factor require --travel-mode"))
+		}
+		return nil, nil
+	}
+	profile, err := finder.ParseProfile(mode)
+	if err != nil {
+		return nil, usage(fmt.Errorf("--travel-mode: %w", err))
+	}
+	if detourFactor != 0 {
+		profile.DetourFactor = detourFactor
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, usage(err)
+	}
+	if osrmURL == "" {
+		return profile, nil
+	}
+
+	osrmProfile, ok := osrmProfiles[profile.Name]
+	if !ok {
+		return nil, usage(fmt.Errorf("--osrm-url cannot be used with --travel-mode %s", profile.Name))
+	}
+	estimator, err := osrm.New(osrm.Config{
+		BaseURL:  osrmURL,
+		Profile:  osrmProfile,
+		Fallback: profile,
+		Logger:   logger,
+	})
+	if err != nil {
+		return nil, usage(fmt.Errorf("--osrm-url: %w", err))
+	}
+	return estimator, nil
+}
@@ -0,0 +1,1368 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: hubfinder/v1/hubfinder.proto
+
+package hubfinderv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Point is a WGS84 position in decimal degrees.
+type Point struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon           float64                `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Point) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+type Hub struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Location *Point                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	// type is the kind of hub, such as large_airport, if the backend records
+	// it.
+	Type string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	// routes is the number of scheduled routes served, if known.
+	Routes int32 `protobuf:"varint,5,opt,name=routes,proto3" json:"routes,omitempty"`
+	// passenger_class is large, medium, small or nonhub, if known.
+	PassengerClass string `protobuf:"bytes,6,opt,name=passenger_class,json=passengerClass,proto3" json:"passenger_class,omitempty"`
+	// country is the country code of the hub, such as HU, if the backend
+	// records it.
+	Country string `protobuf:"bytes,7,opt,name=country,proto3" json:"country,omitempty"`
+	// source names the backend the hub came from when the server combines
+	// several.
+	Source        string `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hub) Reset() {
+	*x = Hub{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hub) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hub) ProtoMessage() {}
+
+func (x *Hub) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hub.ProtoReflect.Descriptor instead.
+func (*Hub) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Hub) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Hub) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Hub) GetLocation() *Point {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Hub) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Hub) GetRoutes() int32 {
+	if x != nil {
+		return x.Routes
+	}
+	return 0
+}
+
+func (x *Hub) GetPassengerClass() string {
+	if x != nil {
+		return x.PassengerClass
+	}
+	return ""
+}
+
+func (x *Hub) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Hub) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type NearbyHub struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Hub        *Hub                   `protobuf:"bytes,1,opt,name=hub,proto3" json:"hub,omitempty"`
+	DistanceKm float64                `protobuf:"fixed64,2,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	// score and score_factors are set when the server scores hubs.
+	Score         float64        `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	ScoreFactors  []*ScoreFactor `protobuf:"bytes,4,rep,name=score_factors,json=scoreFactors,proto3" json:"score_factors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearbyHub) Reset() {
+	*x = NearbyHub{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearbyHub) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearbyHub) ProtoMessage() {}
+
+func (x *NearbyHub) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearbyHub.ProtoReflect.Descriptor instead.
+func (*NearbyHub) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NearbyHub) GetHub() *Hub {
+	if x != nil {
+		return x.Hub
+	}
+	return nil
+}
+
+func (x *NearbyHub) GetDistanceKm() float64 {
+	if x != nil {
+		return x.DistanceKm
+	}
+	return 0
+}
+
+func (x *NearbyHub) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *NearbyHub) GetScoreFactors() []*ScoreFactor {
+	if x != nil {
+		return x.ScoreFactors
+	}
+	return nil
+}
+
+// ScoreFactor is one term of a hub's score: the score is the sum of value
+// times weight over all factors.
+type ScoreFactor struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// value rates the hub on this factor alone, from 0 to 1.
+	Value         float64 `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Weight        float64 `protobuf:"fixed64,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreFactor) Reset() {
+	*x = ScoreFactor{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreFactor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreFactor) ProtoMessage() {}
+
+func (x *ScoreFactor) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreFactor.ProtoReflect.Descriptor instead.
+func (*ScoreFactor) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScoreFactor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScoreFactor) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *ScoreFactor) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// RowWarning describes a backend row that was left out of the results.
+type RowWarning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RowWarning) Reset() {
+	*x = RowWarning{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RowWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RowWarning) ProtoMessage() {}
+
+func (x *RowWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RowWarning.ProtoReflect.Descriptor instead.
+func (*RowWarning) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RowWarning) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RowWarning) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// SourceWarning describes a backend that failed and was left out of the
+// results, which only happens when the server returns partial results.
+type SourceWarning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceWarning) Reset() {
+	*x = SourceWarning{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceWarning) ProtoMessage() {}
+
+func (x *SourceWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceWarning.ProtoReflect.Descriptor instead.
+func (*SourceWarning) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SourceWarning) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SourceWarning) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type FindNearbyRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Center   *Point                 `protobuf:"bytes,1,opt,name=center,proto3" json:"center,omitempty"`
+	RadiusKm float64                `protobuf:"fixed64,2,opt,name=radius_km,json=radiusKm,proto3" json:"radius_km,omitempty"`
+	// limit caps the number of hubs returned. Zero means no limit.
+	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// offset skips that many of the closest hubs, for paging through results.
+	Offset int32 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	// sort orders the hubs as key[:asc|desc], where key is distance, name,
+	// bearing, type or, if the server scores hubs, score. Defaults to
+	// distance, closest first. travel-time, which the CLI accepts, is
+	// rejected with INVALID_ARGUMENT.
+	Sort          string `protobuf:"bytes,5,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindNearbyRequest) Reset() {
+	*x = FindNearbyRequest{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindNearbyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindNearbyRequest) ProtoMessage() {}
+
+func (x *FindNearbyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindNearbyRequest.ProtoReflect.Descriptor instead.
+func (*FindNearbyRequest) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FindNearbyRequest) GetCenter() *Point {
+	if x != nil {
+		return x.Center
+	}
+	return nil
+}
+
+func (x *FindNearbyRequest) GetRadiusKm() float64 {
+	if x != nil {
+		return x.RadiusKm
+	}
+	return 0
+}
+
+func (x *FindNearbyRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FindNearbyRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *FindNearbyRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type FindNearbyResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Hubs       []*NearbyHub           `protobuf:"bytes,1,rep,name=hubs,proto3" json:"hubs,omitempty"`
+	Warnings   []*RowWarning          `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	HasMore    bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	NextOffset int32                  `protobuf:"varint,4,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
+	// stale is set when the hubs come from a local snapshot because the
+	// backend was unavailable; snapshot_age then tells how old it is.
+	Stale       bool                 `protobuf:"varint,5,opt,name=stale,proto3" json:"stale,omitempty"`
+	SnapshotAge *durationpb.Duration `protobuf:"bytes,6,opt,name=snapshot_age,json=snapshotAge,proto3" json:"snapshot_age,omitempty"`
+	// source_warnings lists the backends left out of a partial result.
+	SourceWarnings []*SourceWarning `protobuf:"bytes,7,rep,name=source_warnings,json=sourceWarnings,proto3" json:"source_warnings,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FindNearbyResponse) Reset() {
+	*x = FindNearbyResponse{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindNearbyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindNearbyResponse) ProtoMessage() {}
+
+func (x *FindNearbyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindNearbyResponse.ProtoReflect.Descriptor instead.
+func (*FindNearbyResponse) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FindNearbyResponse) GetHubs() []*NearbyHub {
+	if x != nil {
+		return x.Hubs
+	}
+	return nil
+}
+
+func (x *FindNearbyResponse) GetWarnings() []*RowWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *FindNearbyResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *FindNearbyResponse) GetNextOffset() int32 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
+func (x *FindNearbyResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *FindNearbyResponse) GetSnapshotAge() *durationpb.Duration {
+	if x != nil {
+		return x.SnapshotAge
+	}
+	return nil
+}
+
+func (x *FindNearbyResponse) GetSourceWarnings() []*SourceWarning {
+	if x != nil {
+		return x.SourceWarnings
+	}
+	return nil
+}
+
+type StreamNearbyRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Center   *Point                 `protobuf:"bytes,1,opt,name=center,proto3" json:"center,omitempty"`
+	RadiusKm float64                `protobuf:"fixed64,2,opt,name=radius_km,json=radiusKm,proto3" json:"radius_km,omitempty"`
+	// sort orders the hubs as in FindNearbyRequest.
+	Sort          string `protobuf:"bytes,3,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamNearbyRequest) Reset() {
+	*x = StreamNearbyRequest{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamNearbyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamNearbyRequest) ProtoMessage() {}
+
+func (x *StreamNearbyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamNearbyRequest.ProtoReflect.Descriptor instead.
+func (*StreamNearbyRequest) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamNearbyRequest) GetCenter() *Point {
+	if x != nil {
+		return x.Center
+	}
+	return nil
+}
+
+func (x *StreamNearbyRequest) GetRadiusKm() float64 {
+	if x != nil {
+		return x.RadiusKm
+	}
+	return 0
+}
+
+func (x *StreamNearbyRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type StreamNearbyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Item:
+	//
+	//	*StreamNearbyResponse_Hub
+	//	*StreamNearbyResponse_Warning
+	//	*StreamNearbyResponse_Stale
+	//	*StreamNearbyResponse_SourceWarning
+	Item          isStreamNearbyResponse_Item `protobuf_oneof:"item"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamNearbyResponse) Reset() {
+	*x = StreamNearbyResponse{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamNearbyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamNearbyResponse) ProtoMessage() {}
+
+func (x *StreamNearbyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamNearbyResponse.ProtoReflect.Descriptor instead.
+func (*StreamNearbyResponse) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamNearbyResponse) GetItem() isStreamNearbyResponse_Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *StreamNearbyResponse) GetHub() *NearbyHub {
+	if x != nil {
+		if x, ok := x.Item.(*StreamNearbyResponse_Hub); ok {
+			return x.Hub
+		}
+	}
+	return nil
+}
+
+func (x *StreamNearbyResponse) GetWarning() *RowWarning {
+	if x != nil {
+		if x, ok := x.Item.(*StreamNearbyResponse_Warning); ok {
+			return x.Warning
+		}
+	}
+	return nil
+}
+
+func (x *StreamNearbyResponse) GetStale() *Staleness {
+	if x != nil {
+		if x, ok := x.Item.(*StreamNearbyResponse_Stale); ok {
+			return x.Stale
+		}
+	}
+	return nil
+}
+
+func (x *StreamNearbyResponse) GetSourceWarning() *SourceWarning {
+	if x != nil {
+		if x, ok := x.Item.(*StreamNearbyResponse_SourceWarning); ok {
+			return x.SourceWarning
+		}
+	}
+	return nil
+}
+
+type isStreamNearbyResponse_Item interface {
+	isStreamNearbyResponse_Item()
+}
+
+type StreamNearbyResponse_Hub struct {
+	Hub *NearbyHub `protobuf:"bytes,1,opt,name=hub,proto3,oneof"`
+}
+
+type StreamNearbyResponse_Warning struct {
+	Warning *RowWarning `protobuf:"bytes,2,opt,name=warning,proto3,oneof"`
+}
+
+type StreamNearbyResponse_Stale struct {
+	// stale is sent before any hubs when they come from a local snapshot.
+	Stale *Staleness `protobuf:"bytes,3,opt,name=stale,proto3,oneof"`
+}
+
+type StreamNearbyResponse_SourceWarning struct {
+	// source_warning is sent before any hubs for each backend left out of
+	// a partial result.
+	SourceWarning *SourceWarning `protobuf:"bytes,4,opt,name=source_warning,json=sourceWarning,proto3,oneof"`
+}
+
+func (*StreamNearbyResponse_Hub) isStreamNearbyResponse_Item() {}
+
+func (*StreamNearbyResponse_Warning) isStreamNearbyResponse_Item() {}
+
+func (*StreamNearbyResponse_Stale) isStreamNearbyResponse_Item() {}
+
+func (*StreamNearbyResponse_SourceWarning) isStreamNearbyResponse_Item() {}
+
+// Staleness describes the local snapshot a result was served from.
+type Staleness struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SnapshotAge   *durationpb.Duration   `protobuf:"bytes,1,opt,name=snapshot_age,json=snapshotAge,proto3" json:"snapshot_age,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Staleness) Reset() {
+	*x = Staleness{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Staleness) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Staleness) ProtoMessage() {}
+
+func (x *Staleness) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Staleness.ProtoReflect.Descriptor instead.
+func (*Staleness) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Staleness) GetSnapshotAge() *durationpb.Duration {
+	if x != nil {
+		return x.SnapshotAge
+	}
+	return nil
+}
+
+type FindNearestRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Center *Point                 `protobuf:"bytes,1,opt,name=center,proto3" json:"center,omitempty"`
+	// count is the number of hubs to return and must be positive.
+	Count int32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// max_radius_km bounds the search. Zero searches the whole globe.
+	MaxRadiusKm   float64 `protobuf:"fixed64,3,opt,name=max_radius_km,json=maxRadiusKm,proto3" json:"max_radius_km,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindNearestRequest) Reset() {
+	*x = FindNearestRequest{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindNearestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindNearestRequest) ProtoMessage() {}
+
+func (x *FindNearestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindNearestRequest.ProtoReflect.Descriptor instead.
+func (*FindNearestRequest) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FindNearestRequest) GetCenter() *Point {
+	if x != nil {
+		return x.Center
+	}
+	return nil
+}
+
+func (x *FindNearestRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *FindNearestRequest) GetMaxRadiusKm() float64 {
+	if x != nil {
+		return x.MaxRadiusKm
+	}
+	return 0
+}
+
+type FindNearestResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Hubs           []*NearbyHub           `protobuf:"bytes,1,rep,name=hubs,proto3" json:"hubs,omitempty"`
+	Warnings       []*RowWarning          `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Stale          bool                   `protobuf:"varint,3,opt,name=stale,proto3" json:"stale,omitempty"`
+	SnapshotAge    *durationpb.Duration   `protobuf:"bytes,4,opt,name=snapshot_age,json=snapshotAge,proto3" json:"snapshot_age,omitempty"`
+	SourceWarnings []*SourceWarning       `protobuf:"bytes,5,rep,name=source_warnings,json=sourceWarnings,proto3" json:"source_warnings,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FindNearestResponse) Reset() {
+	*x = FindNearestResponse{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindNearestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindNearestResponse) ProtoMessage() {}
+
+func (x *FindNearestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindNearestResponse.ProtoReflect.Descriptor instead.
+func (*FindNearestResponse) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FindNearestResponse) GetHubs() []*NearbyHub {
+	if x != nil {
+		return x.Hubs
+	}
+	return nil
+}
+
+func (x *FindNearestResponse) GetWarnings() []*RowWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *FindNearestResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *FindNearestResponse) GetSnapshotAge() *durationpb.Duration {
+	if x != nil {
+		return x.SnapshotAge
+	}
+	return nil
+}
+
+func (x *FindNearestResponse) GetSourceWarnings() []*SourceWarning {
+	if x != nil {
+		return x.SourceWarnings
+	}
+	return nil
+}
+
+type FindInPolygonRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// vertices lists at least three corners of the polygon. The polygon is
+	// closed implicitly and must not cross the antimeridian.
+	Vertices      []*Point `protobuf:"bytes,1,rep,name=vertices,proto3" json:"vertices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindInPolygonRequest) Reset() {
+	*x = FindInPolygonRequest{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindInPolygonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindInPolygonRequest) ProtoMessage() {}
+
+func (x *FindInPolygonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindInPolygonRequest.ProtoReflect.Descriptor instead.
+func (*FindInPolygonRequest) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FindInPolygonRequest) GetVertices() []*Point {
+	if x != nil {
+		return x.Vertices
+	}
+	return nil
+}
+
+type FindInPolygonResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Hubs           []*Hub                 `protobuf:"bytes,1,rep,name=hubs,proto3" json:"hubs,omitempty"`
+	Warnings       []*RowWarning          `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Stale          bool                   `protobuf:"varint,3,opt,name=stale,proto3" json:"stale,omitempty"`
+	SnapshotAge    *durationpb.Duration   `protobuf:"bytes,4,opt,name=snapshot_age,json=snapshotAge,proto3" json:"snapshot_age,omitempty"`
+	SourceWarnings []*SourceWarning       `protobuf:"bytes,5,rep,name=source_warnings,json=sourceWarnings,proto3" json:"source_warnings,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FindInPolygonResponse) Reset() {
+	*x = FindInPolygonResponse{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindInPolygonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindInPolygonResponse) ProtoMessage() {}
+
+func (x *FindInPolygonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindInPolygonResponse.ProtoReflect.Descriptor instead.
+func (*FindInPolygonResponse) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *FindInPolygonResponse) GetHubs() []*Hub {
+	if x != nil {
+		return x.Hubs
+	}
+	return nil
+}
+
+func (x *FindInPolygonResponse) GetWarnings() []*RowWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *FindInPolygonResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *FindInPolygonResponse) GetSnapshotAge() *durationpb.Duration {
+	if x != nil {
+		return x.SnapshotAge
+	}
+	return nil
+}
+
+func (x *FindInPolygonResponse) GetSourceWarnings() []*SourceWarning {
+	if x != nil {
+		return x.SourceWarnings
+	}
+	return nil
+}
+
+type GetHubRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHubRequest) Reset() {
+	*x = GetHubRequest{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHubRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHubRequest) ProtoMessage() {}
+
+func (x *GetHubRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHubRequest.ProtoReflect.Descriptor instead.
+func (*GetHubRequest) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetHubRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetHubResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hub           *Hub                   `protobuf:"bytes,1,opt,name=hub,proto3" json:"hub,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHubResponse) Reset() {
+	*x = GetHubResponse{}
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHubResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHubResponse) ProtoMessage() {}
+
+func (x *GetHubResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hubfinder_v1_hubfinder_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHubResponse.ProtoReflect.Descriptor instead.
+func (*GetHubResponse) Descriptor() ([]byte, []int) {
+	return file_hubfinder_v1_hubfinder_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetHubResponse) GetHub() *Hub {
+	if x != nil {
+		return x.Hub
+	}
+	return nil
+}
+
+var File_hubfinder_v1_hubfinder_proto protoreflect.FileDescriptor
+
+const file_hubfinder_v1_hubfinder_proto_rawDesc = "" +
+	"\n" +
+	"\x1chubfinder/v1/hubfinder.proto\x12\fhubfinder.v1\x1a\x1egoogle/protobuf/duration.proto\"+\n" +
+	"\x05Point\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lon\x18\x02 \x01(\x01R\x03lon\"\xe1\x01\n" +
+	"\x03Hub\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12/\n" +
+	"\blocation\x18\x03 \x01(\v2\x13.hubfinder.v1.PointR\blocation\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x16\n" +
+	"\x06routes\x18\x05 \x01(\x05R\x06routes\x12'\n" +
+	"\x0fpassenger_class\x18\x06 \x01(\tR\x0epassengerClass\x12\x18\n" +
+	"\acountry\x18\a \x01(\tR\acountry\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\"\xa7\x01\n" +
+	"\tNearbyHub\x12#\n" +
+	"\x03hub\x18\x01 \x01(\v2\x11.hubfinder.v1.HubR\x03hub\x12\x1f\n" +
+	"\vdistance_km\x18\x02 \x01(\x01R\n" +
+	"distanceKm\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\x12>\n" +
+	"\rscore_factors\x18\x04 \x03(\v2\x19.hubfinder.v1.ScoreFactorR\fscoreFactors\"O\n" +
+	"\vScoreFactor\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x01R\x06weight\"4\n" +
+	"\n" +
+	"RowWarning\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"=\n" +
+	"\rSourceWarning\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x9f\x01\n" +
+	"\x11FindNearbyRequest\x12+\n" +
+	"\x06center\x18\x01 \x01(\v2\x13.hubfinder.v1.PointR\x06center\x12\x1b\n" +
+	"\tradius_km\x18\x02 \x01(\x01R\bradiusKm\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12\x12\n" +
+	"\x04sort\x18\x05 \x01(\tR\x04sort\"\xcd\x02\n" +
+	"\x12FindNearbyResponse\x12+\n" +
+	"\x04hubs\x18\x01 \x03(\v2\x17.hubfinder.v1.NearbyHubR\x04hubs\x124\n" +
+	"\bwarnings\x18\x02 \x03(\v2\x18.hubfinder.v1.RowWarningR\bwarnings\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x1f\n" +
+	"\vnext_offset\x18\x04 \x01(\x05R\n" +
+	"nextOffset\x12\x14\n" +
+	"\x05stale\x18\x05 \x01(\bR\x05stale\x12<\n" +
+	"\fsnapshot_age\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\vsnapshotAge\x12D\n" +
+	"\x0fsource_warnings\x18\a \x03(\v2\x1b.hubfinder.v1.SourceWarningR\x0esourceWarnings\"s\n" +
+	"\x13StreamNearbyRequest\x12+\n" +
+	"\x06center\x18\x01 \x01(\v2\x13.hubfinder.v1.PointR\x06center\x12\x1b\n" +
+	"\tradius_km\x18\x02 \x01(\x01R\bradiusKm\x12\x12\n" +
+	"\x04sort\x18\x03 \x01(\tR\x04sort\"\xf8\x01\n" +
+	"\x14StreamNearbyResponse\x12+\n" +
+	"\x03hub\x18\x01 \x01(\v2\x17.hubfinder.v1.NearbyHubH\x00R\x03hub\x124\n" +
+	"\awarning\x18\x02 \x01(\v2\x18.hubfinder.v1.RowWarningH\x00R\awarning\x12/\n" +
+	"\x05stale\x18\x03 \x01(\v2\x17.hubfinder.v1.StalenessH\x00R\x05stale\x12D\n" +
+	"\x0esource_warning\x18\x04 \x01(\v2\x1b.hubfinder.v1.SourceWarningH\x00R\rsourceWarningB\x06\n" +
+	"\x04item\"I\n" +
+	"\tStaleness\x12<\n" +
+	"\fsnapshot_age\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\vsnapshotAge\"{\n" +
+	"\x12FindNearestRequest\x12+\n" +
+	"\x06center\x18\x01 \x01(\v2\x13.hubfinder.v1.PointR\x06center\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\"\n" +
+	"\rmax_radius_km\x18\x03 \x01(\x01R\vmaxRadiusKm\"\x92\x02\n" +
+	"\x13FindNearestResponse\x12+\n" +
+	"\x04hubs\x18\x01 \x03(\v2\x17.hubfinder.v1.NearbyHubR\x04hubs\x124\n" +
+	"\bwarnings\x18\x02 \x03(\v2\x18.hubfinder.v1.RowWarningR\bwarnings\x12\x14\n" +
+	"\x05stale\x18\x03 \x01(\bR\x05stale\x12<\n" +
+	"\fsnapshot_age\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\vsnapshotAge\x12D\n" +
+	"\x0fsource_warnings\x18\x05 \x03(\v2\x1b.hubfinder.v1.SourceWarningR\x0esourceWarnings\"G\n" +
+	"\x14FindInPolygonRequest\x12/\n" +
+	"\bvertices\x18\x01 \x03(\v2\x13.hubfinder.v1.PointR\bvertices\"\x8e\x02\n" +
+	"\x15FindInPolygonResponse\x12%\n" +
+	"\x04hubs\x18\x01 \x03(\v2\x11.hubfinder.v1.HubR\x04hubs\x124\n" +
+	"\bwarnings\x18\x02 \x03(\v2\x18.hubfinder.v1.RowWarningR\bwarnings\x12\x14\n" +
+	"\x05stale\x18\x03 \x01(\bR\x05stale\x12<\n" +
+	"\fsnapshot_age\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\vsnapshotAge\x12D\n" +
+	"\x0fsource_warnings\x18\x05 \x03(\v2\x1b.hubfinder.v1.SourceWarningR\x0esourceWarnings\"\x1f\n" +
+	"\rGetHubRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"5\n" +
+	"\x0eGetHubResponse\x12#\n" +
+	"\x03hub\x18\x01 \x01(\v2\x11.hubfinder.v1.HubR\x03hub2\xaf\x03\n" +
+	"\x10HubFinderService\x12O\n" +
+	"\n" +
+	"FindNearby\x12\x1f.hubfinder.v1.FindNearbyRequest\x1a .hubfinder.v1.FindNearbyResponse\x12W\n" +
+	"\fStreamNearby\x12!.hubfinder.v1.StreamNearbyRequest\x1a\".hubfinder.v1.StreamNearbyResponse0\x01\x12R\n" +
+	"\vFindNearest\x12 .hubfinder.v1.FindNearestRequest\x1a!.hubfinder.v1.FindNearestResponse\x12X\n" +
+	"\rFindInPolygon\x12\".hubfinder.v1.FindInPolygonRequest\x1a#.hubfinder.v1.FindInPolygonResponse\x12C\n" +
+	"\x06GetHub\x12\x1b.hubfinder.v1.GetHubRequest\x1a\x1c.hubfinder.v1.GetHubResponseBTZRgithub.com/osvathbotond/cloudant-airportdb-go/internal/api/hubfinderv1;hubfinderv1b\x06proto3"
+
+var (
+	file_hubfinder_v1_hubfinder_proto_rawDescOnce sync.Once
+	file_hubfinder_v1_hubfinder_proto_rawDescData []byte
+)
+
+func file_hubfinder_v1_hubfinder_proto_rawDescGZIP() []byte {
+	file_hubfinder_v1_hubfinder_proto_rawDescOnce.Do(func() {
+		file_hubfinder_v1_hubfinder_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_hubfinder_v1_hubfinder_proto_rawDesc), len(file_hubfinder_v1_hubfinder_proto_rawDesc)))
+	})
+	return file_hubfinder_v1_hubfinder_proto_rawDescData
+}
+
+var file_hubfinder_v1_hubfinder_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_hubfinder_v1_hubfinder_proto_goTypes = []any{
+	(*Point)(nil),                 // 0: hubfinder.v1.Point
+	(*Hub)(nil),                   // 1: hubfinder.v1.Hub
+	(*NearbyHub)(nil),             // 2: hubfinder.v1.NearbyHub
+	(*ScoreFactor)(nil),           // 3: hubfinder.v1.ScoreFactor
+	(*RowWarning)(nil),            // 4: hubfinder.v1.RowWarning
+	(*SourceWarning)(nil),         // 5: hubfinder.v1.SourceWarning
+	(*FindNearbyRequest)(nil),     // 6: hubfinder.v1.FindNearbyRequest
+	(*FindNearbyResponse)(nil),    // 7: hubfinder.v1.FindNearbyResponse
+	(*StreamNearbyRequest)(nil),   // 8: hubfinder.v1.StreamNearbyRequest
+	(*StreamNearbyResponse)(nil),  // 9: hubfinder.v1.StreamNearbyResponse
+	(*Staleness)(nil),             // 10: hubfinder.v1.Staleness
+	(*FindNearestRequest)(nil),    // 11: hubfinder.v1.FindNearestRequest
+	(*FindNearestResponse)(nil),   // 12: hubfinder.v1.FindNearestResponse
+	(*FindInPolygonRequest)(nil),  // 13: hubfinder.v1.FindInPolygonRequest
+	(*FindInPolygonResponse)(nil), // 14: hubfinder.v1.FindInPolygonResponse
+	(*GetHubRequest)(nil),         // 15: hubfinder.v1.GetHubRequest
+	(*GetHubResponse)(nil),        // 16: hubfinder.v1.GetHubResponse
+	(*durationpb.Duration)(nil),   // 17: google.protobuf.Duration
+}
+var file_hubfinder_v1_hubfinder_proto_depIdxs = []int32{
+	0,  // 0: hubfinder.v1.Hub.location:type_name -> hubfinder.v1.Point
+	1,  // 1: hubfinder.v1.NearbyHub.hub:type_name -> hubfinder.v1.Hub
+	3,  // 2: hubfinder.v1.NearbyHub.score_factors:type_name -> hubfinder.v1.ScoreFactor
+	0,  // 3: hubfinder.v1.FindNearbyRequest.center:type_name -> hubfinder.v1.Point
+	2,  // 4: hubfinder.v1.FindNearbyResponse.hubs:type_name -> hubfinder.v1.NearbyHub
+	4,  // 5: hubfinder.v1.FindNearbyResponse.warnings:type_name -> hubfinder.v1.RowWarning
+	17, // 6: hubfinder.v1.FindNearbyResponse.snapshot_age:type_name -> google.protobuf.Duration
+	5,  // 7: hubfinder.v1.FindNearbyResponse.source_warnings:type_name -> hubfinder.v1.SourceWarning
+	0,  // 8: hubfinder.v1.StreamNearbyRequest.center:type_name -> hubfinder.v1.Point
+	2,  // 9: hubfinder.v1.StreamNearbyResponse.hub:type_name -> hubfinder.v1.NearbyHub
+	4,  // 10: hubfinder.v1.StreamNearbyResponse.warning:type_name -> hubfinder.v1.RowWarning
+	10, // 11: hubfinder.v1.StreamNearbyResponse.stale:type_name -> hubfinder.v1.Staleness
+	5,  // 12: hubfinder.v1.StreamNearbyResponse.source_warning:type_name -> hubfinder.v1.SourceWarning
+	17, // 13: hubfinder.v1.Staleness.snapshot_age:type_name -> google.protobuf.Duration
+	0,  // 14: hubfinder.v1.FindNearestRequest.center:type_name -> hubfinder.v1.Point
+	2,  // 15: hubfinder.v1.FindNearestResponse.hubs:type_name -> hubfinder.v1.NearbyHub
+	4,  // 16: hubfinder.v1.FindNearestResponse.warnings:type_name -> hubfinder.v1.RowWarning
+	17, // 17: hubfinder.v1.FindNearestResponse.snapshot_age:type_name -> google.protobuf.Duration
+	5,  // 18: hubfinder.v1.FindNearestResponse.source_warnings:type_name -> hubfinder.v1.SourceWarning
+	0,  // 19: hubfinder.v1.FindInPolygonRequest.vertices:type_name -> hubfinder.v1.Point
+	1,  // 20: hubfinder.v1.FindInPolygonResponse.hubs:type_name -> hubfinder.v1.Hub
+	4,  // 21: hubfinder.v1.FindInPolygonResponse.warnings:type_name -> hubfinder.v1.RowWarning
+	17, // 22: hubfinder.v1.FindInPolygonResponse.snapshot_age:type_name -> google.protobuf.Duration
+	5,  // 23: hubfinder.v1.FindInPolygonResponse.source_warnings:type_name -> hubfinder.v1.SourceWarning
+	1,  // 24: hubfinder.v1.GetHubResponse.hub:type_name -> hubfinder.v1.Hub
+	6,  // 25: hubfinder.v1.HubFinderService.FindNearby:input_type -> hubfinder.v1.FindNearbyRequest
+	8,  // 26: hubfinder.v1.HubFinderService.StreamNearby:input_type -> hubfinder.v1.StreamNearbyRequest
+	11, // 27: hubfinder.v1.HubFinderService.FindNearest:input_type -> hubfinder.v1.FindNearestRequest
+	13, // 28: hubfinder.v1.HubFinderService.FindInPolygon:input_type -> hubfinder.v1.FindInPolygonRequest
+	15, // 29: hubfinder.v1.HubFinderService.GetHub:input_type -> hubfinder.v1.GetHubRequest
+	7,  // 30: hubfinder.v1.HubFinderService.FindNearby:output_type -> hubfinder.v1.FindNearbyResponse
+	9,  // 31: hubfinder.v1.HubFinderService.StreamNearby:output_type -> hubfinder.v1.StreamNearbyResponse
+	12, // 32: hubfinder.v1.HubFinderService.FindNearest:output_type -> hubfinder.v1.FindNearestResponse
+	14, // 33: hubfinder.v1.HubFinderService.FindInPolygon:output_type -> hubfinder.v1.FindInPolygonResponse
+	16, // 34: hubfinder.v1.HubFinderService.GetHub:output_type -> hubfinder.v1.GetHubResponse
+	30, // [30:35] is the sub-list for method output_type
+	25, // [25:30] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
+}
+
+func init() { file_hubfinder_v1_hubfinder_proto_init() }
+func file_hubfinder_v1_hubfinder_proto_init() {
+	if File_hubfinder_v1_hubfinder_proto != nil {
+		return
+	}
+	file_hubfinder_v1_hubfinder_proto_msgTypes[9].OneofWrappers = []any{
+		(*StreamNearbyResponse_Hub)(nil),
+		(*StreamNearbyResponse_Warning)(nil),
+		(*StreamNearbyResponse_Stale)(nil),
+		(*StreamNearbyResponse_SourceWarning)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_hubfinder_v1_hubfinder_proto_rawDesc), len(file_hubfinder_v1_hubfinder_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_hubfinder_v1_hubfinder_proto_goTypes,
+		DependencyIndexes: file_hubfinder_v1_hubfinder_proto_depIdxs,
+		MessageInfos:      file_hubfinder_v1_hubfinder_proto_msgTypes,
+	}.Build()
+	File_hubfinder_v1_hubfinder_proto = out.File
+	file_hubfinder_v1_hubfinder_proto_goTypes = nil
+	file_hubfinder_v1_hubfinder_proto_depIdxs = nil
+}
@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: hubfinder/v1/hubfinder.proto
+
+package hubfinderv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	HubFinderService_FindNearby_FullMethodName    = "/hubfinder.v1.HubFinderService/FindNearby"
+	HubFinderService_StreamNearby_FullMethodName  = "/hubfinder.v1.HubFinderService/StreamNearby"
+	HubFinderService_FindNearest_FullMethodName   = "/hubfinder.v1.HubFinderService/FindNearest"
+	HubFinderService_FindInPolygon_FullMethodName = "/hubfinder.v1.HubFinderService/FindInPolygon"
+	HubFinderService_GetHub_FullMethodName        = "/hubfinder.v1.HubFinderService/GetHub"
+)
+
+// HubFinderServiceClient is the client API for HubFinderService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HubFinderService looks up transport hubs by location.
+type HubFinderServiceClient interface {
+	// FindNearby returns the hubs within a radius of a point, closest first
+	// unless another sort is requested.
+	FindNearby(ctx context.Context, in *FindNearbyRequest, opts ...grpc.CallOption) (*FindNearbyResponse, error)
+	// StreamNearby returns the same hubs as FindNearby one message at a time,
+	// for result sets too large to hold in a single response.
+	StreamNearby(ctx context.Context, in *StreamNearbyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamNearbyResponse], error)
+	// FindNearest returns the closest hubs to a point.
+	FindNearest(ctx context.Context, in *FindNearestRequest, opts ...grpc.CallOption) (*FindNearestResponse, error)
+	// FindInPolygon returns the hubs inside a polygon.
+	FindInPolygon(ctx context.Context, in *FindInPolygonRequest, opts ...grpc.CallOption) (*FindInPolygonResponse, error)
+	// GetHub returns a single hub by ID.
+	GetHub(ctx context.Context, in *GetHubRequest, opts ...grpc.CallOption) (*GetHubResponse, error)
+}
+
+type hubFinderServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHubFinderServiceClient(cc grpc.ClientConnInterface) HubFinderServiceClient {
+	return &hubFinderServiceClient{cc}
+}
+
+func (c *hubFinderServiceClient) FindNearby(ctx context.Context, in *FindNearbyRequest, opts ...grpc.CallOption) (*FindNearbyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindNearbyResponse)
+	err := c.cc.Invoke(ctx, HubFinderService_FindNearby_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubFinderServiceClient) StreamNearby(ctx context.Context, in *StreamNearbyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamNearbyResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HubFinderService_ServiceDesc.Streams[0], HubFinderService_StreamNearby_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamNearbyRequest, StreamNearbyResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HubFinderService_StreamNearbyClient = grpc.ServerStreamingClient[StreamNearbyResponse]
+
+func (c *hubFinderServiceClient) FindNearest(ctx context.Context, in *FindNearestRequest, opts ...grpc.CallOption) (*FindNearestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindNearestResponse)
+	err := c.cc.Invoke(ctx, HubFinderService_FindNearest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubFinderServiceClient) FindInPolygon(ctx context.Context, in *FindInPolygonRequest, opts ...grpc.CallOption) (*FindInPolygonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindInPolygonResponse)
+	err := c.cc.Invoke(ctx, HubFinderService_FindInPolygon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubFinderServiceClient) GetHub(ctx context.Context, in *GetHubRequest, opts ...grpc.CallOption) (*GetHubResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHubResponse)
+	err := c.cc.Invoke(ctx, HubFinderService_GetHub_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HubFinderServiceServer is the server API for HubFinderService service.
+// All implementations must embed UnimplementedHubFinderServiceServer
+// for forward compatibility.
+//
+// HubFinderService looks up transport hubs by location.
+type HubFinderServiceServer interface {
+	// FindNearby returns the hubs within a radius of a point, closest first
+	// unless another sort is requested.
+	FindNearby(context.Context, *FindNearbyRequest) (*FindNearbyResponse, error)
+	// StreamNearby returns the same hubs as FindNearby one message at a time,
+	// for result sets too large to hold in a single response.
+	StreamNearby(*StreamNearbyRequest, grpc.ServerStreamingServer[StreamNearbyResponse]) error
+	// FindNearest returns the closest hubs to a point.
+	FindNearest(context.Context, *FindNearestRequest) (*FindNearestResponse, error)
+	// FindInPolygon returns the hubs inside a polygon.
+	FindInPolygon(context.Context, *FindInPolygonRequest) (*FindInPolygonResponse, error)
+	// GetHub returns a single hub by ID.
+	GetHub(context.Context, *GetHubRequest) (*GetHubResponse, error)
+	mustEmbedUnimplementedHubFinderServiceServer()
+}
+
+// UnimplementedHubFinderServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHubFinderServiceServer struct{}
+
+func (UnimplementedHubFinderServiceServer) FindNearby(context.Context, *FindNearbyRequest) (*FindNearbyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FindNearby not implemented")
+}
+func (UnimplementedHubFinderServiceServer) StreamNearby(*StreamNearbyRequest, grpc.ServerStreamingServer[StreamNearbyResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamNearby not implemented")
+}
+func (UnimplementedHubFinderServiceServer) FindNearest(context.Context, *FindNearestRequest) (*FindNearestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FindNearest not implemented")
+}
+func (UnimplementedHubFinderServiceServer) FindInPolygon(context.Context, *FindInPolygonRequest) (*FindInPolygonResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FindInPolygon not implemented")
+}
+func (UnimplementedHubFinderServiceServer) GetHub(context.Context, *GetHubRequest) (*GetHubResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHub not implemented")
+}
+func (UnimplementedHubFinderServiceServer) mustEmbedUnimplementedHubFinderServiceServer() {}
+func (UnimplementedHubFinderServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeHubFinderServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HubFinderServiceServer will
+// result in compilation errors.
+type UnsafeHubFinderServiceServer interface {
+	mustEmbedUnimplementedHubFinderServiceServer()
+}
+
+func RegisterHubFinderServiceServer(s grpc.ServiceRegistrar, srv HubFinderServiceServer) {
+	// If the following call panics, it indicates UnimplementedHubFinderServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HubFinderService_ServiceDesc, srv)
+}
+
+func _HubFinderService_FindNearby_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindNearbyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubFinderServiceServer).FindNearby(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HubFinderService_FindNearby_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubFinderServiceServer).FindNearby(ctx, req.(*FindNearbyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HubFinderService_StreamNearby_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamNearbyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubFinderServiceServer).StreamNearby(m, &grpc.GenericServerStream[StreamNearbyRequest, StreamNearbyResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HubFinderService_StreamNearbyServer = grpc.ServerStreamingServer[StreamNearbyResponse]
+
+func _HubFinderService_FindNearest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindNearestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubFinderServiceServer).FindNearest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HubFinderService_FindNearest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubFinderServiceServer).FindNearest(ctx, req.(*FindNearestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HubFinderService_FindInPolygon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindInPolygonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubFinderServiceServer).FindInPolygon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HubFinderService_FindInPolygon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubFinderServiceServer).FindInPolygon(ctx, req.(*FindInPolygonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HubFinderService_GetHub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubFinderServiceServer).GetHub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HubFinderService_GetHub_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubFinderServiceServer).GetHub(ctx, req.(*GetHubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HubFinderService_ServiceDesc is the grpc.ServiceDesc for HubFinderService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HubFinderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hubfinder.v1.HubFinderService",
+	HandlerType: (*HubFinderServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FindNearby",
+			Handler:    _HubFinderService_FindNearby_Handler,
+		},
+		{
+			MethodName: "FindNearest",
+			Handler:    _HubFinderService_FindNearest_Handler,
+		},
+		{
+			MethodName: "FindInPolygon",
+			Handler:    _HubFinderService_FindInPolygon_Handler,
+		},
+		{
+			MethodName: "GetHub",
+			Handler:    _HubFinderService_GetHub_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamNearby",
+			Handler:       _HubFinderService_StreamNearby_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "hubfinder/v1/hubfinder.proto",
+}
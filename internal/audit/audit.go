@@ -0,0 +1,220 @@
+// Package audit checks a hub data set for entries that are probably wrong,
+// such as impossible or swapped coordinates, hubs in the open ocean and
+// duplicate IDs.
+package audit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+const (
+	defaultOceanDistanceKm = 200
+	defaultMinDecimals     = 2
+)
+
+type Config struct {
+	// OceanDistanceKm is how far from land, according to the coarse
+	// embedded land mask, a hub must be to be reported as in the ocean.
+	// Defaults to 200.
+	OceanDistanceKm float64
+	// MinDecimals is the number of decimal places below which coordinates
+	// are reported as imprecise. Defaults to 2, about a kilometre.
+	MinDecimals int
+}
+
+// Check identifies a kind of problem.
+type Check string
+
+const (
+	// CheckMalformedRow marks a backend row that could not be read as a
+	// hub, such as one with a missing name or non-numeric coordinates.
+	CheckMalformedRow Check = "malformed_row"
+	// CheckOutOfRange marks coordinates outside ±90 latitude or ±180
+	// longitude.
+	CheckOutOfRange Check = "out_of_range"
+	// CheckSwapped marks coordinates that are out of range while the
+	// swapped pair is not, so latitude and longitude were almost certainly
+	// swapped.
+	CheckSwapped Check = "swapped"
+	// CheckSwappedOcean marks hubs in the ocean whose swapped coordinates
+	// are on land. Unlike CheckSwapped, the coordinates may still be right.
+	CheckSwappedOcean Check = "swapped_ocean"
+	// CheckNullIsland marks hubs at exactly (0, 0), a common placeholder
+	// for missing coordinates.
+	CheckNullIsland Check = "null_island"
+	// CheckMissingName marks hubs with an empty or blank name.
+	CheckMissingName Check = "missing_name"
+	// CheckDuplicateID marks every hub after the first with a given ID.
+	CheckDuplicateID Check = "duplicate_id"
+	// CheckOcean marks hubs far from any land.
+	CheckOcean Check = "ocean"
+	// CheckLowPrecision marks coordinates with fewer decimal places than
+	// Config.MinDecimals, which often means they were rounded or guessed.
+	CheckLowPrecision Check = "low_precision"
+)
+
+// Checks lists every check in the order findings are reported.
+var Checks = []Check{
+	CheckMalformedRow,
+	CheckOutOfRange,
+	CheckSwapped,
+	CheckSwappedOcean,
+	CheckNullIsland,
+	CheckMissingName,
+	CheckDuplicateID,
+	CheckOcean,
+	CheckLowPrecision,
+}
+
+// Severity tells how likely a finding is to be a real problem.
+type Severity string
+
+const (
+	// SeverityError marks data that cannot be right.
+	SeverityError Severity = "error"
+	// SeverityWarning marks data that is suspicious but may be right.
+	SeverityWarning Severity = "warning"
+)
+
+// Severity returns the severity of findings of the check.
+func (c Check) Severity() Severity {
+	switch c {
+	case CheckMalformedRow, CheckOutOfRange, CheckSwapped, CheckMissingName, CheckDuplicateID:
+		return SeverityError
+	default:
+		return SeverityWarning
+	}
+}
+
+// Finding is a problem with one hub.
+type Finding struct {
+	Check    Check    `json:"check"`
+	Severity Severity `json:"severity"`
+	// ID is the hub ID, or empty for a row without one.
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	id := f.ID
+	if id == "" {
+		id = "<missing id>"
+	}
+	return fmt.Sprintf("%s %s: %s", f.Severity, id, f.Message)
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	// Hubs is the number of hubs and malformed rows examined.
+	Hubs int `json:"hubs"`
+	// Counts holds the number of findings of every check, including
+	// checks without findings.
+	Counts   map[Check]int `json:"counts"`
+	Findings []Finding     `json:"findings"`
+}
+
+// Errors returns the number of findings with SeverityError.
+func (r Report) Errors() int {
+	n := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Run checks hubs and the rows a repository skipped while reading them.
+// Findings are ordered by check, then by position in hubs.
+func Run(hubs []model.Hub, skipped []repository.RowWarning, cfg Config) Report {
+	if cfg.OceanDistanceKm <= 0 {
+		cfg.OceanDistanceKm = defaultOceanDistanceKm
+	}
+	if cfg.MinDecimals <= 0 {
+		cfg.MinDecimals = defaultMinDecimals
+	}
+	mask := defaultLandMask()
+
+	byCheck := make(map[Check][]Finding)
+	add := func(check Check, id, format string, args ...any) {
+		byCheck[check] = append(byCheck[check], Finding{
+			Check:    check,
+			Severity: check.Severity(),
+			ID:       id,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, row := range skipped {
+		add(CheckMalformedRow, row.ID, "%s", row.Reason)
+	}
+
+	seen := make(map[string]int, len(hubs))
+	for _, hub := range hubs {
+		seen[hub.ID]++
+		if seen[hub.ID] == 2 {
+			add(CheckDuplicateID, hub.ID, "ID is used by more than one hub")
+		}
+		if strings.TrimSpace(hub.Name) == "" {
+			add(CheckMissingName, hub.ID, "name is empty")
+		}
+
+		lat, lon := hub.Lat, hub.Lon
+		if !inRange(lat, lon) {
+			if inRange(lon, lat) {
+				add(CheckSwapped, hub.ID, "(%g, %g) is out of range but (%g, %g) is not", lat, lon, lon, lat)
+			} else {
+				add(CheckOutOfRange, hub.ID, "(%g, %g) is out of range", lat, lon)
+			}
+			continue
+		}
+		if lat == 0 && lon == 0 {
+			add(CheckNullIsland, hub.ID, "coordinates are (0, 0)")
+			continue
+		}
+
+		if _, near := mask.distanceToLand(lat, lon, cfg.OceanDistanceKm); !near {
+			if inRange(lon, lat) && mask.isLand(lon, lat) {
+				add(CheckSwappedOcean, hub.ID, "(%g, %g) is in the ocean but (%g, %g) is on land", lat, lon, lon, lat)
+			} else {
+				add(CheckOcean, hub.ID, "(%g, %g) is more than %g km from land", lat, lon, cfg.OceanDistanceKm)
+			}
+		}
+		if decimals(lat) < cfg.MinDecimals && decimals(lon) < cfg.MinDecimals {
+			add(CheckLowPrecision, hub.ID, "(%g, %g) has fewer than %d decimal places", lat, lon, cfg.MinDecimals)
+		}
+	}
+
+	report := Report{Hubs: len(hubs) + len(skipped), Counts: make(map[Check]int, len(Checks))}
+	for _, check := range Checks {
+		report.Counts[check] = len(byCheck[check])
+		report.Findings = append(report.Findings, byCheck[check]...)
+	}
+	if report.Findings == nil {
+		report.Findings = []Finding{}
+	}
+	return report
+}
+
+func inRange(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// decimals returns the number of decimal places in the shortest text form
+// of v.
+func decimals(v float64) int {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	text := strconv.FormatFloat(v, 'f', -1, 64)
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		return len(text) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		hub  model.Hub
+		want []Check
+	}{
+		{name: "valid", hub: model.Hub{ID: "bud", Name: "Budapest", Lat: 47.4369, Lon: 19.2556}},
+		{name: "island airport", hub: model.Hub{ID: "hnl", Name: "Honolulu", Lat: 21.3187, Lon: -157.9225}},
+		{name: "out of range", hub: model.Hub{ID: "x", Name: "X", Lat: 95.12, Lon: 200.34}, want: []Check{CheckOutOfRange}},
+		{name: "not a number", hub: model.Hub{ID: "x", Name: "X", Lat: math.NaN(), Lon: 19.25}, want: []Check{CheckOutOfRange}},
+		{name: "swapped out of range", hub: model.Hub{ID: "x", Name: "X", Lat: 151.1772, Lon: -33.9461}, want: []Check{CheckSwapped}},
+		{name: "swapped into the ocean", hub: model.Hub{ID: "x", Name: "X", Lat: -43.2, Lon: -22.91}, want: []Check{CheckSwappedOcean}},
+		{name: "null island", hub: model.Hub{ID: "x", Name: "X"}, want: []Check{CheckNullIsland}},
+		{name: "missing name", hub: model.Hub{ID: "x", Name: "  ", Lat: 47.4369, Lon: 19.2556}, want: []Check{CheckMissingName}},
+		{name: "ocean", hub: model.Hub{ID: "x", Name: "X", Lat: 30.123, Lon: -40.456}, want: []Check{CheckOcean}},
+		{name: "low precision", hub: model.Hub{ID: "x", Name: "X", Lat: 47.4, Lon: 19}, want: []Check{CheckLowPrecision}},
+		{name: "one precise coordinate", hub: model.Hub{ID: "x", Name: "X", Lat: 47.4369, Lon: 19}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Run([]model.Hub{tt.hub}, nil, Config{})
+			var got []Check
+			for _, finding := range report.Findings {
+				got = append(got, finding.Check)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v (%v), want %v", got, report.Findings, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Report(t *testing.T) {
+	hubs := []model.Hub{
+		{ID: "bud", Name: "Budapest", Lat: 47.4369, Lon: 19.2556},
+		{ID: "bud", Name: "Budapest again", Lat: 47.4369, Lon: 19.2556},
+		{ID: "bud", Name: "Budapest once more", Lat: 47.4369, Lon: 19.2556},
+		{ID: "origin", Name: "", Lat: 0, Lon: 0},
+		{ID: "syd", Name: "Sydney", Lat: 151.1772, Lon: -33.9461},
+	}
+	skipped := []repository.RowWarning{{ID: "bad", Reason: "lat 95 is out of range"}}
+
+	report := Run(hubs, skipped, Config{})
+	if report.Hubs != 6 {
+		t.Errorf("got %d hubs, want 6", report.Hubs)
+	}
+	want := []Finding{
+		{Check: CheckMalformedRow, Severity: SeverityError, ID: "bad", Message: "lat 95 is out of range"},
+		{Check: CheckSwapped, Severity: SeverityError, ID: "syd", Message: "(151.1772, -33.9461) is out of range but (-33.9461, 151.1772) is not"},
+		{Check: CheckNullIsland, Severity: SeverityWarning, ID: "origin", Message: "coordinates are (0, 0)"},
+		{Check: CheckMissingName, Severity: SeverityError, ID: "origin", Message: "name is empty"},
+		{Check: CheckDuplicateID, Severity: SeverityError, ID: "bud", Message: "ID is used by more than one hub"},
+	}
+	if !slices.Equal(report.Findings, want) {
+		t.Errorf("got findings %v, want %v", report.Findings, want)
+	}
+	if got := report.Errors(); got != 4 {
+		t.Errorf("got %d errors, want 4", got)
+	}
+	for _, check := range Checks {
+		if _, ok := report.Counts[check]; !ok {
+			t.Errorf("counts are missing %s", check)
+		}
+	}
+	if report.Counts[CheckDuplicateID] != 1 || report.Counts[CheckOcean] != 0 {
+		t.Errorf("got counts %v", report.Counts)
+	}
+}
+
+func TestLandMask(t *testing.T) {
+	mask := defaultLandMask()
+	tests := []struct {
+		name     string
+		lat, lon float64
+		wantNear bool
+	}{
+		{"Budapest", 47.4369, 19.2556, true},
+		{"Singapore Changi", 1.3644, 103.9915, true},
+		{"Nadi", -17.7553, 177.4433, true},
+		{"Longyearbyen", 78.2461, 15.4656, true},
+		{"McMurdo", -77.846, 166.676, true},
+		{"Mid-Atlantic", 0, -30, false},
+		{"South Pacific", -40, -120, false},
+		{"Sea of Okhotsk", 55, 150, false},
+		{"Gulf of Mexico", 25, -90, false},
+		{"Hudson Bay", 60, -87, false},
+	}
+	for _, tt := range tests {
+		if _, near := mask.distanceToLand(tt.lat, tt.lon, defaultOceanDistanceKm); near != tt.wantNear {
+			t.Errorf("%s: got near land %v, want %v", tt.name, near, tt.wantNear)
+		}
+	}
+}
+
+func TestParseLandMask(t *testing.T) {
+	mask, err := parseLandMask("# comment\nland square: 0,0 10,0 10,10 0,10\nwater hole: 4,4 6,4 6,6 4,6\npoint rock: -50.5,20.5\n")
+	if err != nil {
+		t.Fatalf("parseLandMask returned error: %v", err)
+	}
+	tests := []struct {
+		lat, lon float64
+		want     bool
+	}{
+		{2.5, 2.5, true},
+		{5.5, 5.5, false},
+		{20.5, -50.5, true},
+		{30.5, 30.5, false},
+	}
+	for _, tt := range tests {
+		if got := mask.isLand(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("isLand(%g, %g) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+
+	for _, data := range []string{"land a 0,0 1,1 1,0", "land a: 0,0 1,1", "sea a: 0,0 1,1 1,0", "point a: 0;0", "point a: 0,95"} {
+		if _, err := parseLandMask(data); err == nil {
+			t.Errorf("parseLandMask(%q) returned no error", data)
+		}
+	}
+}
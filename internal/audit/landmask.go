@@ -0,0 +1,195 @@
+package audit
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+)
+
+//go:embed land.txt
+var landData string
+
+// The land mask divides the globe into one degree cells.
+const (
+	maskRows = 180
+	maskCols = 360
+)
+
+// landMask records which cells of the globe hold land.
+type landMask [maskRows][maskCols]bool
+
+// defaultLandMask is the mask built from the embedded outlines.
+var defaultLandMask = sync.OnceValue(func() *landMask {
+	mask, err := parseLandMask(landData)
+	if err != nil {
+		panic(fmt.Sprintf("audit: embedded land mask: %v", err))
+	}
+	return mask
+})
+
+// cell returns the row and column of the cell holding (lat, lon).
+func cell(lat, lon float64) (row, col int) {
+	row = min(max(int(math.Floor(lat+90)), 0), maskRows-1)
+	col = min(max(int(math.Floor(lon+180)), 0), maskCols-1)
+	return row, col
+}
+
+// isLand reports whether the cell holding (lat, lon) holds land.
+func (m *landMask) isLand(lat, lon float64) bool {
+	row, col := cell(lat, lon)
+	return m[row][col]
+}
+
+// distanceToLand returns the distance in kilometres from (lat, lon) to the
+// centre of the nearest land cell, looking no further than limitKm. It
+// reports false if there is no land within limitKm.
+func (m *landMask) distanceToLand(lat, lon, limitKm float64) (float64, bool) {
+	if m.isLand(lat, lon) {
+		return 0, true
+	}
+	minLat, maxLat, minLon, maxLon, err := geo.CalculateBoundingBox(lat, lon, limitKm)
+	if err != nil {
+		return 0, false
+	}
+	minRow, _ := cell(minLat, 0)
+	maxRow, _ := cell(maxLat, 0)
+	var cols []int
+	if maxLon-minLon >= 359 {
+		for col := range maskCols {
+			cols = append(cols, col)
+		}
+	} else {
+		// The box may wrap across the antimeridian, so walk the columns
+		// modulo the width of the grid.
+		_, first := cell(0, minLon)
+		_, last := cell(0, maxLon)
+		for col := first; ; col = (col + 1) % maskCols {
+			cols = append(cols, col)
+			if col == last {
+				break
+			}
+		}
+	}
+
+	nearest, found := math.Inf(1), false
+	for row := minRow; row <= maxRow; row++ {
+		for _, col := range cols {
+			if !m[row][col] {
+				continue
+			}
+			distanceKm, _ := geo.HaversineDistance(lat, lon, float64(row)-89.5, float64(col)-179.5)
+			if distanceKm <= limitKm && distanceKm < nearest {
+				nearest, found = distanceKm, true
+			}
+		}
+	}
+	return nearest, found
+}
+
+// parseLandMask rasterizes the outlines described in land.txt. A cell holds
+// land if its centre lies inside a land outline and outside every water
+// outline, if a land outline passes through it, or if it holds a point.
+func parseLandMask(data string) (*landMask, error) {
+	var land, water [][]geo.Point
+	var points []geo.Point
+	for n, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		header, coords, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing colon", n+1)
+		}
+		kind, _, _ := strings.Cut(header, " ")
+		vertices, err := parseVertices(coords)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		switch kind {
+		case "land", "water":
+			if err := geo.ValidatePolygon(vertices); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			if kind == "land" {
+				land = append(land, vertices)
+			} else {
+				water = append(water, vertices)
+			}
+		case "point":
+			points = append(points, vertices...)
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", n+1, kind)
+		}
+	}
+
+	mask := new(landMask)
+	for row := range maskRows {
+		for col := range maskCols {
+			lat, lon := float64(row)-89.5, float64(col)-179.5
+			mask[row][col] = insideAny(lat, lon, land) && !insideAny(lat, lon, water)
+		}
+	}
+	for _, outline := range land {
+		for i := range outline {
+			markEdge(mask, outline[i], outline[(i+1)%len(outline)])
+		}
+	}
+	for _, p := range points {
+		row, col := cell(p.Lat, p.Lon)
+		mask[row][col] = true
+	}
+	return mask, nil
+}
+
+func parseVertices(s string) ([]geo.Point, error) {
+	fields := strings.Fields(s)
+	vertices := make([]geo.Point, 0, len(fields))
+	for _, field := range fields {
+		lonText, latText, ok := strings.Cut(field, ",")
+		if !ok {
+			return nil, fmt.Errorf("vertex %q is not lon,lat", field)
+		}
+		lon, err := strconv.ParseFloat(lonText, 64)
+		if err != nil {
+			return nil, fmt.Errorf("vertex %q: %w", field, err)
+		}
+		lat, err := strconv.ParseFloat(latText, 64)
+		if err != nil {
+			return nil, fmt.Errorf("vertex %q: %w", field, err)
+		}
+		if err := geo.ValidateCoordinates(lat, lon); err != nil {
+			return nil, fmt.Errorf("vertex %q: %w", field, err)
+		}
+		vertices = append(vertices, geo.Point{Lat: lat, Lon: lon})
+	}
+	return vertices, nil
+}
+
+func insideAny(lat, lon float64, polygons [][]geo.Point) bool {
+	for _, polygon := range polygons {
+		if geo.PointInPolygon(lat, lon, polygon) {
+			return true
+		}
+	}
+	return false
+}
+
+// markEdge marks the cells along the edge from a to b as land, sampling it
+// every quarter of a degree.
+func markEdge(mask *landMask, a, b geo.Point) {
+	steps := int(math.Ceil(max(math.Abs(b.Lat-a.Lat), math.Abs(b.Lon-a.Lon)) * 4))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		row, col := cell(a.Lat+t*(b.Lat-a.Lat), a.Lon+t*(b.Lon-a.Lon))
+		mask[row][col] = true
+	}
+}
@@ -0,0 +1,136 @@
+// Package coord parses geographic positions written in the notations used by
+// field teams: decimal degrees, degrees-minutes-seconds, UTM, MGRS, geohash
+// and Open Location Code (plus code).
+package coord
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Format identifies the notation a position was written in.
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatDecimal
+	FormatDMS
+	FormatUTM
+	FormatMGRS
+	FormatGeohash
+	FormatPlusCode
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatDecimal:
+		return "decimal degrees"
+	case FormatDMS:
+		return "DMS coordinates"
+	case FormatUTM:
+		return "UTM coordinates"
+	case FormatMGRS:
+		return "MGRS reference"
+	case FormatGeohash:
+		return "geohash"
+	case FormatPlusCode:
+		return "plus code"
+	default:
+		return "coordinates"
+	}
+}
+
+// Position is a validated WGS84 latitude and longitude in decimal degrees.
+type Position struct {
+	Lat    float64
+	Lon    float64
+	Format Format
+}
+
+// ParseError reports why an input could not be parsed as the format it
+// appeared to be written in.
+type ParseError struct {
+	Format Format
+	Input  string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	if e.Format == FormatUnknown {
+		return fmt.Sprintf("unrecognized location %q: %s", e.Input, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s %q: %s", e.Format, e.Input, e.Reason)
+}
+
+var (
+	utmPattern     = regexp.MustCompile(`^(\d{1,2})\s*([C-HJ-NP-X])\s+(\d+(?:\.\d+)?)\s*M?E?\s+(\d+(?:\.\d+)?)\s*M?N?$`)
+	mgrsPattern    = regexp.MustCompile(`^(\d{1,2})\s*([C-HJ-NP-X])\s*([A-HJ-NP-Z])([A-HJ-NP-V])\s*(\d*)\s*(\d*)$`)
+	geohashPattern = regexp.MustCompile(`^[0-9B-HJKMNP-Z]+$`)
+	// plusCodePattern matches the shape of a plus code, optionally followed
+	// by a locality, so that signed degrees such as +47.5 +19.0 are not
+	// mistaken for one.
+	plusCodePattern = regexp.MustCompile(`^([0-9A-Z]+\+[0-9A-Z]*)(?:\s+\S.*)?$`)
+)
+
+// Parse detects the notation of input and converts it into a Position. The
+// returned error is a *ParseError describing what is wrong for the detected
+// format. A string that is both a valid MGRS reference and a valid geohash
+// is read as MGRS, and one made of digits and hemisphere letters, such as
+// 47N19E, is read as degrees if it can be.
+func Parse(input string) (Position, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return Position{}, &ParseError{Input: input, Reason: "input must not be empty"}
+	}
+	upper := strings.ToUpper(trimmed)
+
+	var (
+		pos    Position
+		format Format
+		reason string
+	)
+	switch {
+	case plusCodePattern.MatchString(upper):
+		format = FormatPlusCode
+		pos, reason = parsePlusCode(plusCodePattern.FindStringSubmatch(upper)[1])
+	case mgrsPattern.MatchString(upper):
+		format = FormatMGRS
+		pos, reason = parseMGRS(mgrsPattern.FindStringSubmatch(upper))
+	case utmPattern.MatchString(upper):
+		format = FormatUTM
+		pos, reason = parseUTM(utmPattern.FindStringSubmatch(upper))
+	case geohashPattern.MatchString(upper) && strings.IndexFunc(upper, unicode.IsLetter) >= 0:
+		if strings.Trim(upper, "0123456789NSEW") == "" {
+			if degrees, degreesFormat, degreesReason := parseDegrees(upper); degreesReason == "" && validate(degrees) == "" {
+				pos, format = degrees, degreesFormat
+				break
+			}
+		}
+		format = FormatGeohash
+		pos, reason = parseGeohash(strings.ToLower(upper))
+	default:
+		pos, format, reason = parseDegrees(upper)
+	}
+
+	if reason != "" {
+		return Position{}, &ParseError{Format: format, Input: trimmed, Reason: reason}
+	}
+	if reason := validate(pos); reason != "" {
+		return Position{}, &ParseError{Format: format, Input: trimmed, Reason: reason}
+	}
+	pos.Format = format
+	return pos, nil
+}
+
+func validate(pos Position) string {
+	switch {
+	case math.IsNaN(pos.Lat) || math.IsInf(pos.Lat, 0) || pos.Lat < -90 || pos.Lat > 90:
+		return fmt.Sprintf("latitude %g must be between -90 and 90 degrees", pos.Lat)
+	case math.IsNaN(pos.Lon) || math.IsInf(pos.Lon, 0) || pos.Lon < -180 || pos.Lon > 180:
+		return fmt.Sprintf("longitude %g must be between -180 and 180 degrees", pos.Lon)
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package coord
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lat, lon  float64
+		format    Format
+		tolerance float64
+	}{
+		{"decimal with comma", "47.4925, 19.0403", 47.4925, 19.0403, FormatDecimal, 1e-9},
+		{"decimal with space", "-33.946 151.177", -33.946, 151.177, FormatDecimal, 1e-9},
+		{"decimal with plus signs", "+47.5, +19.0", 47.5, 19, FormatDecimal, 1e-9},
+		{"decimal with plus signs and space", "+47.5 +19.0", 47.5, 19, FormatDecimal, 1e-9},
+		{"decimal with hemispheres", "33.946S 151.177E", -33.946, 151.177, FormatDecimal, 1e-9},
+		{"decimal with leading hemispheres", "S33.946 W70.5", -33.946, -70.5, FormatDecimal, 1e-9},
+		{"DMS with symbols", `47°29'33"N 19°02'25"E`, 47.4925, 19.040278, FormatDMS, 1e-6},
+		{"DMS longitude first", `19°02'25"E 47°29'33"N`, 47.4925, 19.040278, FormatDMS, 1e-6},
+		{"DMS with unicode primes", "33°56′45″S 151°10′37″E", -33.945833, 151.176944, FormatDMS, 1e-6},
+		{"DMS with spaces", "47 29 33 N 19 02 25 E", 47.4925, 19.040278, FormatDMS, 1e-6},
+		{"DMS without hemispheres", "47 29 33 19 2 25", 47.4925, 19.040278, FormatDMS, 1e-6},
+		{"degrees and decimal minutes", "N47 29.55 E19 2.417", 47.4925, 19.040283, FormatDMS, 1e-6},
+		{"DMS negative", `-33°56'45" -70°30'0"`, -33.945833, -70.5, FormatDMS, 1e-6},
+		{"lower case DMS", `47°29'33"n 19°02'25"w`, 47.4925, -19.040278, FormatDMS, 1e-6},
+		{"UTM", "18S 323483 4306479", 38.8895, -77.0352, FormatUTM, 1e-3},
+		{"UTM with unit suffixes", "31U 448252mE 5411933mN", 48.8582, 2.2945, FormatUTM, 1e-3},
+		{"UTM southern hemisphere", "56H 334901 6252289", -33.8568, 151.2153, FormatUTM, 1e-3},
+		{"MGRS", "18SUJ2348306479", 38.8895, -77.0352, FormatMGRS, 1e-3},
+		{"MGRS with spaces", "18S UJ 23483 06479", 38.8895, -77.0352, FormatMGRS, 1e-3},
+		{"MGRS even zone", "31UDQ4825211933", 48.8582, 2.2945, FormatMGRS, 1e-3},
+		{"MGRS low precision", "4QFJ1256", 21.31, -157.92, FormatMGRS, 1e-2},
+		{"MGRS southern hemisphere", "56HLH3490052288", -33.8568, 151.2153, FormatMGRS, 1e-3},
+		{"geohash", "u4pruydqqvj", 57.64911, 10.40744, FormatGeohash, 1e-5},
+		{"geohash upper case", "EZS42", 42.605, -5.603, FormatGeohash, 1e-3},
+		{"hemispheres without spaces", "47N19E", 47, 19, FormatDecimal, 1e-9},
+		{"geohash of digits and hemisphere letters", "s00e", 0.615234, 0.878906, FormatGeohash, 1e-5},
+		{"plus code", "849VCWC8+R9", 37.4220, -122.0841, FormatPlusCode, 1e-3},
+		{"plus code with grid refinement", "8FVC9G8F+6XQ", 47.365590, 8.524912, FormatPlusCode, 1e-4},
+		{"padded plus code", "8FVC0000+", 47.5, 8.5, FormatPlusCode, 1e-9},
+		{"plus code of the usage example", "8FVXF2VR+24", 47.4925, 19.0403, FormatPlusCode, 1e-4},
+		{"lower case plus code", "8fvc9g8f+6x", 47.3656, 8.5249, FormatPlusCode, 1e-3},
+		{"plus code with locality", "8FVXF2VR+24 Budapest", 47.4925, 19.0403, FormatPlusCode, 1e-4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if pos.Format != tt.format {
+				t.Errorf("format = %v, want %v", pos.Format, tt.format)
+			}
+			if math.Abs(pos.Lat-tt.lat) > tt.tolerance || math.Abs(pos.Lon-tt.lon) > tt.tolerance {
+				t.Errorf("Parse(%q) = (%f, %f), want (%f, %f) ±%g", tt.input, pos.Lat, pos.Lon, tt.lat, tt.lon, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		format     Format
+		wantReason string
+	}{
+		{"empty", "   ", FormatUnknown, "must not be empty"},
+		{"single number", "47.5", FormatUnknown, "expected a latitude and a longitude"},
+		{"digits only", "12345", FormatUnknown, "expected a latitude and a longitude"},
+		{"odd number of numbers", "47 29 19", FormatUnknown, "expected a latitude and a longitude"},
+		{"unexpected character", "47.5 / 19.0", FormatUnknown, "unexpected character '/'"},
+		{"latitude out of range", "91, 19", FormatDecimal, "latitude 91 must be between -90 and 90"},
+		{"longitude out of range", "47, -181", FormatDecimal, "longitude -181 must be between -180 and 180"},
+		{"minutes too large", `47°61'N 19°E`, FormatDMS, "latitude minutes must be less than 60"},
+		{"seconds too large", `47°1'60"N 19°E`, FormatDMS, "latitude seconds must be less than 60"},
+		{"two latitudes", "47N 19N", FormatDecimal, "one of N/S and one of E/W"},
+		{"sign and hemisphere", "-47N 19E", FormatDecimal, "minus sign with a hemisphere letter"},
+		{"fractional degrees with minutes", `47.5°29'N 19°E`, FormatDMS, "only the last of degrees, minutes and seconds"},
+		{"minutes before degrees", `29'N 19°E`, FormatDMS, "unexpected minutes"},
+		{"UTM bad zone", "61T 353236 5262715", FormatUTM, "zone 61 must be between 1 and 60"},
+		{"UTM easting out of range", "34T 053236 5262715", FormatUTM, "easting 053236 must be between"},
+		{"UTM wrong band", "34C 353236 5262715", FormatUTM, "outside latitude band C"},
+		{"MGRS odd digits", "34TCT532366271", FormatMGRS, "even number of up to 10 digits"},
+		{"MGRS column not in zone", "34TJT5323662715", FormatMGRS, "column letter J is not used in zone 34"},
+		{"geohash too long", "u4pruydqqvjxyz", FormatGeohash, "at most 12 characters"},
+		{"short plus code", "9G8F+6X", FormatPlusCode, "short plus codes need a reference location"},
+		{"short plus code with locality", "9G8F+6X Zurich", FormatPlusCode, "short plus codes need a reference location"},
+		{"plus code bad digit", "8FVC9G8A+6X", FormatPlusCode, `character 'A' at position 8`},
+		{"plus code single refinement digit", "8FVC9G8F+6", FormatPlusCode, "single character after '+'"},
+		{"plus code bad padding", "8FVC0G00+", FormatPlusCode, "padding characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %+v, want error", tt.input, pos)
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %T", err)
+			}
+			if parseErr.Format != tt.format {
+				t.Errorf("format = %v, want %v", parseErr.Format, tt.format)
+			}
+			if !strings.Contains(err.Error(), tt.wantReason) {
+				t.Errorf("error %q does not contain %q", err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestParseGeohash_CellCentre(t *testing.T) {
+	pos, reason := parseGeohash("s")
+	if reason != "" {
+		t.Fatalf("unexpected error: %s", reason)
+	}
+	if pos.Lat != 22.5 || pos.Lon != 22.5 {
+		t.Errorf("got (%f, %f), want (22.5, 22.5)", pos.Lat, pos.Lon)
+	}
+}
+
+func TestUTMToLatLon_CentralMeridian(t *testing.T) {
+	pos := utmToLatLon(34, true, utmFalseE, 0)
+	if math.Abs(pos.Lat) > 1e-9 || math.Abs(pos.Lon-21) > 1e-9 {
+		t.Errorf("got (%f, %f), want (0, 21)", pos.Lat, pos.Lon)
+	}
+}
@@ -0,0 +1,228 @@
+package coord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const unrecognizedHint = "expected decimal degrees (47.4925, 19.0403), DMS (47°29'33\"N 19°02'25\"E), " +
+	"UTM (34T 353236 5262715), MGRS (34TCT5323662715), a geohash (u2mw1q8x) or a plus code (8FVXF2VR+24)"
+
+type dmsMarker int
+
+const (
+	markerNone dmsMarker = iota
+	markerDegrees
+	markerMinutes
+	markerSeconds
+)
+
+// angle is one latitude or longitude component of a degrees input.
+type angle struct {
+	parts      []float64
+	texts      []string
+	hemisphere byte
+}
+
+// parseDegrees parses a latitude/longitude pair written in decimal degrees or
+// degrees-minutes-seconds, optionally with N/S/E/W hemisphere letters before
+// or after each component.
+func parseDegrees(input string) (Position, Format, string) {
+	normalized := strings.NewReplacer(
+		"º", "°", "′", "'", "″", `"`, "''", `"`, ",", " ", ";", " ",
+	).Replace(input)
+
+	var (
+		angles      []angle
+		current     angle
+		marked      bool
+		hemispheres int
+	)
+	closeAngle := func() {
+		if len(current.parts) > 0 || current.hemisphere != 0 {
+			angles = append(angles, current)
+		}
+		current = angle{}
+	}
+
+	for i := 0; i < len(normalized); {
+		r := rune(normalized[i])
+		switch {
+		case r == ' ' || r == '\t':
+			i++
+		case r == 'N' || r == 'S' || r == 'E' || r == 'W':
+			hemispheres++
+			switch {
+			case len(current.parts) == 0 && current.hemisphere == 0:
+				current.hemisphere = byte(r)
+			case len(current.parts) > 0 && current.hemisphere == 0:
+				current.hemisphere = byte(r)
+				closeAngle()
+			default:
+				closeAngle()
+				current.hemisphere = byte(r)
+			}
+			i++
+		case r == '-' || r == '+' || r == '.' || unicode.IsDigit(r):
+			end := i + 1
+			for end < len(normalized) && (normalized[end] == '.' || unicode.IsDigit(rune(normalized[end]))) {
+				end++
+			}
+			text := normalized[i:end]
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return Position{}, FormatUnknown, fmt.Sprintf("%q is not a number; %s", text, unrecognizedHint)
+			}
+			i = end
+
+			marker := markerNone
+			rest := strings.TrimLeft(normalized[i:], " ")
+			switch {
+			case strings.HasPrefix(rest, "°"):
+				marker = markerDegrees
+				i = len(normalized) - len(rest) + len("°")
+			case strings.HasPrefix(rest, "'"):
+				marker = markerMinutes
+				i = len(normalized) - len(rest) + 1
+			case strings.HasPrefix(rest, `"`):
+				marker = markerSeconds
+				i = len(normalized) - len(rest) + 1
+			}
+			if marker != markerNone {
+				marked = true
+			}
+
+			slot := len(current.parts)
+			switch {
+			case marker == markerDegrees && slot > 0, marker == markerNone && slot == 3:
+				closeAngle()
+				slot = 0
+			}
+			if marker != markerNone && int(marker)-1 != slot {
+				return Position{}, FormatDMS, fmt.Sprintf("unexpected %s after %q", markerName(marker), strings.Join(current.texts, " "))
+			}
+			current.parts = append(current.parts, value)
+			current.texts = append(current.texts, text)
+		default:
+			r, _ := utf8.DecodeRuneInString(normalized[i:])
+			return Position{}, FormatUnknown, fmt.Sprintf("unexpected character %q; %s", r, unrecognizedHint)
+		}
+	}
+	closeAngle()
+
+	format := FormatDecimal
+	if marked {
+		format = FormatDMS
+	}
+
+	// Plain numbers without markers or hemisphere letters are split evenly
+	// between latitude and longitude.
+	if !marked && hemispheres == 0 {
+		var parts []float64
+		var texts []string
+		for _, a := range angles {
+			parts = append(parts, a.parts...)
+			texts = append(texts, a.texts...)
+		}
+		if len(parts) == 0 || len(parts)%2 != 0 || len(parts) > 6 {
+			return Position{}, FormatUnknown, "expected a latitude and a longitude; " + unrecognizedHint
+		}
+		half := len(parts) / 2
+		angles = []angle{
+			{parts: parts[:half], texts: texts[:half]},
+			{parts: parts[half:], texts: texts[half:]},
+		}
+	}
+	for _, a := range angles {
+		if len(a.parts) > 1 {
+			format = FormatDMS
+		}
+	}
+
+	if len(angles) != 2 {
+		return Position{}, format, "expected exactly one latitude and one longitude; " + unrecognizedHint
+	}
+
+	latAngle, lonAngle := angles[0], angles[1]
+	if isLongitudeHemisphere(latAngle.hemisphere) || isLatitudeHemisphere(lonAngle.hemisphere) {
+		latAngle, lonAngle = lonAngle, latAngle
+	}
+	if isLongitudeHemisphere(latAngle.hemisphere) || isLatitudeHemisphere(lonAngle.hemisphere) {
+		return Position{}, format, "hemisphere letters must give one of N/S and one of E/W"
+	}
+
+	lat, reason := latAngle.value("latitude")
+	if reason != "" {
+		return Position{}, format, reason
+	}
+	lon, reason := lonAngle.value("longitude")
+	if reason != "" {
+		return Position{}, format, reason
+	}
+	return Position{Lat: lat, Lon: lon}, format, ""
+}
+
+// value combines degrees, minutes and seconds into signed decimal degrees.
+func (a angle) value(name string) (float64, string) {
+	if len(a.parts) == 0 {
+		return 0, fmt.Sprintf("%s is missing its degrees", name)
+	}
+
+	negative := strings.HasPrefix(a.texts[0], "-")
+	for i, text := range a.texts {
+		if i > 0 && (strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+")) {
+			return 0, fmt.Sprintf("%s minutes and seconds must not carry a sign", name)
+		}
+		if i < len(a.texts)-1 && strings.Contains(text, ".") {
+			return 0, fmt.Sprintf("%s: only the last of degrees, minutes and seconds may have a fraction", name)
+		}
+	}
+	if negative && a.hemisphere != 0 {
+		return 0, fmt.Sprintf("%s must not combine a minus sign with a hemisphere letter", name)
+	}
+
+	degrees := a.parts[0]
+	if negative {
+		degrees = -degrees
+	}
+	total := degrees
+	if len(a.parts) > 1 {
+		if a.parts[1] >= 60 {
+			return 0, fmt.Sprintf("%s minutes must be less than 60, got %s", name, a.texts[1])
+		}
+		total += a.parts[1] / 60
+	}
+	if len(a.parts) > 2 {
+		if a.parts[2] >= 60 {
+			return 0, fmt.Sprintf("%s seconds must be less than 60, got %s", name, a.texts[2])
+		}
+		total += a.parts[2] / 3600
+	}
+
+	if negative || a.hemisphere == 'S' || a.hemisphere == 'W' {
+		total = -total
+	}
+	return total, ""
+}
+
+func isLatitudeHemisphere(h byte) bool {
+	return h == 'N' || h == 'S'
+}
+
+func isLongitudeHemisphere(h byte) bool {
+	return h == 'E' || h == 'W'
+}
+
+func markerName(m dmsMarker) string {
+	switch m {
+	case markerDegrees:
+		return "degrees"
+	case markerMinutes:
+		return "minutes"
+	default:
+		return "seconds"
+	}
+}
@@ -0,0 +1,64 @@
+package coord
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Notation selects how coordinates are written in output.
+type Notation int
+
+const (
+	NotationDecimal Notation = iota
+	NotationDMS
+)
+
+// ParseNotation parses an output notation name: decimal or dms.
+func ParseNotation(s string) (Notation, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "decimal":
+		return NotationDecimal, nil
+	case "dms":
+		return NotationDMS, nil
+	default:
+		return 0, fmt.Errorf("invalid coordinate notation %q: must be decimal or dms", s)
+	}
+}
+
+// FormatLatitude writes lat in the given notation, e.g. "47.492500" or
+// `47°29'33.0"N`.
+func FormatLatitude(lat float64, n Notation) string {
+	if n == NotationDMS {
+		return formatDMS(lat, 'N', 'S')
+	}
+	return fmt.Sprintf("%.6f", lat)
+}
+
+// FormatLongitude writes lon in the given notation, e.g. "19.040300" or
+// `19°02'25.1"E`.
+func FormatLongitude(lon float64, n Notation) string {
+	if n == NotationDMS {
+		return formatDMS(lon, 'E', 'W')
+	}
+	return fmt.Sprintf("%.6f", lon)
+}
+
+// formatDMS writes value as degrees, minutes and seconds to a tenth of a
+// second, which is about as precise as the six decimals of NotationDecimal.
+// The hemisphere is chosen after rounding, so values that round to zero are
+// written as N or E.
+func formatDMS(value float64, positive, negative byte) string {
+	tenths := math.Round(math.Abs(value) * 36000)
+	hemisphere := positive
+	if value < 0 && tenths > 0 {
+		hemisphere = negative
+	}
+
+	degrees := math.Floor(tenths / 36000)
+	tenths -= degrees * 36000
+	minutes := math.Floor(tenths / 600)
+	seconds := (tenths - minutes*600) / 10
+
+	return fmt.Sprintf(`%d°%02d'%04.1f"%c`, int(degrees), int(minutes), seconds, hemisphere)
+}
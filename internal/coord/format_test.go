@@ -0,0 +1,63 @@
+package coord
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseNotation(t *testing.T) {
+	if n, err := ParseNotation("DMS"); err != nil || n != NotationDMS {
+		t.Errorf("ParseNotation(DMS) = %v, %v", n, err)
+	}
+	if n, err := ParseNotation("decimal"); err != nil || n != NotationDecimal {
+		t.Errorf("ParseNotation(decimal) = %v, %v", n, err)
+	}
+	if _, err := ParseNotation("utm"); err == nil {
+		t.Error("expected error for unsupported notation")
+	}
+}
+
+func TestFormatCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		notation Notation
+		wantLat  string
+		wantLon  string
+	}{
+		{"decimal", 47.4925, 19.0403, NotationDecimal, "47.492500", "19.040300"},
+		{"decimal negative", -33.946, -70.5, NotationDecimal, "-33.946000", "-70.500000"},
+		{"dms", 47.4925, 19.040278, NotationDMS, `47°29'33.0"N`, `19°02'25.0"E`},
+		{"dms southern and western", -33.945833, -151.176944, NotationDMS, `33°56'45.0"S`, `151°10'37.0"W`},
+		{"dms rounding up to next minute", 10.999999, 0, NotationDMS, `11°00'00.0"N`, `0°00'00.0"E`},
+		{"dms poles and antimeridian", -90, 180, NotationDMS, `90°00'00.0"S`, `180°00'00.0"E`},
+		{"dms negative rounding to zero", -0.000001, -0.000001, NotationDMS, `0°00'00.0"N`, `0°00'00.0"E`},
+		{"dms negative zero", math.Copysign(0, -1), math.Copysign(0, -1), NotationDMS, `0°00'00.0"N`, `0°00'00.0"E`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatLatitude(tt.lat, tt.notation); got != tt.wantLat {
+				t.Errorf("FormatLatitude(%g) = %q, want %q", tt.lat, got, tt.wantLat)
+			}
+			if got := FormatLongitude(tt.lon, tt.notation); got != tt.wantLon {
+				t.Errorf("FormatLongitude(%g) = %q, want %q", tt.lon, got, tt.wantLon)
+			}
+		})
+	}
+}
+
+func TestFormatDMS_RoundTrip(t *testing.T) {
+	input := FormatLatitude(-33.9461, NotationDMS) + " " + FormatLongitude(151.1772, NotationDMS)
+	pos, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned error: %v", input, err)
+	}
+	if !floatClose(pos.Lat, -33.9461, 1e-4) || !floatClose(pos.Lon, 151.1772, 1e-4) {
+		t.Errorf("round trip of %q gave (%f, %f)", input, pos.Lat, pos.Lon)
+	}
+}
+
+func floatClose(a, b, tolerance float64) bool {
+	return a-b < tolerance && b-a < tolerance
+}
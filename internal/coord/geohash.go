@@ -0,0 +1,50 @@
+package coord
+
+import (
+	"fmt"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// maxGeohashLength bounds the precision to what a float64 can represent.
+const maxGeohashLength = 12
+
+// parseGeohash decodes a lower-case geohash into the centre of its cell.
+func parseGeohash(hash string) (Position, string) {
+	if len(hash) > maxGeohashLength {
+		return Position{}, fmt.Sprintf("must be at most %d characters long, got %d", maxGeohashLength, len(hash))
+	}
+
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	even := true
+
+	for _, c := range hash {
+		index := strings.IndexRune(geohashAlphabet, c)
+		if index < 0 {
+			return Position{}, fmt.Sprintf("character %q is not in the geohash alphabet %s", c, geohashAlphabet)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := index&(1<<bit) != 0
+			if even {
+				mid := (minLon + maxLon) / 2
+				if set {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+
+	return Position{Lat: (minLat + maxLat) / 2, Lon: (minLon + maxLon) / 2}, ""
+}
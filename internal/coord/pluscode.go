@@ -0,0 +1,83 @@
+package coord
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Open Location Code constants, see
+// https://github.com/google/open-location-code/blob/main/Documentation/Specification/specification.md
+const (
+	olcAlphabet     = "23456789CFGHJMPQRVWX"
+	olcSeparatorPos = 8
+	olcPairLength   = 10
+	olcMaxLength    = 15
+	olcGridColumns  = 4
+	olcGridRows     = 5
+)
+
+// parsePlusCode decodes a full upper-case plus code into the centre of its
+// area. Short codes need a reference location and are rejected.
+func parsePlusCode(code string) (Position, string) {
+	separator := strings.IndexByte(code, '+')
+	if separator != strings.LastIndexByte(code, '+') {
+		return Position{}, "must contain exactly one '+'"
+	}
+	if separator < olcSeparatorPos {
+		return Position{}, "short plus codes need a reference location; use the full code, e.g. 8FVXF2VR+24"
+	}
+	if separator > olcSeparatorPos || separator%2 != 0 {
+		return Position{}, fmt.Sprintf("'+' must follow the first %d characters", olcSeparatorPos)
+	}
+
+	digits := code[:separator] + code[separator+1:]
+	if padding := strings.IndexByte(digits, '0'); padding >= 0 {
+		if strings.Trim(digits[padding:], "0") != "" || padding%2 != 0 || separator+1 < len(code) {
+			return Position{}, "padding characters '0' may only end the code before '+'"
+		}
+		digits = digits[:padding]
+	}
+	if len(digits) == 0 {
+		return Position{}, "code has no digits"
+	}
+	if len(digits) == olcSeparatorPos+1 {
+		return Position{}, "a single character after '+' is not valid"
+	}
+	if len(digits) > olcMaxLength {
+		digits = digits[:olcMaxLength]
+	}
+
+	for i, c := range digits {
+		if strings.IndexRune(olcAlphabet, c) < 0 {
+			return Position{}, fmt.Sprintf("character %q at position %d is not a plus code digit", c, i+1)
+		}
+	}
+	if strings.IndexByte(olcAlphabet, digits[0]) > 8 {
+		return Position{}, "the first character encodes a latitude above 90 degrees"
+	}
+	if len(digits) > 1 && strings.IndexByte(olcAlphabet, digits[1]) > 17 {
+		return Position{}, "the second character encodes a longitude above 180 degrees"
+	}
+
+	lat, lon := -90.0, -180.0
+	resolution := 20.0
+	i := 0
+	for ; i < len(digits) && i < olcPairLength; i += 2 {
+		lat += float64(strings.IndexByte(olcAlphabet, digits[i])) * resolution
+		if i+1 < len(digits) {
+			lon += float64(strings.IndexByte(olcAlphabet, digits[i+1])) * resolution
+		}
+		resolution /= 20
+	}
+	latSize, lonSize := resolution*20, resolution*20
+
+	for ; i < len(digits); i++ {
+		index := strings.IndexByte(olcAlphabet, digits[i])
+		latSize /= olcGridRows
+		lonSize /= olcGridColumns
+		lat += float64(index/olcGridColumns) * latSize
+		lon += float64(index%olcGridColumns) * lonSize
+	}
+
+	return Position{Lat: lat + latSize/2, Lon: lon + lonSize/2}, ""
+}
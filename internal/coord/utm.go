@@ -0,0 +1,189 @@
+package coord
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WGS84 ellipsoid and UTM projection constants.
+const (
+	wgs84A      = 6378137.0
+	wgs84F      = 1 / 298.257223563
+	utmK0       = 0.9996
+	utmFalseE   = 500000.0
+	utmFalseN   = 10000000.0
+	mgrsSquare  = 100000.0
+	mgrsRowSpan = 2000000.0
+)
+
+const (
+	latitudeBands  = "CDEFGHJKLMNPQRSTUVWX"
+	mgrsRowLetters = "ABCDEFGHJKLMNPQRSTUV"
+)
+
+// mgrsColumnLetters holds the 100 km column letters of the three column sets,
+// which repeat every three zones.
+var mgrsColumnLetters = [3]string{"ABCDEFGH", "JKLMNPQR", "STUVWXYZ"}
+
+// bandMinNorthing is the lowest northing, rounded down to 100 km, reached
+// anywhere inside each latitude band. It resolves the 2000 km ambiguity of
+// MGRS row letters.
+var bandMinNorthing = map[byte]float64{
+	'C': 1100000, 'D': 2000000, 'E': 2800000, 'F': 3700000, 'G': 4600000,
+	'H': 5500000, 'J': 6400000, 'K': 7300000, 'L': 8200000, 'M': 9100000,
+	'N': 0, 'P': 800000, 'Q': 1700000, 'R': 2600000, 'S': 3500000,
+	'T': 4400000, 'U': 5300000, 'V': 6200000, 'W': 7000000, 'X': 7900000,
+}
+
+// parseUTM converts a match of utmPattern ("34T 353236 5262715").
+func parseUTM(m []string) (Position, string) {
+	zone, reason := parseZone(m[1])
+	if reason != "" {
+		return Position{}, reason
+	}
+	band := m[2][0]
+
+	easting, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return Position{}, fmt.Sprintf("easting %q is not a number", m[3])
+	}
+	northing, err := strconv.ParseFloat(m[4], 64)
+	if err != nil {
+		return Position{}, fmt.Sprintf("northing %q is not a number", m[4])
+	}
+	if easting < 100000 || easting > 900000 {
+		return Position{}, fmt.Sprintf("easting %s must be between 100000 and 900000 metres", m[3])
+	}
+	if northing < 0 || northing > utmFalseN {
+		return Position{}, fmt.Sprintf("northing %s must be between 0 and 10000000 metres", m[4])
+	}
+
+	pos := utmToLatLon(zone, band >= 'N', easting, northing)
+	if reason := checkBand(pos, band); reason != "" {
+		return Position{}, reason
+	}
+	return pos, ""
+}
+
+// parseMGRS converts a match of mgrsPattern ("34TCT5323662715").
+func parseMGRS(m []string) (Position, string) {
+	zone, reason := parseZone(m[1])
+	if reason != "" {
+		return Position{}, reason
+	}
+	band := m[2][0]
+	column, row := m[3][0], m[4][0]
+
+	digits := m[5] + m[6]
+	if len(digits)%2 != 0 || len(digits) > 10 {
+		return Position{}, fmt.Sprintf("expected an even number of up to 10 digits after the square letters, got %d", len(digits))
+	}
+	precision := len(digits) / 2
+	var eastingInSquare, northingInSquare float64
+	if precision > 0 {
+		e, _ := strconv.Atoi(digits[:precision])
+		n, _ := strconv.Atoi(digits[precision:])
+		scale := math.Pow(10, float64(5-precision))
+		// Use the centre of the cell the reference describes.
+		eastingInSquare = (float64(e) + 0.5) * scale
+		northingInSquare = (float64(n) + 0.5) * scale
+	} else {
+		eastingInSquare, northingInSquare = mgrsSquare/2, mgrsSquare/2
+	}
+
+	set := (zone - 1) % 3
+	columnIndex := strings.IndexByte(mgrsColumnLetters[set], column)
+	if columnIndex < 0 {
+		return Position{}, fmt.Sprintf("column letter %c is not used in zone %d; expected one of %s", column, zone, mgrsColumnLetters[set])
+	}
+	rowIndex := strings.IndexByte(mgrsRowLetters, row)
+	if zone%2 == 0 {
+		rowIndex = (rowIndex - 5 + len(mgrsRowLetters)) % len(mgrsRowLetters)
+	}
+
+	easting := float64(columnIndex+1)*mgrsSquare + eastingInSquare
+	northing := float64(rowIndex)*mgrsSquare + northingInSquare
+	for northing < bandMinNorthing[band] {
+		northing += mgrsRowSpan
+	}
+
+	pos := utmToLatLon(zone, band >= 'N', easting, northing)
+	if reason := checkBand(pos, band); reason != "" {
+		return Position{}, reason
+	}
+	return pos, ""
+}
+
+func parseZone(s string) (int, string) {
+	zone, err := strconv.Atoi(s)
+	if err != nil || zone < 1 || zone > 60 {
+		return 0, fmt.Sprintf("zone %s must be between 1 and 60", s)
+	}
+	return zone, ""
+}
+
+// checkBand verifies that pos lies in the latitude band the input named. A
+// small tolerance accepts points on the band edges.
+func checkBand(pos Position, band byte) string {
+	index := strings.IndexByte(latitudeBands, band)
+	south := -80 + 8*float64(index)
+	north := south + 8
+	if band == 'X' {
+		north = 84
+	}
+	const tolerance = 0.01
+	if pos.Lat < south-tolerance || pos.Lat > north+tolerance {
+		return fmt.Sprintf("the position (%.5f, %.5f) lies outside latitude band %c (%g to %g degrees)", pos.Lat, pos.Lon, band, south, north)
+	}
+	return ""
+}
+
+// utmToLatLon applies the inverse transverse Mercator projection on the WGS84
+// ellipsoid (Snyder, "Map Projections: A Working Manual", pp. 63-64).
+func utmToLatLon(zone int, northern bool, easting, northing float64) Position {
+	e2 := wgs84F * (2 - wgs84F)
+	ep2 := e2 / (1 - e2)
+	e4, e6 := e2*e2, e2*e2*e2
+
+	x := easting - utmFalseE
+	y := northing
+	if !northern {
+		y -= utmFalseN
+	}
+
+	m := y / utmK0
+	mu := m / (wgs84A * (1 - e2/4 - 3*e4/64 - 5*e6/256))
+
+	sqrt1e2 := math.Sqrt(1 - e2)
+	e1 := (1 - sqrt1e2) / (1 + sqrt1e2)
+	phi1 := mu +
+		(3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sinPhi1, cosPhi1, tanPhi1 := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+	n1 := wgs84A / math.Sqrt(1-e2*sinPhi1*sinPhi1)
+	t1 := tanPhi1 * tanPhi1
+	c1 := ep2 * cosPhi1 * cosPhi1
+	r1 := wgs84A * (1 - e2) / math.Pow(1-e2*sinPhi1*sinPhi1, 1.5)
+	d := x / (n1 * utmK0)
+
+	lat := phi1 - (n1*tanPhi1/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+	lon := (d - (1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120) / cosPhi1
+
+	centralMeridian := float64(zone-1)*6 - 180 + 3
+	lonDeg := centralMeridian + lon*180/math.Pi
+	if lonDeg > 180 {
+		lonDeg -= 360
+	} else if lonDeg < -180 {
+		lonDeg += 360
+	}
+
+	return Position{Lat: lat * 180 / math.Pi, Lon: lonDeg}
+}
@@ -0,0 +1,262 @@
+// Package dedupe finds hubs that are likely the same place recorded twice,
+// for example after merging data sets that spell names or round
+// coordinates differently.
+package dedupe
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/names"
+)
+
+const (
+	defaultRadiusKm       = 2
+	defaultNameSimilarity = 0.8
+)
+
+type Config struct {
+	// RadiusKm is the distance within which similarly named hubs are
+	// candidates. Defaults to 2.
+	RadiusKm float64
+	// NameSimilarity is the minimum names.Similarity of two nearby hubs
+	// for them to be candidates. Defaults to 0.8.
+	NameSimilarity float64
+}
+
+// Reason is why two hubs were considered duplicates.
+type Reason string
+
+const (
+	// ReasonSameID marks hubs with the same ID.
+	ReasonSameID Reason = "same_id"
+	// ReasonSameCode marks hubs sharing an airport code, taken from an ID
+	// or a parenthesized part of the name such as "Budapest (BUD)".
+	// Codes are compared case-insensitively, however far apart the hubs
+	// are.
+	ReasonSameCode Reason = "same_code"
+	// ReasonNearbyName marks hubs within Config.RadiusKm of each other
+	// whose names are similar.
+	ReasonNearbyName Reason = "nearby_name"
+)
+
+// Candidate is a pair of hubs that may be duplicates. A precedes B in the
+// input.
+type Candidate struct {
+	A              model.Hub `json:"a"`
+	B              model.Hub `json:"b"`
+	DistanceKm     float64   `json:"distance_km"`
+	NameSimilarity float64   `json:"name_similarity"`
+	Reasons        []Reason  `json:"reasons"`
+}
+
+// Group is a set of hubs connected by candidate pairs.
+type Group struct {
+	// Hubs lists the members in input order. The first is the one kept by
+	// Report.Merge.
+	Hubs       []model.Hub `json:"hubs"`
+	Candidates []Candidate `json:"candidates"`
+
+	// members holds the positions of Hubs in the input of Find.
+	members []int
+}
+
+// Report is the outcome of Find.
+type Report struct {
+	// Hubs is the number of hubs examined.
+	Hubs   int     `json:"hubs"`
+	Groups []Group `json:"groups"`
+}
+
+// Duplicates is the number of hubs Merge drops.
+func (r Report) Duplicates() int {
+	n := 0
+	for _, group := range r.Groups {
+		n += len(group.Hubs) - 1
+	}
+	return n
+}
+
+// Find compares hubs and groups the likely duplicates. Groups are ordered
+// by their first member in hubs.
+func Find(hubs []model.Hub, cfg Config) (Report, error) {
+	if cfg.RadiusKm < 0 {
+		return Report{}, geo.ErrNegativeRadius
+	}
+	if cfg.NameSimilarity > 1 {
+		return Report{}, fmt.Errorf("name similarity %g is above 1", cfg.NameSimilarity)
+	}
+	if cfg.RadiusKm == 0 {
+		cfg.RadiusKm = defaultRadiusKm
+	}
+	if cfg.NameSimilarity <= 0 {
+		cfg.NameSimilarity = defaultNameSimilarity
+	}
+	for _, hub := range hubs {
+		if err := geo.ValidateCoordinates(hub.Lat, hub.Lon); err != nil {
+			return Report{}, fmt.Errorf("hub %s: %w", hub.ID, err)
+		}
+	}
+
+	reasons := make(map[[2]int][]Reason)
+	flag := func(i, j int, reason Reason) {
+		pair := [2]int{min(i, j), max(i, j)}
+		if !slices.Contains(reasons[pair], reason) {
+			reasons[pair] = append(reasons[pair], reason)
+		}
+	}
+	// sameKey flags every pair of hubs sharing a key. Hubs with the same
+	// ID are only flagged for that.
+	sameKey := func(keys func(model.Hub) []string, reason Reason) {
+		seen := make(map[string][]int)
+		for i, hub := range hubs {
+			for _, key := range keys(hub) {
+				for _, j := range seen[key] {
+					if reason == ReasonSameID || hubs[j].ID != hub.ID {
+						flag(j, i, reason)
+					}
+				}
+				seen[key] = append(seen[key], i)
+			}
+		}
+	}
+	sameKey(func(hub model.Hub) []string { return []string{hub.ID} }, ReasonSameID)
+	sameKey(codes, ReasonSameCode)
+
+	// Sweep the hubs in latitude order, only comparing those whose
+	// latitudes are close enough for them to be within the radius.
+	window := cfg.RadiusKm / (geo.EarthRadiusKm * math.Pi / 180)
+	byLat := make([]int, len(hubs))
+	for i := range byLat {
+		byLat[i] = i
+	}
+	sort.SliceStable(byLat, func(a, b int) bool { return hubs[byLat[a]].Lat < hubs[byLat[b]].Lat })
+	for a, i := range byLat {
+		for _, j := range byLat[a+1:] {
+			if hubs[j].Lat-hubs[i].Lat > window {
+				break
+			}
+			distanceKm, _ := geo.HaversineDistance(hubs[i].Lat, hubs[i].Lon, hubs[j].Lat, hubs[j].Lon)
+			if distanceKm <= cfg.RadiusKm && names.Similarity(hubs[i].Name, hubs[j].Name) >= cfg.NameSimilarity {
+				flag(i, j, ReasonNearbyName)
+			}
+		}
+	}
+
+	return buildReport(hubs, reasons), nil
+}
+
+// buildReport groups the flagged pairs into connected components.
+func buildReport(hubs []model.Hub, reasons map[[2]int][]Reason) Report {
+	parent := make([]int, len(hubs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	pairs := make([][2]int, 0, len(reasons))
+	for pair := range reasons {
+		pairs = append(pairs, pair)
+		// The smaller index becomes the root, so that every group is
+		// rooted at its first member.
+		a, b := root(pair[0]), root(pair[1])
+		parent[max(a, b)] = min(a, b)
+	}
+	slices.SortFunc(pairs, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	report := Report{Hubs: len(hubs)}
+	groupOf := make(map[int]int)
+	for _, pair := range pairs {
+		for _, i := range pair {
+			r := root(i)
+			g, ok := groupOf[r]
+			if !ok {
+				g = len(report.Groups)
+				groupOf[r] = g
+				report.Groups = append(report.Groups, Group{})
+			}
+			if !slices.Contains(report.Groups[g].members, i) {
+				report.Groups[g].members = append(report.Groups[g].members, i)
+			}
+		}
+	}
+	for g := range report.Groups {
+		group := &report.Groups[g]
+		slices.Sort(group.members)
+		for _, i := range group.members {
+			group.Hubs = append(group.Hubs, hubs[i])
+		}
+	}
+	slices.SortFunc(report.Groups, func(a, b Group) int { return cmp.Compare(a.members[0], b.members[0]) })
+	for g, group := range report.Groups {
+		groupOf[group.members[0]] = g
+	}
+
+	for _, pair := range pairs {
+		a, b := hubs[pair[0]], hubs[pair[1]]
+		distanceKm, _ := geo.HaversineDistance(a.Lat, a.Lon, b.Lat, b.Lon)
+		group := &report.Groups[groupOf[root(pair[0])]]
+		group.Candidates = append(group.Candidates, Candidate{
+			A:              a,
+			B:              b,
+			DistanceKm:     distanceKm,
+			NameSimilarity: names.Similarity(a.Name, b.Name),
+			Reasons:        reasons[pair],
+		})
+	}
+	return report
+}
+
+// Merge returns the hubs given to Find without the duplicates, keeping the
+// first member of each group.
+func (r Report) Merge(hubs []model.Hub) []model.Hub {
+	drop := make(map[int]bool)
+	for _, group := range r.Groups {
+		for _, i := range group.members[1:] {
+			drop[i] = true
+		}
+	}
+	merged := make([]model.Hub, 0, len(hubs)-len(drop))
+	for i, hub := range hubs {
+		if !drop[i] {
+			merged = append(merged, hub)
+		}
+	}
+	return merged
+}
+
+// Airport codes: IATA codes have three letters and ICAO codes four. Codes
+// in names must be upper case, so that words like "(new)" do not count.
+var (
+	idCodePattern   = regexp.MustCompile(`^[A-Za-z]{3,4}$`)
+	nameCodePattern = regexp.MustCompile(`\(\s*([A-Z]{3,4})\s*\)`)
+)
+
+// codes returns the upper-cased airport codes of hub: its ID if it looks
+// like a code, and any code in parentheses in its name.
+func codes(hub model.Hub) []string {
+	var found []string
+	if idCodePattern.MatchString(hub.ID) {
+		found = append(found, strings.ToUpper(hub.ID))
+	}
+	for _, match := range nameCodePattern.FindAllStringSubmatch(hub.Name, -1) {
+		if !slices.Contains(found, match[1]) {
+			found = append(found, match[1])
+		}
+	}
+	return found
+}
@@ -0,0 +1,135 @@
+package dedupe
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+func groupIDs(report Report) [][]string {
+	groups := make([][]string, len(report.Groups))
+	for g, group := range report.Groups {
+		for _, hub := range group.Hubs {
+			groups[g] = append(groups[g], hub.ID)
+		}
+	}
+	return groups
+}
+
+func TestFind(t *testing.T) {
+	hubs := []model.Hub{
+		{ID: "bud", Name: "Budapest Ferenc Liszt International", Lat: 47.4369, Lon: 19.2556},
+		{ID: "zrh", Name: "Zürich Airport", Lat: 47.4647, Lon: 8.5492},
+		{ID: "hub-1", Name: "Zurich Airport", Lat: 47.4581, Lon: 8.5555},
+		{ID: "hub-2", Name: "Liszt Ferenc Budapest International (BUD)", Lat: 47.43, Lon: 19.26},
+		{ID: "zrh-hb", Name: "Zürich HB", Lat: 47.3779, Lon: 8.5403},
+		{ID: "vie", Name: "Vienna International", Lat: 48.1103, Lon: 16.5697},
+		{ID: "vie", Name: "Wien-Schwechat", Lat: 48.1103, Lon: 16.5697},
+		{ID: "LOWW", Name: "Vienna International Airport", Lat: 48.2, Lon: 16.5697},
+	}
+
+	report, err := Find(hubs, Config{})
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	want := [][]string{{"bud", "hub-2"}, {"zrh", "hub-1"}, {"vie", "vie"}}
+	if got := groupIDs(report); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got groups %v, want %v", got, want)
+	}
+	if got := report.Duplicates(); got != 3 {
+		t.Errorf("got %d duplicates, want 3", got)
+	}
+
+	reasons := map[string][]Reason{}
+	for _, group := range report.Groups {
+		for _, candidate := range group.Candidates {
+			reasons[candidate.A.ID+"/"+candidate.B.ID] = candidate.Reasons
+		}
+	}
+	wantReasons := map[string][]Reason{
+		"bud/hub-2": {ReasonSameCode, ReasonNearbyName},
+		"zrh/hub-1": {ReasonNearbyName},
+		"vie/vie":   {ReasonSameID},
+	}
+	for pair, want := range wantReasons {
+		if got := reasons[pair]; !slices.Equal(got, want) {
+			t.Errorf("reasons for %s: got %v, want %v", pair, got, want)
+		}
+	}
+	if len(reasons) != len(wantReasons) {
+		t.Errorf("got candidates %v, want %v", reasons, wantReasons)
+	}
+
+	merged := report.Merge(hubs)
+	var ids []string
+	for _, hub := range merged {
+		ids = append(ids, hub.ID+":"+hub.Name)
+	}
+	wantMerged := []string{
+		"bud:Budapest Ferenc Liszt International",
+		"zrh:Zürich Airport",
+		"zrh-hb:Zürich HB",
+		"vie:Vienna International",
+		"LOWW:Vienna International Airport",
+	}
+	if !slices.Equal(ids, wantMerged) {
+		t.Errorf("got merged %v, want %v", ids, wantMerged)
+	}
+}
+
+func TestFind_TransitiveGroups(t *testing.T) {
+	// a and c are too far apart, but both are duplicates of b.
+	hubs := []model.Hub{
+		{ID: "c", Name: "Harbour", Lat: 10.03, Lon: 0},
+		{ID: "x", Name: "Elsewhere", Lat: 40, Lon: 40},
+		{ID: "b", Name: "Harbour", Lat: 10.015, Lon: 0},
+		{ID: "a", Name: "Harbour", Lat: 10, Lon: 0},
+	}
+	report, err := Find(hubs, Config{RadiusKm: 2})
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	want := [][]string{{"c", "b", "a"}}
+	if got := groupIDs(report); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got groups %v, want %v", got, want)
+	}
+	if got := len(report.Groups[0].Candidates); got != 2 {
+		t.Errorf("got %d candidates, want 2", got)
+	}
+}
+
+func TestFind_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		hubs []model.Hub
+		cfg  Config
+	}{
+		{name: "negative radius", cfg: Config{RadiusKm: -1}},
+		{name: "similarity above 1", cfg: Config{NameSimilarity: 1.5}},
+		{name: "invalid coordinates", hubs: []model.Hub{{ID: "bad", Lat: 91}}},
+	}
+	for _, tt := range tests {
+		if _, err := Find(tt.hubs, tt.cfg); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
+
+func TestCodes(t *testing.T) {
+	tests := []struct {
+		hub  model.Hub
+		want []string
+	}{
+		{model.Hub{ID: "bud", Name: "Budapest"}, []string{"BUD"}},
+		{model.Hub{ID: "hub-1", Name: "Budapest (BUD)"}, []string{"BUD"}},
+		{model.Hub{ID: "LHBP", Name: "Budapest ( BUD ) (LHBP)"}, []string{"LHBP", "BUD"}},
+		{model.Hub{ID: "airport-1", Name: "Budapest (new)"}, nil},
+		{model.Hub{ID: "a1", Name: "Terminal (T2)"}, nil},
+	}
+	for _, tt := range tests {
+		if got := codes(tt.hub); !slices.Equal(got, tt.want) {
+			t.Errorf("codes(%v) = %v, want %v", tt.hub, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package finder
+
+import (
+	"errors"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+)
+
+// Validation errors for search parameters.
+var (
+	ErrNegativeLimit  = errors.New("limit cannot be negative")
+	ErrNegativeOffset = errors.New("offset cannot be negative")
+	ErrInvalidCount   = errors.New("count must be positive")
+	ErrEmptyText      = errors.New("search text must contain a word")
+	ErrInvalidSort    = errors.New("invalid sort")
+	ErrInvalidBins    = errors.New("histogram bins must be between 1 and 1000")
+)
+
+// Errors returned when results are to be sorted by a key the Finder has no
+// estimator for.
+var (
+	ErrNoTravelEstimator = errors.New("sorting by travel time requires a travel estimator")
+	ErrNoScorer          = errors.New("sorting by score requires a scorer")
+)
+
+// invalidArgumentErrors lists the errors caused by the caller's input rather
+// than by the repository.
+var invalidArgumentErrors = []error{
+	geo.ErrInvalidLatitude,
+	geo.ErrInvalidLongitude,
+	geo.ErrNegativeRadius,
+	geo.ErrInvalidPolygon,
+	ErrNegativeLimit,
+	ErrNegativeOffset,
+	ErrInvalidCount,
+	ErrEmptyText,
+	ErrInvalidSort,
+	ErrInvalidBins,
+	ErrNoTravelEstimator,
+	ErrNoScorer,
+}
+
+// IsInvalidArgument reports whether err was caused by invalid search input,
+// as opposed to a failure of the repository.
+func IsInvalidArgument(err error) bool {
+	for _, target := range invalidArgumentErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
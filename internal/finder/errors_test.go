@@ -0,0 +1,60 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+func TestSearchErrors(t *testing.T) {
+	backendErr := &repository.BackendError{Op: "post search", StatusCode: 503, Retryable: true, Err: errors.New("unavailable")}
+
+	tests := []struct {
+		name        string
+		repo        repository.Repository
+		query       Query
+		expected    error
+		invalidArgs bool
+	}{
+		{"latitude out of range", &mockRepository{}, Query{Lat: 91, RadiusKm: 10}, geo.ErrInvalidLatitude, true},
+		{"longitude out of range", &mockRepository{}, Query{Lon: -181, RadiusKm: 10}, geo.ErrInvalidLongitude, true},
+		{"negative radius", &mockRepository{}, Query{RadiusKm: -1}, geo.ErrNegativeRadius, true},
+		{"negative radius with limit", &mockRepository{}, Query{RadiusKm: -1, Limit: 5}, geo.ErrNegativeRadius, true},
+		{"negative limit", &mockRepository{}, Query{RadiusKm: 10, Limit: -1}, ErrNegativeLimit, true},
+		{"unknown sort", &mockRepository{}, Query{RadiusKm: 10, SortBy: "size"}, ErrInvalidSort, true},
+		{"unknown order", &mockRepository{}, Query{RadiusKm: 10, Order: "up"}, ErrInvalidSort, true},
+		{"travel time without estimator", &mockRepository{}, Query{RadiusKm: 10, SortBy: SortTravelTime}, ErrNoTravelEstimator, true},
+		{"score without scorer", &mockRepository{}, Query{RadiusKm: 10, SortBy: SortScore}, ErrNoScorer, true},
+		{"backend failure", &mockRepository{returnErr: backendErr}, Query{RadiusKm: 10}, backendErr, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.repo).Search(context.Background(), tt.query)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("got error %v, want %v", err, tt.expected)
+			}
+			if got := IsInvalidArgument(err); got != tt.invalidArgs {
+				t.Errorf("IsInvalidArgument(%v) = %v, want %v", err, got, tt.invalidArgs)
+			}
+		})
+	}
+}
+
+func TestSearchReportsHubsWithInvalidCoordinates(t *testing.T) {
+	repo := &mockRepository{hubs: []model.Hub{{ID: "bad", Name: "Bad", Lat: 0, Lon: 200}}}
+	// The wrapped longitude check of the mock lets the out-of-range hub through.
+	_, err := New(repo).Search(context.Background(), Query{Lat: 0, Lon: 179, RadiusKm: 500})
+
+	var rowErr *repository.MalformedRowError
+	if !errors.As(err, &rowErr) || rowErr.ID != "bad" {
+		t.Fatalf("expected *MalformedRowError for hub bad, got %v", err)
+	}
+	if IsInvalidArgument(err) {
+		t.Error("expected a bad hub not to be reported as invalid input")
+	}
+}
@@ -3,39 +3,317 @@ package finder
 import (
 	"context"
 	"fmt"
-	"sort"
+	"log/slog"
+	"math"
+	"time"
 
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Finder struct {
-	repo repository.Repository
+	repo           repository.Repository
+	logger         *slog.Logger
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+	metrics        *telemetry.Metrics
+	travel         TravelEstimator
+	scorer         Scorer
 }
 
-func New(repo repository.Repository) *Finder {
-	return &Finder{repo: repo}
+// Option configures optional Finder behaviour.
+type Option func(*Finder)
+
+// WithLogger sets the logger used for search traces. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(f *Finder) {
+		f.logger = logger
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider used for search
+// spans. Defaults to the global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(f *Finder) {
+		f.tracerProvider = tp
+	}
+}
+
+// WithMetrics sets the Prometheus metrics that searches are recorded in.
+func WithMetrics(m *telemetry.Metrics) Option {
+	return func(f *Finder) {
+		f.metrics = m
+	}
+}
+
+func New(repo repository.Repository, opts ...Option) *Finder {
+	f := &Finder{repo: repo}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.logger == nil {
+		f.logger = slog.Default()
+	}
+	f.logger = f.logger.With("component", "finder")
+	f.tracer = telemetry.Tracer(f.tracerProvider)
+	return f
+}
+
+// Distance-ordered expansion: a limited search starts with a small radius and
+// widens it until enough hubs are found or the requested radius is reached.
+const (
+	expansionStartKm = 50.0
+	expansionFactor  = 4.0
+)
+
+// Query describes a search for hubs around a point.
+type Query struct {
+	Lat      float64
+	Lon      float64
+	RadiusKm float64
+	// Limit caps the number of hubs returned. Zero means no limit.
+	Limit int
+	// Offset skips that many of the closest hubs, for paging through results.
+	Offset int
+	// SortBy orders the results. Defaults to SortDistance.
+	SortBy SortKey
+	// Order is the direction of the sort. Defaults to OrderDefault.
+	Order Order
+}
+
+// Result is the outcome of a Search.
+type Result struct {
+	Hubs []model.HubWithDistance
+	// Warnings lists backend rows that were left out of Hubs because they
+	// could not be converted into hubs. It is only populated by repositories
+	// that implement repository.DetailedRepository.
+	Warnings []repository.RowWarning
+	// HasMore reports whether hubs beyond this page exist within the radius.
+	HasMore bool
+	// NextOffset is the Offset of the next page when HasMore is true.
+	NextOffset int
+	// Stale reports that the repository answered from a snapshot because
+	// the live backend was unavailable, and SnapshotAge how old it is.
+	Stale       bool
+	SnapshotAge time.Duration
+	// SourceWarnings lists the sources of a repository.MultiRepository that
+	// failed and were left out of Hubs.
+	SourceWarnings []repository.SourceWarning
 }
 
 // FindNearby finds transport hubs within a specified radius (in kilometers) from a given point.
 // It returns a slice of hubs with distances sorted by distance from the given point (closest first).
 func (f *Finder) FindNearby(ctx context.Context, lat, lon, radiusKm float64) ([]model.HubWithDistance, error) {
+	result, err := f.Search(ctx, Query{Lat: lat, Lon: lon, RadiusKm: radiusKm})
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// Search runs q and returns the matching hubs sorted by distance (closest
+// first) or as q.SortBy and q.Order ask, together with any warnings
+// reported by the repository.
+//
+// When q.Limit is set and the hubs are sorted closest first, the repository
+// is first queried with a small radius that is widened until it holds
+// enough hubs for the requested page, so large radii do not require
+// fetching every hub inside them. Any other order considers every hub
+// within the radius before paging.
+func (f *Finder) Search(ctx context.Context, q Query) (_ Result, err error) {
+	lat, lon, radiusKm := q.Lat, q.Lon, q.RadiusKm
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.FindNearby", trace.WithAttributes(
+		attribute.Float64("hubfinder.lat", lat),
+		attribute.Float64("hubfinder.lon", lon),
+		attribute.Float64("hubfinder.radius_km", radiusKm),
+		attribute.Int("hubfinder.limit", q.Limit),
+		attribute.Int("hubfinder.offset", q.Offset),
+		attribute.String("hubfinder.sort", string(q.SortBy)),
+		attribute.String("hubfinder.order", string(q.Order)),
+	))
+	defer func() {
+		f.metrics.ObserveQuery("find_nearby", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	if q.Limit < 0 {
+		return Result{}, ErrNegativeLimit
+	}
+	if q.Offset < 0 {
+		return Result{}, ErrNegativeOffset
+	}
+	sortKey, descending, err := f.resolveSort(q.SortBy, q.Order)
+	if err != nil {
+		return Result{}, err
+	}
+	closestFirst := sortKey == SortDistance && !descending
+	// Keys that depend on the estimates need them for every hub, others
+	// only for the returned page.
+	annotateAll := sortKey == SortTravelTime || sortKey == SortScore
+
+	searchRadiusKm := radiusKm
+	if q.Limit > 0 && closestFirst {
+		searchRadiusKm = math.Min(radiusKm, expansionStartKm)
+	}
+	// One extra hub tells us whether another page exists.
+	needed := q.Offset + q.Limit + 1
+
+	var found nearbyResult
+	rounds := 0
+	for {
+		rounds++
+		found, err = f.collectNearby(ctx, lat, lon, searchRadiusKm)
+		if err != nil {
+			return Result{}, err
+		}
+		if q.Limit == 0 || len(found.hubs) >= needed || searchRadiusKm >= radiusKm {
+			break
+		}
+		searchRadiusKm = math.Min(radiusKm, searchRadiusKm*expansionFactor)
+		f.logger.DebugContext(ctx, "expanding search radius", "radius_km", searchRadiusKm, "found", len(found.hubs), "needed", needed)
+	}
+
+	nearbyHubs := found.hubs
+	if annotateAll {
+		if err := f.annotate(ctx, lat, lon, nearbyHubs); err != nil {
+			return Result{}, err
+		}
+	}
+	if !closestFirst {
+		sortHubs(nearbyHubs, geo.Point{Lat: lat, Lon: lon}, sortKey, descending)
+	}
+	result := Result{Warnings: found.warnings, Stale: found.stale, SnapshotAge: found.snapshotAge, SourceWarnings: found.sourceWarnings}
+	if q.Offset >= len(nearbyHubs) {
+		nearbyHubs = nearbyHubs[:0]
+	} else {
+		nearbyHubs = nearbyHubs[q.Offset:]
+	}
+	if q.Limit > 0 && len(nearbyHubs) > q.Limit {
+		nearbyHubs = nearbyHubs[:q.Limit]
+		result.HasMore = true
+		result.NextOffset = q.Offset + q.Limit
+	}
+	if !annotateAll {
+		if err := f.annotate(ctx, lat, lon, nearbyHubs); err != nil {
+			return Result{}, err
+		}
+	}
+	result.Hubs = nearbyHubs
+
+	span.SetAttributes(telemetry.BoundsAttributes(found.minLat, found.maxLat, found.minLon, found.maxLon)...)
+	span.SetAttributes(
+		attribute.Int("hubfinder.expansion_rounds", rounds),
+		attribute.Int("hubfinder.candidate_count", found.candidates),
+		attribute.Int("hubfinder.result_count", len(result.Hubs)),
+		attribute.Int("hubfinder.warning_count", len(result.Warnings)),
+		attribute.Bool("hubfinder.stale", result.Stale),
+	)
+	f.logger.InfoContext(ctx, "search finished",
+		"lat", lat, "lon", lon, "radius_km", radiusKm,
+		"limit", q.Limit, "offset", q.Offset,
+		"rounds", rounds,
+		"candidates", found.candidates,
+		"results", len(result.Hubs),
+		"warnings", len(result.Warnings),
+		"stale", result.Stale,
+		"duration", time.Since(start),
+	)
+
+	return result, nil
+}
+
+// nearbyResult holds the hubs found within one search radius.
+type nearbyResult struct {
+	hubs                           []model.HubWithDistance
+	warnings                       []repository.RowWarning
+	candidates                     int
+	minLat, maxLat, minLon, maxLon float64
+	stale                          bool
+	snapshotAge                    time.Duration
+	sourceWarnings                 []repository.SourceWarning
+}
+
+// collectNearby fetches the hubs inside the bounding box of the given circle
+// and returns those within radiusKm, sorted by distance (closest first).
+// Repositories that implement repository.RadiusSearcher are asked for the
+// circle directly; their results are still checked with the Haversine
+// distance so that every backend agrees on the edge of the circle.
+func (f *Finder) collectNearby(ctx context.Context, lat, lon, radiusKm float64) (nearbyResult, error) {
+	start := time.Now()
+
 	minLat, maxLat, minLon, maxLon, err := geo.CalculateBoundingBox(lat, lon, radiusKm)
 	if err != nil {
-		return nil, fmt.Errorf("calculate bounding box: %w", err)
+		return nearbyResult{}, fmt.Errorf("calculate bounding box: %w", err)
+	}
+	f.logger.DebugContext(ctx, "bounding box computed",
+		"lat", lat, "lon", lon, "radius_km", radiusKm,
+		"min_lat", minLat, "max_lat", maxLat, "min_lon", minLon, "max_lon", maxLon,
+	)
+
+	var fetched repository.Result
+	if searcher, ok := f.repo.(repository.RadiusSearcher); ok {
+		if fetched, err = searcher.GetByRadius(ctx, lat, lon, radiusKm); err != nil {
+			return nearbyResult{}, fmt.Errorf("get hubs by radius: %w", err)
+		}
+	} else if fetched, err = f.getByBounds(ctx, minLat, maxLat, minLon, maxLon); err != nil {
+		return nearbyResult{}, fmt.Errorf("get hubs by bounds: %w", err)
 	}
+	hubs := fetched.Hubs
 
+	nearbyHubs, err := withinRadius(lat, lon, radiusKm, hubs)
+	if err != nil {
+		return nearbyResult{}, err
+	}
+
+	f.logger.DebugContext(ctx, "hubs fetched",
+		"radius_km", radiusKm,
+		"candidates", len(hubs),
+		"within_radius", len(nearbyHubs),
+		"duration", time.Since(start),
+	)
+
+	return nearbyResult{
+		hubs:           nearbyHubs,
+		warnings:       fetched.Warnings,
+		candidates:     len(hubs),
+		minLat:         minLat,
+		maxLat:         maxLat,
+		minLon:         minLon,
+		maxLon:         maxLon,
+		stale:          fetched.Stale,
+		snapshotAge:    fetched.SnapshotAge,
+		sourceWarnings: fetched.SourceWarnings,
+	}, nil
+}
+
+// getByBounds queries the repository, using the detailed variant when the
+// repository supports it.
+func (f *Finder) getByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (repository.Result, error) {
+	if detailed, ok := f.repo.(repository.DetailedRepository); ok {
+		return detailed.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	}
 	hubs, err := f.repo.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
 	if err != nil {
-		return nil, fmt.Errorf("get hubs by bounds: %w", err)
+		return repository.Result{}, err
 	}
+	return repository.Result{Hubs: hubs}, nil
+}
 
+// withinRadius returns the hubs within radiusKm of a validated point with
+// their distances, sorted by distance (closest first).
+func withinRadius(lat, lon, radiusKm float64, hubs []model.Hub) ([]model.HubWithDistance, error) {
 	nearbyHubs := make([]model.HubWithDistance, 0, len(hubs))
 	for _, hub := range hubs {
 		distanceKm, err := geo.HaversineDistance(lat, lon, hub.Lat, hub.Lon)
 		if err != nil {
-			return nil, fmt.Errorf("calculate distance for hub %s: %w", hub.ID, err)
+			// The query point was validated by the caller, so the hub is at fault.
+			return nil, &repository.MalformedRowError{RowWarning: repository.RowWarning{ID: hub.ID, Reason: err.Error()}}
 		}
 		if distanceKm <= radiusKm {
 			nearbyHubs = append(nearbyHubs, model.HubWithDistance{
@@ -45,9 +323,6 @@ func (f *Finder) FindNearby(ctx context.Context, lat, lon, radiusKm float64) ([]
 		}
 	}
 
-	sort.Slice(nearbyHubs, func(i, j int) bool {
-		return nearbyHubs[i].DistanceKm < nearbyHubs[j].DistanceKm
-	})
-
+	sortHubs(nearbyHubs, geo.Point{Lat: lat, Lon: lon}, SortDistance, false)
 	return nearbyHubs, nil
 }
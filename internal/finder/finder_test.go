@@ -1,11 +1,25 @@
 package finder
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type mockRepository struct {
@@ -495,3 +509,329 @@ func TestFindNearby_PreservesHubData(t *testing.T) {
 		t.Errorf("Expected Lon %f, got %f", expectedHub.Lon, result.Lon)
 	}
 }
+
+func TestFindNearby_LogsSearchTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	repo := &mockRepository{
+		hubs: []model.Hub{
+			{ID: "hub1", Name: "JFK Airport", Lat: 40.6413, Lon: -73.7781},
+			{ID: "hub2", Name: "Los Angeles Airport", Lat: 34.0522, Lon: -118.2437},
+		},
+	}
+	f := New(repo, WithLogger(logger))
+
+	if _, err := f.FindNearby(context.Background(), 40.7128, -74.0060, 50); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %v", err)
+		}
+		entries[entry["msg"].(string)] = entry
+	}
+
+	box, ok := entries["bounding box computed"]
+	if !ok {
+		t.Fatalf("missing bounding box log entry, got: %s", buf.String())
+	}
+	for _, key := range []string{"min_lat", "max_lat", "min_lon", "max_lon"} {
+		if _, ok := box[key]; !ok {
+			t.Errorf("bounding box log entry missing %q", key)
+		}
+	}
+
+	done, ok := entries["search finished"]
+	if !ok {
+		t.Fatalf("missing search finished log entry, got: %s", buf.String())
+	}
+	if done["candidates"] != 1.0 || done["results"] != 1.0 {
+		t.Errorf("expected 1 candidate and 1 result, got candidates=%v results=%v", done["candidates"], done["results"])
+	}
+}
+
+type detailedMockRepository struct {
+	mockRepository
+	warnings []repository.RowWarning
+}
+
+func (m *detailedMockRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (repository.Result, error) {
+	hubs, err := m.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return repository.Result{}, err
+	}
+	return repository.Result{Hubs: hubs, Warnings: m.warnings}, nil
+}
+
+func TestSearch_ReturnsRepositoryWarnings(t *testing.T) {
+	repo := &detailedMockRepository{
+		mockRepository: mockRepository{
+			hubs: []model.Hub{
+				{ID: "hub1", Name: "JFK Airport", Lat: 40.6413, Lon: -73.7781},
+			},
+		},
+		warnings: []repository.RowWarning{
+			{ID: "hub2", Reason: "lat is a string (\"40.69\"), not a number"},
+		},
+	}
+	f := New(repo)
+
+	result, err := f.Search(context.Background(), Query{Lat: 40.7128, Lon: -74.0060, RadiusKm: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hubs) != 1 || result.Hubs[0].ID != "hub1" {
+		t.Errorf("expected hub1 only, got %v", result.Hubs)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].ID != "hub2" {
+		t.Errorf("expected warning for hub2, got %v", result.Warnings)
+	}
+}
+
+func TestSearch_ReportsStaleResults(t *testing.T) {
+	repo, err := repository.NewFallbackRepository(repository.FallbackConfig{
+		Primary: &mockRepository{returnErr: &repository.BackendError{Op: "post search", Retryable: true, Err: errors.New("connection refused")}},
+		Snapshot: &repository.Snapshot{
+			CreatedAt: time.Now().Add(-2 * time.Hour),
+			Hubs:      []model.Hub{{ID: "hub1", Name: "JFK Airport", Lat: 40.6413, Lon: -73.7781}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create fallback repository: %v", err)
+	}
+
+	result, err := New(repo).Search(context.Background(), Query{Lat: 40.7128, Lon: -74.0060, RadiusKm: 50, Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 1 || !result.Stale {
+		t.Fatalf("expected one stale hub, got %+v", result)
+	}
+	if result.SnapshotAge < 2*time.Hour || result.SnapshotAge > 3*time.Hour {
+		t.Errorf("expected a snapshot age of about 2h, got %v", result.SnapshotAge)
+	}
+}
+
+func TestSearch_PlainRepositoryHasNoWarnings(t *testing.T) {
+	repo := &mockRepository{
+		hubs: []model.Hub{
+			{ID: "hub1", Name: "JFK Airport", Lat: 40.6413, Lon: -73.7781},
+		},
+	}
+	f := New(repo)
+
+	result, err := f.Search(context.Background(), Query{Lat: 40.7128, Lon: -74.0060, RadiusKm: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hubs) != 1 {
+		t.Errorf("expected 1 hub, got %d", len(result.Hubs))
+	}
+	if result.Warnings != nil {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSearch_RecordsSpanAndMetrics(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	reg := prometheus.NewRegistry()
+	metrics, err := telemetry.NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("create metrics: %v", err)
+	}
+
+	repo := &mockRepository{
+		hubs: []model.Hub{
+			{ID: "hub1", Name: "JFK Airport", Lat: 40.6413, Lon: -73.7781},
+			{ID: "hub2", Name: "Newark Airport", Lat: 40.6895, Lon: -74.1745},
+		},
+	}
+	f := New(repo, WithTracerProvider(tp), WithMetrics(metrics))
+
+	if _, err := f.FindNearby(context.Background(), 40.7128, -74.0060, 50); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.FindNearby(context.Background(), 100, -74.0060, 50); err == nil {
+		t.Fatal("expected error for invalid latitude")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	ok := spans[0]
+	if ok.Name() != "Finder.FindNearby" {
+		t.Errorf("unexpected span name %q", ok.Name())
+	}
+	attrs := spanAttributes(ok)
+	if attrs["hubfinder.result_count"].AsInt64() != 2 {
+		t.Errorf("expected result_count 2, got %v", attrs["hubfinder.result_count"])
+	}
+	for _, key := range []attribute.Key{"hubfinder.bbox.min_lat", "hubfinder.bbox.max_lat", "hubfinder.bbox.min_lon", "hubfinder.bbox.max_lon"} {
+		if _, found := attrs[key]; !found {
+			t.Errorf("span missing attribute %q", key)
+		}
+	}
+
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("expected error status on failed search, got %v", spans[1].Status())
+	}
+
+	if got := testutil.CollectAndCount(reg, "hubfinder_query_duration_seconds"); got != 2 {
+		t.Errorf("expected success and error latency series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(reg, "hubfinder_errors_total"); got != 1 {
+		t.Errorf("expected 1 error series, got %d", got)
+	}
+}
+
+type countingRepository struct {
+	mockRepository
+	calls     int
+	maxLatGot []float64
+}
+
+func (c *countingRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	c.calls++
+	c.maxLatGot = append(c.maxLatGot, maxLat)
+	return c.mockRepository.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+}
+
+// gridHubs returns hubs spread on a 0.1 degree grid around Budapest.
+func gridHubs() []model.Hub {
+	var hubs []model.Hub
+	for i := -20; i <= 20; i++ {
+		for j := -20; j <= 20; j++ {
+			hubs = append(hubs, model.Hub{
+				ID:   fmt.Sprintf("hub_%03d_%03d", i+20, j+20),
+				Name: "Grid hub",
+				Lat:  47.5 + float64(i)*0.1,
+				Lon:  19.0 + float64(j)*0.1,
+			})
+		}
+	}
+	return hubs
+}
+
+func TestSearch_LimitStopsEarly(t *testing.T) {
+	repo := &countingRepository{mockRepository: mockRepository{hubs: gridHubs()}}
+	f := New(repo)
+
+	result, err := f.Search(context.Background(), Query{Lat: 47.5, Lon: 19.0, RadiusKm: 5000, Limit: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hubs) != 5 {
+		t.Fatalf("expected 5 hubs, got %d", len(result.Hubs))
+	}
+	if !result.HasMore || result.NextOffset != 5 {
+		t.Errorf("expected another page at offset 5, got HasMore=%v NextOffset=%d", result.HasMore, result.NextOffset)
+	}
+	if repo.calls != 1 {
+		t.Errorf("expected a single repository call, got %d", repo.calls)
+	}
+	if repo.maxLatGot[0] > 48.5 {
+		t.Errorf("expected a bounding box much smaller than the radius, got max lat %f", repo.maxLatGot[0])
+	}
+}
+
+func TestSearch_LimitExpandsUntilEnoughHubs(t *testing.T) {
+	repo := &countingRepository{mockRepository: mockRepository{hubs: []model.Hub{
+		{ID: "near", Name: "Near", Lat: 47.5, Lon: 19.1},
+		{ID: "far", Name: "Far", Lat: 52.5, Lon: 13.4},
+		{ID: "farther", Name: "Farther", Lat: 40.4, Lon: -3.7},
+	}}}
+	f := New(repo)
+
+	result, err := f.Search(context.Background(), Query{Lat: 47.5, Lon: 19.0, RadiusKm: 3000, Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hubs) != 2 || result.Hubs[0].ID != "near" || result.Hubs[1].ID != "far" {
+		t.Fatalf("expected near and far, got %v", result.Hubs)
+	}
+	if !result.HasMore {
+		t.Error("expected HasMore to be true")
+	}
+	if repo.calls < 2 {
+		t.Errorf("expected the search radius to be expanded, got %d call(s)", repo.calls)
+	}
+}
+
+func TestSearch_PagesMatchFullSearch(t *testing.T) {
+	repo := &mockRepository{hubs: gridHubs()}
+	f := New(repo)
+
+	full, err := f.Search(context.Background(), Query{Lat: 47.52, Lon: 19.03, RadiusKm: 120})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if full.HasMore {
+		t.Error("unlimited search must not report more pages")
+	}
+
+	var paged []model.HubWithDistance
+	offset := 0
+	for {
+		page, err := f.Search(context.Background(), Query{Lat: 47.52, Lon: 19.03, RadiusKm: 120, Limit: 37, Offset: offset})
+		if err != nil {
+			t.Fatalf("unexpected error at offset %d: %v", offset, err)
+		}
+		paged = append(paged, page.Hubs...)
+		if !page.HasMore {
+			break
+		}
+		offset = page.NextOffset
+	}
+
+	if len(paged) != len(full.Hubs) {
+		t.Fatalf("paged search returned %d hubs, full search %d", len(paged), len(full.Hubs))
+	}
+	for i := range paged {
+		if paged[i].ID != full.Hubs[i].ID {
+			t.Fatalf("position %d: paged %s, full %s", i, paged[i].ID, full.Hubs[i].ID)
+		}
+	}
+}
+
+func TestSearch_OffsetBeyondResults(t *testing.T) {
+	repo := &mockRepository{hubs: gridHubs()[:3]}
+	f := New(repo)
+
+	result, err := f.Search(context.Background(), Query{Lat: 45.5, Lon: 17.0, RadiusKm: 100, Limit: 10, Offset: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 0 || result.HasMore {
+		t.Errorf("expected an empty last page, got %d hubs, HasMore=%v", len(result.Hubs), result.HasMore)
+	}
+}
+
+func TestSearch_InvalidPaging(t *testing.T) {
+	f := New(&mockRepository{})
+
+	if _, err := f.Search(context.Background(), Query{Lat: 0, Lon: 0, RadiusKm: 10, Limit: -1}); !errors.Is(err, ErrNegativeLimit) {
+		t.Errorf("expected ErrNegativeLimit, got %v", err)
+	}
+	if _, err := f.Search(context.Background(), Query{Lat: 0, Lon: 0, RadiusKm: 10, Offset: -1}); !errors.Is(err, ErrNegativeOffset) {
+		t.Errorf("expected ErrNegativeOffset, got %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// antipodalDistanceKm is the largest possible distance between two points,
+// so a radius of this size covers the whole globe.
+const antipodalDistanceKm = math.Pi * geo.EarthRadiusKm
+
+// FindNearest returns the count hubs closest to the given point. Only hubs
+// within maxRadiusKm are considered; zero searches the whole globe.
+// Repositories that implement repository.NearestSearcher are asked for the
+// closest hubs directly; others are searched with a widening radius.
+func (f *Finder) FindNearest(ctx context.Context, lat, lon float64, count int, maxRadiusKm float64) (Result, error) {
+	if count <= 0 {
+		return Result{}, ErrInvalidCount
+	}
+	if maxRadiusKm == 0 {
+		maxRadiusKm = antipodalDistanceKm
+	}
+	if searcher, ok := f.repo.(repository.NearestSearcher); ok {
+		return f.findNearest(ctx, searcher, lat, lon, count, maxRadiusKm)
+	}
+	return f.Search(ctx, Query{Lat: lat, Lon: lon, RadiusKm: maxRadiusKm, Limit: count})
+}
+
+// findNearest answers FindNearest with a NearestSearcher. One extra hub is
+// requested to tell whether more exist within the radius.
+func (f *Finder) findNearest(ctx context.Context, searcher repository.NearestSearcher, lat, lon float64, count int, maxRadiusKm float64) (_ Result, err error) {
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.FindNearest", trace.WithAttributes(
+		attribute.Float64("hubfinder.lat", lat),
+		attribute.Float64("hubfinder.lon", lon),
+		attribute.Float64("hubfinder.radius_km", maxRadiusKm),
+		attribute.Int("hubfinder.limit", count),
+	))
+	defer func() {
+		f.metrics.ObserveQuery("find_nearest", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	if err := geo.ValidateCoordinates(lat, lon); err != nil {
+		return Result{}, err
+	}
+	if maxRadiusKm < 0 {
+		return Result{}, geo.ErrNegativeRadius
+	}
+
+	fetched, err := searcher.GetNearest(ctx, lat, lon, count+1, maxRadiusKm)
+	if err != nil {
+		return Result{}, fmt.Errorf("get nearest hubs: %w", err)
+	}
+	hubs, err := withinRadius(lat, lon, maxRadiusKm, fetched.Hubs)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Warnings: fetched.Warnings, Stale: fetched.Stale, SnapshotAge: fetched.SnapshotAge, SourceWarnings: fetched.SourceWarnings}
+	if len(hubs) > count {
+		hubs = hubs[:count]
+		result.HasMore = true
+		result.NextOffset = count
+	}
+	if err := f.annotate(ctx, lat, lon, hubs); err != nil {
+		return Result{}, err
+	}
+	result.Hubs = hubs
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.candidate_count", len(fetched.Hubs)),
+		attribute.Int("hubfinder.result_count", len(result.Hubs)),
+		attribute.Int("hubfinder.warning_count", len(result.Warnings)),
+	)
+	f.logger.InfoContext(ctx, "nearest search finished",
+		"lat", lat, "lon", lon, "radius_km", maxRadiusKm, "count", count,
+		"candidates", len(fetched.Hubs),
+		"results", len(result.Hubs),
+		"warnings", len(result.Warnings),
+		"duration", time.Since(start),
+	)
+	return result, nil
+}
+
+// FindInPolygon returns the hubs inside polygon or on its boundary, sorted
+// by ID. The polygon must satisfy geo.ValidatePolygon. Repositories that
+// implement repository.PolygonSearcher are asked for the polygon directly;
+// others are asked for its bounding box.
+func (f *Finder) FindInPolygon(ctx context.Context, polygon []geo.Point) (_ repository.Result, err error) {
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.FindInPolygon", trace.WithAttributes(
+		attribute.Int("hubfinder.vertex_count", len(polygon)),
+	))
+	defer func() {
+		f.metrics.ObserveQuery("find_in_polygon", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	if err := geo.ValidatePolygon(polygon); err != nil {
+		return repository.Result{}, err
+	}
+	minLat, maxLat, minLon, maxLon := geo.PolygonBounds(polygon)
+	span.SetAttributes(telemetry.BoundsAttributes(minLat, maxLat, minLon, maxLon)...)
+
+	var fetched repository.Result
+	if searcher, ok := f.repo.(repository.PolygonSearcher); ok {
+		if fetched, err = searcher.GetByPolygon(ctx, polygon); err != nil {
+			return repository.Result{}, fmt.Errorf("get hubs by polygon: %w", err)
+		}
+	} else if fetched, err = f.getByBounds(ctx, minLat, maxLat, minLon, maxLon); err != nil {
+		return repository.Result{}, fmt.Errorf("get hubs by bounds: %w", err)
+	}
+
+	hubs := make([]model.Hub, 0, len(fetched.Hubs))
+	for _, hub := range fetched.Hubs {
+		if geo.PointInPolygon(hub.Lat, hub.Lon, polygon) {
+			hubs = append(hubs, hub)
+		}
+	}
+	sort.Slice(hubs, func(i, j int) bool { return hubs[i].ID < hubs[j].ID })
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.candidate_count", len(fetched.Hubs)),
+		attribute.Int("hubfinder.result_count", len(hubs)),
+		attribute.Int("hubfinder.warning_count", len(fetched.Warnings)),
+	)
+	f.logger.InfoContext(ctx, "polygon search finished",
+		"vertices", len(polygon),
+		"candidates", len(fetched.Hubs),
+		"results", len(hubs),
+		"warnings", len(fetched.Warnings),
+		"duration", time.Since(start),
+	)
+
+	fetched.Hubs = hubs
+	return fetched, nil
+}
+
+// GetHub returns the hub with the given ID. Repositories that implement
+// repository.IDGetter are asked directly; others are scanned in full. The
+// error wraps repository.ErrNotFound if no hub has that ID.
+func (f *Finder) GetHub(ctx context.Context, id string) (_ model.Hub, err error) {
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.GetHub", trace.WithAttributes(
+		attribute.String("hubfinder.hub_id", id),
+	))
+	defer func() {
+		f.metrics.ObserveQuery("get_hub", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	if getter, ok := f.repo.(repository.IDGetter); ok {
+		return getter.GetHub(ctx, id)
+	}
+
+	fetched, err := f.getByBounds(ctx, -90, 90, -180, 180)
+	if err != nil {
+		return model.Hub{}, fmt.Errorf("get hubs by bounds: %w", err)
+	}
+	for _, hub := range fetched.Hubs {
+		if hub.ID == id {
+			return hub, nil
+		}
+	}
+	return model.Hub{}, fmt.Errorf("hub %q: %w", id, repository.ErrNotFound)
+}
+
+// FindByName returns the hubs whose name contains every word of text,
+// ignoring case and punctuation, sorted by name and then ID. limit caps the
+// number of hubs returned; zero means no limit. Repositories that implement
+// repository.TextSearcher are asked directly; others are scanned in full.
+func (f *Finder) FindByName(ctx context.Context, text string, limit int) (_ repository.Result, err error) {
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.FindByName", trace.WithAttributes(
+		attribute.String("hubfinder.text", text),
+		attribute.Int("hubfinder.limit", limit),
+	))
+	defer func() {
+		f.metrics.ObserveQuery("find_by_name", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	terms := repository.TextTerms(text)
+	if len(terms) == 0 {
+		return repository.Result{}, ErrEmptyText
+	}
+	if limit < 0 {
+		return repository.Result{}, ErrNegativeLimit
+	}
+
+	var fetched repository.Result
+	if searcher, ok := f.repo.(repository.TextSearcher); ok {
+		if fetched, err = searcher.SearchText(ctx, text); err != nil {
+			return repository.Result{}, fmt.Errorf("search text: %w", err)
+		}
+	} else if fetched, err = f.getByBounds(ctx, -90, 90, -180, 180); err != nil {
+		return repository.Result{}, fmt.Errorf("get hubs by bounds: %w", err)
+	}
+
+	hubs := make([]model.Hub, 0, len(fetched.Hubs))
+	for _, hub := range fetched.Hubs {
+		if containsTerms(repository.TextTerms(hub.Name), terms) {
+			hubs = append(hubs, hub)
+		}
+	}
+	sort.Slice(hubs, func(i, j int) bool {
+		if hubs[i].Name != hubs[j].Name {
+			return hubs[i].Name < hubs[j].Name
+		}
+		return hubs[i].ID < hubs[j].ID
+	})
+	if limit > 0 && len(hubs) > limit {
+		hubs = hubs[:limit]
+	}
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.candidate_count", len(fetched.Hubs)),
+		attribute.Int("hubfinder.result_count", len(hubs)),
+	)
+	f.logger.InfoContext(ctx, "name search finished",
+		"text", text,
+		"candidates", len(fetched.Hubs),
+		"results", len(hubs),
+		"duration", time.Since(start),
+	)
+	fetched.Hubs = hubs
+	return fetched, nil
+}
+
+// containsTerms reports whether every term of want is in have.
+func containsTerms(have, want []string) bool {
+	for _, term := range want {
+		if !slices.Contains(have, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// CountInBounds returns the number of hubs within the given bounds, where a
+// minLon greater than maxLon wraps across the antimeridian. Repositories that
+// implement repository.Counter count without returning the hubs.
+func (f *Finder) CountInBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (_ int, err error) {
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.CountInBounds", trace.WithAttributes(
+		telemetry.BoundsAttributes(minLat, maxLat, minLon, maxLon)...,
+	))
+	defer func() {
+		f.metrics.ObserveQuery("count_in_bounds", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	if err := geo.ValidateCoordinates(minLat, minLon); err != nil {
+		return 0, err
+	}
+	if err := geo.ValidateCoordinates(maxLat, maxLon); err != nil {
+		return 0, err
+	}
+
+	if counter, ok := f.repo.(repository.Counter); ok {
+		count, err := counter.CountByBounds(ctx, minLat, maxLat, minLon, maxLon)
+		if err != nil {
+			return 0, fmt.Errorf("count hubs by bounds: %w", err)
+		}
+		return count, nil
+	}
+	fetched, err := f.getByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return 0, fmt.Errorf("get hubs by bounds: %w", err)
+	}
+	return len(fetched.Hubs), nil
+}
@@ -0,0 +1,364 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+func TestFindNearest(t *testing.T) {
+	repo := &mockRepository{hubs: []model.Hub{
+		{ID: "near", Name: "Near", Lat: 47.5, Lon: 19.1},
+		{ID: "far", Name: "Far", Lat: 52.5, Lon: 13.4},
+		{ID: "antipode", Name: "Antipode", Lat: -47.5, Lon: -161.0},
+	}}
+	f := New(repo)
+
+	result, err := f.FindNearest(context.Background(), 47.5, 19.0, 2, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 2 || result.Hubs[0].ID != "near" || result.Hubs[1].ID != "far" {
+		t.Fatalf("expected near and far, got %v", result.Hubs)
+	}
+
+	result, err = f.FindNearest(context.Background(), 47.5, 19.0, 3, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 3 || result.Hubs[2].ID != "antipode" {
+		t.Errorf("expected the whole globe to be searched, got %v", result.Hubs)
+	}
+
+	result, err = f.FindNearest(context.Background(), 47.5, 19.0, 3, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 1 {
+		t.Errorf("expected max radius to limit the search to 1 hub, got %v", result.Hubs)
+	}
+
+	if _, err := f.FindNearest(context.Background(), 47.5, 19.0, 0, 0); err == nil {
+		t.Error("expected an error for a zero count")
+	}
+}
+
+func TestFindInPolygon(t *testing.T) {
+	repo := &mockRepository{hubs: gridHubs()}
+	f := New(repo)
+
+	// A triangle with its right angle at the grid centre.
+	triangle := []geo.Point{{Lat: 47.5, Lon: 19.0}, {Lat: 47.8, Lon: 19.0}, {Lat: 47.5, Lon: 19.3}}
+	result, err := f.FindInPolygon(context.Background(), triangle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Grid points (i, j) with i, j >= 0 and i + j <= 3.
+	if len(result.Hubs) != 10 {
+		t.Fatalf("expected 10 hubs, got %d: %v", len(result.Hubs), result.Hubs)
+	}
+	for i := 1; i < len(result.Hubs); i++ {
+		if result.Hubs[i-1].ID > result.Hubs[i].ID {
+			t.Errorf("expected hubs sorted by ID, got %s before %s", result.Hubs[i-1].ID, result.Hubs[i].ID)
+		}
+	}
+
+	if _, err := f.FindInPolygon(context.Background(), triangle[:2]); err == nil {
+		t.Error("expected an error for a polygon with 2 vertices")
+	}
+}
+
+type idGetterRepository struct {
+	mockRepository
+	calls int
+}
+
+func (r *idGetterRepository) GetHub(_ context.Context, id string) (model.Hub, error) {
+	r.calls++
+	for _, hub := range r.hubs {
+		if hub.ID == id {
+			return hub, nil
+		}
+	}
+	return model.Hub{}, repository.ErrNotFound
+}
+
+func TestGetHub(t *testing.T) {
+	hubs := []model.Hub{{ID: "BUD", Name: "Budapest", Lat: 47.43, Lon: 19.26}}
+	getter := &idGetterRepository{mockRepository: mockRepository{hubs: hubs}}
+
+	tests := []struct {
+		name string
+		repo repository.Repository
+	}{
+		{"scans plain repository", &mockRepository{hubs: hubs}},
+		{"uses IDGetter", getter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(tt.repo)
+
+			got, err := f.GetHub(context.Background(), "BUD")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != hubs[0] {
+				t.Errorf("got %+v, want %+v", got, hubs[0])
+			}
+
+			if _, err := f.GetHub(context.Background(), "missing"); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+
+	if getter.calls != 2 {
+		t.Errorf("expected IDGetter to be called twice, got %d", getter.calls)
+	}
+}
+
+// nativeRepository answers radius and polygon queries natively and fails
+// bounds queries, so tests notice if the finder falls back to them. Radius
+// queries return every hub within twice the radius, to check that the
+// finder still applies its own distance filter.
+type nativeRepository struct {
+	mockRepository
+	radiusCalls, polygonCalls int
+}
+
+func (r *nativeRepository) GetByBounds(context.Context, float64, float64, float64, float64) ([]model.Hub, error) {
+	return nil, errors.New("unexpected bounds query")
+}
+
+func (r *nativeRepository) GetByRadius(_ context.Context, lat, lon, radiusKm float64) (repository.Result, error) {
+	r.radiusCalls++
+	var hubs []model.Hub
+	for _, hub := range r.hubs {
+		if d, _ := geo.HaversineDistance(lat, lon, hub.Lat, hub.Lon); d <= 2*radiusKm {
+			hubs = append(hubs, hub)
+		}
+	}
+	return repository.Result{Hubs: hubs}, nil
+}
+
+func (r *nativeRepository) GetByPolygon(_ context.Context, polygon []geo.Point) (repository.Result, error) {
+	r.polygonCalls++
+	var hubs []model.Hub
+	for _, hub := range r.hubs {
+		if geo.PointInPolygon(hub.Lat, hub.Lon, polygon) {
+			hubs = append(hubs, hub)
+		}
+	}
+	return repository.Result{Hubs: hubs}, nil
+}
+
+func TestNativeSearchesMatchFallback(t *testing.T) {
+	ctx := context.Background()
+	native := &nativeRepository{mockRepository: mockRepository{hubs: gridHubs()}}
+	withNative, withFallback := New(native), New(&mockRepository{hubs: gridHubs()})
+
+	for _, q := range []Query{
+		{Lat: 47.5, Lon: 19.0, RadiusKm: 25},
+		{Lat: 47.5, Lon: 19.0, RadiusKm: 300, Limit: 5, Offset: 5},
+	} {
+		got, err := withNative.Search(ctx, q)
+		if err != nil {
+			t.Fatalf("native search: %v", err)
+		}
+		want, err := withFallback.Search(ctx, q)
+		if err != nil {
+			t.Fatalf("fallback search: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("query %+v: got %v, want %v", q, got.Hubs, want.Hubs)
+		}
+	}
+	if native.radiusCalls == 0 {
+		t.Error("expected GetByRadius to be used")
+	}
+
+	triangle := []geo.Point{{Lat: 47.5, Lon: 19.0}, {Lat: 47.8, Lon: 19.0}, {Lat: 47.5, Lon: 19.3}}
+	got, err := withNative.FindInPolygon(ctx, triangle)
+	if err != nil {
+		t.Fatalf("native polygon search: %v", err)
+	}
+	want, err := withFallback.FindInPolygon(ctx, triangle)
+	if err != nil {
+		t.Fatalf("fallback polygon search: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got.Hubs, want.Hubs)
+	}
+	if native.polygonCalls != 1 {
+		t.Errorf("expected GetByPolygon to be called once, got %d", native.polygonCalls)
+	}
+}
+
+// nearestRepository implements repository.NearestSearcher by sorting every
+// hub by distance, as a search index sort would.
+type nearestRepository struct {
+	mockRepository
+	calls int
+}
+
+func (r *nearestRepository) GetNearest(_ context.Context, lat, lon float64, count int, maxRadiusKm float64) (repository.Result, error) {
+	r.calls++
+	hubs, err := withinRadius(lat, lon, maxRadiusKm, r.hubs)
+	if err != nil {
+		return repository.Result{}, err
+	}
+	result := repository.Result{}
+	for _, hub := range hubs[:min(count, len(hubs))] {
+		result.Hubs = append(result.Hubs, hub.Hub)
+	}
+	return result, nil
+}
+
+// textRepository implements repository.TextSearcher, returning its matches
+// in reverse ID order to check that the finder sorts them.
+type textRepository struct {
+	mockRepository
+	calls int
+}
+
+func (r *textRepository) SearchText(_ context.Context, text string) (repository.Result, error) {
+	r.calls++
+	var hubs []model.Hub
+	for _, hub := range r.hubs {
+		if containsTerms(repository.TextTerms(hub.Name), repository.TextTerms(text)) {
+			hubs = append(hubs, hub)
+		}
+	}
+	slices.Reverse(hubs)
+	return repository.Result{Hubs: hubs}, nil
+}
+
+// counterRepository implements repository.Counter.
+type counterRepository struct {
+	mockRepository
+	calls int
+}
+
+func (r *counterRepository) CountByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (int, error) {
+	r.calls++
+	hubs, err := r.mockRepository.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	return len(hubs), err
+}
+
+func capabilityHubs() []model.Hub {
+	return append(gridHubs(),
+		model.Hub{ID: "bud", Name: "Budapest Ferenc Liszt Airport", Lat: 47.437, Lon: 19.261},
+		model.Hub{ID: "bud-keleti", Name: "Budapest-Keleti", Lat: 47.5, Lon: 19.084},
+		model.Hub{ID: "deb", Name: "Debrecen Airport", Lat: 47.489, Lon: 21.615},
+		model.Hub{ID: "fiji", Name: "Nadi Airport", Lat: -17.755, Lon: 177.443},
+	)
+}
+
+func TestCapabilitiesMatchFallback(t *testing.T) {
+	ctx := context.Background()
+	fallback := New(&mockRepository{hubs: capabilityHubs()})
+
+	t.Run("NearestSearcher", func(t *testing.T) {
+		repo := &nearestRepository{mockRepository: mockRepository{hubs: capabilityHubs()}}
+		native := New(repo)
+		for _, tc := range []struct {
+			count     int
+			maxRadius float64
+		}{{1, 0}, {7, 0}, {5, 20}, {50, 3}, {2000, 0}} {
+			got, err := native.FindNearest(ctx, 47.51, 19.02, tc.count, tc.maxRadius)
+			if err != nil {
+				t.Fatalf("native: %v", err)
+			}
+			want, err := fallback.FindNearest(ctx, 47.51, 19.02, tc.count, tc.maxRadius)
+			if err != nil {
+				t.Fatalf("fallback: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("count %d, radius %g: got %v (more %v), want %v (more %v)", tc.count, tc.maxRadius, got.Hubs, got.HasMore, want.Hubs, want.HasMore)
+			}
+		}
+		if repo.calls != 5 {
+			t.Errorf("expected GetNearest to be called 5 times, got %d", repo.calls)
+		}
+	})
+
+	t.Run("TextSearcher", func(t *testing.T) {
+		repo := &textRepository{mockRepository: mockRepository{hubs: capabilityHubs()}}
+		native := New(repo)
+		for _, tc := range []struct {
+			text  string
+			limit int
+		}{{"airport", 0}, {"AIRPORT", 2}, {"budapest", 0}, {"keleti budapest", 0}, {"grid", 3}, {"nowhere", 0}} {
+			got, err := native.FindByName(ctx, tc.text, tc.limit)
+			if err != nil {
+				t.Fatalf("native: %v", err)
+			}
+			want, err := fallback.FindByName(ctx, tc.text, tc.limit)
+			if err != nil {
+				t.Fatalf("fallback: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q: got %v, want %v", tc.text, got.Hubs, want.Hubs)
+			}
+		}
+		if repo.calls != 6 {
+			t.Errorf("expected SearchText to be called 6 times, got %d", repo.calls)
+		}
+	})
+
+	t.Run("Counter", func(t *testing.T) {
+		repo := &counterRepository{mockRepository: mockRepository{hubs: capabilityHubs()}}
+		native := New(repo)
+		for _, box := range [][4]float64{{47, 48, 19, 20}, {-90, 90, -180, 180}, {-20, -10, 170, -170}, {0, 1, 0, 1}} {
+			got, err := native.CountInBounds(ctx, box[0], box[1], box[2], box[3])
+			if err != nil {
+				t.Fatalf("native: %v", err)
+			}
+			want, err := fallback.CountInBounds(ctx, box[0], box[1], box[2], box[3])
+			if err != nil {
+				t.Fatalf("fallback: %v", err)
+			}
+			if got != want {
+				t.Errorf("bounds %v: got %d, want %d", box, got, want)
+			}
+		}
+		if repo.calls != 4 {
+			t.Errorf("expected CountByBounds to be called 4 times, got %d", repo.calls)
+		}
+	})
+}
+
+func TestFindByName(t *testing.T) {
+	f := New(&mockRepository{hubs: capabilityHubs()})
+
+	result, err := f.FindByName(context.Background(), "budapest, airport", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 1 || result.Hubs[0].ID != "bud" {
+		t.Errorf("expected only bud, got %v", result.Hubs)
+	}
+
+	result, err = f.FindByName(context.Background(), "Budapest", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 2 || result.Hubs[0].ID != "bud" || result.Hubs[1].ID != "bud-keleti" {
+		t.Errorf("expected bud and bud-keleti sorted by name, got %v", result.Hubs)
+	}
+
+	if _, err := f.FindByName(context.Background(), " - ", 0); !errors.Is(err, ErrEmptyText) {
+		t.Errorf("expected ErrEmptyText, got %v", err)
+	}
+	if _, err := f.FindByName(context.Background(), "airport", -1); !errors.Is(err, ErrNegativeLimit) {
+		t.Errorf("expected ErrNegativeLimit, got %v", err)
+	}
+}
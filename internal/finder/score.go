@@ -0,0 +1,181 @@
+package finder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// Names of the factors of a WeightedScorer.
+const (
+	FactorDistance       = "distance"
+	FactorType           = "type"
+	FactorRoutes         = "routes"
+	FactorPassengerClass = "passenger_class"
+)
+
+const (
+	defaultDistanceScaleKm = 50
+	defaultRoutesScale     = 200
+)
+
+// Weights sets how much each factor of a WeightedScorer counts. Only their
+// ratios matter; a zero weight leaves the factor out.
+type Weights struct {
+	Distance       float64
+	Type           float64
+	Routes         float64
+	PassengerClass float64
+}
+
+// DefaultWeights favours close hubs while still preferring large airports
+// with many routes to a nearby airstrip.
+var DefaultWeights = Weights{Distance: 0.5, Type: 0.2, Routes: 0.2, PassengerClass: 0.1}
+
+// ParseWeights parses weights given as comma-separated factor=weight pairs,
+// such as "distance=2,routes=1". Factors that are not named get no weight.
+func ParseWeights(s string) (Weights, error) {
+	var w Weights
+	for pair := range strings.SplitSeq(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return Weights{}, fmt.Errorf("invalid weight %q: want factor=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return Weights{}, fmt.Errorf("invalid weight %q: %w", pair, err)
+		}
+		switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_") {
+		case FactorDistance:
+			w.Distance = weight
+		case FactorType:
+			w.Type = weight
+		case FactorRoutes:
+			w.Routes = weight
+		case FactorPassengerClass:
+			w.PassengerClass = weight
+		default:
+			return Weights{}, fmt.Errorf("invalid weight %q: factor must be distance, type, routes or passenger_class", pair)
+		}
+	}
+	return w, w.validate()
+}
+
+func (w Weights) validate() error {
+	for _, weight := range []float64{w.Distance, w.Type, w.Routes, w.PassengerClass} {
+		if math.IsNaN(weight) || math.IsInf(weight, 0) || weight < 0 {
+			return fmt.Errorf("weights must be non-negative numbers")
+		}
+	}
+	return nil
+}
+
+func (w Weights) sum() float64 {
+	return w.Distance + w.Type + w.Routes + w.PassengerClass
+}
+
+// WeightedScorer scores hubs with a weighted average of factors that each
+// rate the hub from 0 to 1:
+//   - distance: 1 at the search point, falling to 1/2 at the distance
+//     scale;
+//   - type: 1 for large airports down to 0 for closed ones;
+//   - routes: growing with the logarithm of the number of routes, up to 1
+//     at the routes scale;
+//   - passenger class: 1 for large, 0.6 for medium, 0.3 for small and 0.1
+//     for nonhub.
+//
+// Hubs without a type, route count or passenger class get 0 for that
+// factor.
+type WeightedScorer struct {
+	weights         Weights
+	distanceScaleKm float64
+	routesScale     int
+}
+
+// Compile-time check that WeightedScorer implements Scorer.
+var _ Scorer = (*WeightedScorer)(nil)
+
+type WeightedConfig struct {
+	// Weights sets how much each factor counts. Defaults to DefaultWeights
+	// if every weight is zero.
+	Weights Weights
+	// DistanceScaleKm is the distance at which the distance factor falls
+	// to 1/2. Defaults to 50.
+	DistanceScaleKm float64
+	// RoutesScale is the number of routes from which the routes factor is
+	// 1. Defaults to 200.
+	RoutesScale int
+}
+
+func NewWeightedScorer(cfg WeightedConfig) (*WeightedScorer, error) {
+	if err := cfg.Weights.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.DistanceScaleKm < 0 || math.IsNaN(cfg.DistanceScaleKm) {
+		return nil, fmt.Errorf("distance scale cannot be negative")
+	}
+	if cfg.RoutesScale < 0 {
+		return nil, fmt.Errorf("routes scale cannot be negative")
+	}
+
+	s := &WeightedScorer{
+		weights:         cfg.Weights,
+		distanceScaleKm: cfg.DistanceScaleKm,
+		routesScale:     cfg.RoutesScale,
+	}
+	if s.weights.sum() == 0 {
+		s.weights = DefaultWeights
+	}
+	if s.distanceScaleKm == 0 {
+		s.distanceScaleKm = defaultDistanceScaleKm
+	}
+	if s.routesScale == 0 {
+		s.routesScale = defaultRoutesScale
+	}
+	return s, nil
+}
+
+// typeValues rates the hub types of the OurAirports data set.
+var typeValues = map[string]float64{
+	"large_airport":  1,
+	"medium_airport": 0.6,
+	"small_airport":  0.3,
+	"seaplane_base":  0.2,
+	"heliport":       0.1,
+	"balloonport":    0.05,
+	"closed":         0,
+}
+
+var passengerClassValues = map[string]float64{
+	"large":  1,
+	"medium": 0.6,
+	"small":  0.3,
+	"nonhub": 0.1,
+}
+
+// Score implements Scorer. Factors with no weight are left out of the
+// breakdown.
+func (s *WeightedScorer) Score(_ geo.Point, hub model.HubWithDistance) (float64, []model.ScoreFactor) {
+	total := s.weights.sum()
+	factors := make([]model.ScoreFactor, 0, 4)
+	add := func(name string, weight, value float64) {
+		if weight > 0 {
+			factors = append(factors, model.ScoreFactor{Name: name, Value: value, Weight: weight / total})
+		}
+	}
+
+	add(FactorDistance, s.weights.Distance, s.distanceScaleKm/(s.distanceScaleKm+hub.DistanceKm))
+	add(FactorType, s.weights.Type, typeValues[hub.Type])
+	add(FactorRoutes, s.weights.Routes, math.Min(1, math.Log1p(float64(hub.Routes))/math.Log1p(float64(s.routesScale))))
+	add(FactorPassengerClass, s.weights.PassengerClass, passengerClassValues[strings.ToLower(strings.TrimSpace(hub.PassengerClass))])
+
+	var score float64
+	for _, factor := range factors {
+		score += factor.Value * factor.Weight
+	}
+	return score, factors
+}
@@ -0,0 +1,130 @@
+package finder
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Weights
+		wantErr bool
+	}{
+		{input: "distance=1", want: Weights{Distance: 1}},
+		{input: " distance = 2 , Passenger-Class=0.5,type=1,routes=0", want: Weights{Distance: 2, Type: 1, PassengerClass: 0.5}},
+		{input: "passenger_class=3", want: Weights{PassengerClass: 3}},
+		{input: "size=1", wantErr: true},
+		{input: "distance", wantErr: true},
+		{input: "distance=near", wantErr: true},
+		{input: "distance=-1", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseWeights(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %+v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightedScorer(t *testing.T) {
+	scorer, err := NewWeightedScorer(WeightedConfig{})
+	if err != nil {
+		t.Fatalf("NewWeightedScorer returned error: %v", err)
+	}
+
+	major := model.HubWithDistance{Hub: model.Hub{ID: "major", Type: "large_airport", Routes: 200, PassengerClass: "Large"}, DistanceKm: 50}
+	score, factors := scorer.Score(geo.Point{}, major)
+	want := []model.ScoreFactor{
+		{Name: FactorDistance, Value: 0.5, Weight: 0.5},
+		{Name: FactorType, Value: 1, Weight: 0.2},
+		{Name: FactorRoutes, Value: 1, Weight: 0.2},
+		{Name: FactorPassengerClass, Value: 1, Weight: 0.1},
+	}
+	if len(factors) != len(want) {
+		t.Fatalf("got factors %+v, want %+v", factors, want)
+	}
+	for i := range want {
+		if factors[i].Name != want[i].Name || math.Abs(factors[i].Value-want[i].Value) > 1e-9 || math.Abs(factors[i].Weight-want[i].Weight) > 1e-9 {
+			t.Errorf("factor %d: got %+v, want %+v", i, factors[i], want[i])
+		}
+	}
+	if math.Abs(score-0.75) > 1e-9 {
+		t.Errorf("got score %g, want 0.75", score)
+	}
+
+	airstrip := model.HubWithDistance{Hub: model.Hub{ID: "airstrip", Type: "small_airport"}, DistanceKm: 5}
+	if airstripScore, _ := scorer.Score(geo.Point{}, airstrip); airstripScore >= score {
+		t.Errorf("nearby airstrip scored %g, not below the major airport's %g", airstripScore, score)
+	}
+}
+
+func TestWeightedScorer_Weights(t *testing.T) {
+	scorer, err := NewWeightedScorer(WeightedConfig{Weights: Weights{Distance: 3, Routes: 1}, DistanceScaleKm: 10, RoutesScale: 9})
+	if err != nil {
+		t.Fatalf("NewWeightedScorer returned error: %v", err)
+	}
+
+	score, factors := scorer.Score(geo.Point{}, model.HubWithDistance{Hub: model.Hub{Routes: 99}, DistanceKm: 30})
+	if len(factors) != 2 || factors[0].Name != FactorDistance || factors[1].Name != FactorRoutes {
+		t.Fatalf("got factors %+v, want distance and routes", factors)
+	}
+	if factors[0].Weight != 0.75 || factors[0].Value != 0.25 || factors[1].Value != 1 {
+		t.Errorf("got factors %+v", factors)
+	}
+	if math.Abs(score-(0.75*0.25+0.25)) > 1e-9 {
+		t.Errorf("got score %g, want %g", score, 0.75*0.25+0.25)
+	}
+
+	for _, cfg := range []WeightedConfig{
+		{Weights: Weights{Type: -1}},
+		{Weights: Weights{Routes: math.NaN()}},
+		{DistanceScaleKm: -1},
+		{RoutesScale: -1},
+	} {
+		if _, err := NewWeightedScorer(cfg); err == nil {
+			t.Errorf("NewWeightedScorer(%+v) returned no error", cfg)
+		}
+	}
+}
+
+func TestSearch_WeightedScore(t *testing.T) {
+	repo := &mockRepository{hubs: []model.Hub{
+		{ID: "strip", Name: "Airstrip", Lat: 47.5, Lon: 19.05, Type: "small_airport"},
+		{ID: "major", Name: "Major", Lat: 47.5, Lon: 19.4, Type: "large_airport", Routes: 150, PassengerClass: "large"},
+	}}
+	scorer, err := NewWeightedScorer(WeightedConfig{})
+	if err != nil {
+		t.Fatalf("NewWeightedScorer returned error: %v", err)
+	}
+
+	result, err := New(repo, WithScorer(scorer)).Search(context.Background(), Query{Lat: 47.5, Lon: 19.0, RadiusKm: 50, SortBy: SortScore})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 2 || result.Hubs[0].ID != "major" {
+		t.Fatalf("expected the major airport first, got %v", result.Hubs)
+	}
+	for _, hub := range result.Hubs {
+		if len(hub.ScoreFactors) != 4 || hub.Score <= 0 {
+			t.Errorf("%s: got score %g with factors %+v", hub.ID, hub.Score, hub.ScoreFactors)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package finder
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/names"
+)
+
+// SortKey selects the order of search results.
+type SortKey string
+
+const (
+	// SortDistance orders hubs by distance, closest first.
+	SortDistance SortKey = "distance"
+	// SortTravelTime orders hubs by estimated travel time, quickest first.
+	// It requires a TravelEstimator.
+	SortTravelTime SortKey = "travel-time"
+	// SortName orders hubs alphabetically by name, ignoring case, accents
+	// and punctuation.
+	SortName SortKey = "name"
+	// SortBearing orders hubs by compass direction from the search point,
+	// clockwise from north.
+	SortBearing SortKey = "bearing"
+	// SortType orders hubs by type from the largest (large_airport) to the
+	// smallest, followed by unknown types alphabetically and then hubs
+	// without a type.
+	SortType SortKey = "type"
+	// SortScore orders hubs by score, highest first. It requires a Scorer.
+	SortScore SortKey = "score"
+)
+
+// SortKeys lists every SortKey.
+var SortKeys = []SortKey{SortDistance, SortTravelTime, SortName, SortBearing, SortType, SortScore}
+
+// Order is the direction of a sort.
+type Order string
+
+const (
+	// OrderDefault sorts in the key's natural direction, as described on
+	// each SortKey.
+	OrderDefault Order = ""
+	// OrderAscending sorts in the key's natural direction.
+	OrderAscending Order = "asc"
+	// OrderDescending sorts against the key's natural direction, such as
+	// farthest first or lowest score first.
+	OrderDescending Order = "desc"
+)
+
+// ParseSort parses a sort given as a key, optionally followed by a colon and
+// asc or desc, such as "name" or "distance:desc".
+func ParseSort(s string) (SortKey, Order, error) {
+	name, direction, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+	key := SortKey(name)
+	if !slices.Contains(SortKeys, key) {
+		return "", "", fmt.Errorf("%w: unknown key %q, must be one of %s", ErrInvalidSort, name, sortKeyList())
+	}
+	switch order := Order(direction); order {
+	case OrderDefault, OrderAscending, OrderDescending:
+		return key, order, nil
+	default:
+		return "", "", fmt.Errorf("%w: unknown direction %q, must be asc or desc", ErrInvalidSort, direction)
+	}
+}
+
+func sortKeyList() string {
+	keys := make([]string, len(SortKeys))
+	for i, key := range SortKeys {
+		keys[i] = string(key)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Scorer rates hubs for sorting by SortScore. Higher scores are better.
+// Score returns the score together with the factors it is made of, which
+// may be empty.
+type Scorer interface {
+	Score(origin geo.Point, hub model.HubWithDistance) (float64, []model.ScoreFactor)
+}
+
+// WithScorer makes searches fill in HubWithDistance.Score and ScoreFactors
+// using s, and allows sorting by score. See WeightedScorer for the default
+// formula.
+func WithScorer(s Scorer) Option {
+	return func(f *Finder) {
+		f.scorer = s
+	}
+}
+
+// resolveSort applies the defaults of a query's sort and checks that the
+// Finder can sort that way.
+func (f *Finder) resolveSort(key SortKey, order Order) (SortKey, bool, error) {
+	if key == "" {
+		key = SortDistance
+	}
+	if !slices.Contains(SortKeys, key) {
+		return "", false, fmt.Errorf("%w: unknown key %q, must be one of %s", ErrInvalidSort, key, sortKeyList())
+	}
+	var descending bool
+	switch order {
+	case OrderDefault, OrderAscending:
+	case OrderDescending:
+		descending = true
+	default:
+		return "", false, fmt.Errorf("%w: unknown direction %q, must be asc or desc", ErrInvalidSort, order)
+	}
+	switch {
+	case key == SortTravelTime && f.travel == nil:
+		return "", false, ErrNoTravelEstimator
+	case key == SortScore && f.scorer == nil:
+		return "", false, ErrNoScorer
+	}
+	// Scores are best highest, so their natural direction is descending.
+	if key == SortScore {
+		descending = !descending
+	}
+	return key, descending, nil
+}
+
+// annotate fills in the travel times and scores of hubs, for the Finder's
+// TravelEstimator and Scorer if it has them.
+func (f *Finder) annotate(ctx context.Context, lat, lon float64, hubs []model.HubWithDistance) error {
+	if err := f.estimateTravel(ctx, lat, lon, hubs); err != nil {
+		return err
+	}
+	if f.scorer != nil {
+		origin := geo.Point{Lat: lat, Lon: lon}
+		for i := range hubs {
+			hubs[i].Score, hubs[i].ScoreFactors = f.scorer.Score(origin, hubs[i])
+		}
+	}
+	return nil
+}
+
+// hubTypeRanks orders the hub types of the OurAirports data set from the
+// largest to the smallest.
+var hubTypeRanks = map[string]int{
+	"large_airport":  0,
+	"medium_airport": 1,
+	"small_airport":  2,
+	"seaplane_base":  3,
+	"heliport":       4,
+	"balloonport":    5,
+	"closed":         6,
+}
+
+// compareTypes orders hub types as described on SortType.
+func compareTypes(a, b string) int {
+	rank := func(t string) int {
+		if r, ok := hubTypeRanks[t]; ok {
+			return r
+		}
+		if t == "" {
+			return len(hubTypeRanks) + 1
+		}
+		return len(hubTypeRanks)
+	}
+	if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a, b)
+}
+
+// sortHubs orders hubs by key around origin, reversed if descending. Ties
+// are broken on distance and then ID, both ascending, so that pages line up
+// regardless of the order the repository returned the hubs in.
+func sortHubs(hubs []model.HubWithDistance, origin geo.Point, key SortKey, descending bool) {
+	type item struct {
+		hub     model.HubWithDistance
+		name    string
+		bearing float64
+	}
+	items := make([]item, len(hubs))
+	for i, hub := range hubs {
+		items[i].hub = hub
+		switch key {
+		case SortName:
+			items[i].name = names.Normalize(hub.Name)
+		case SortBearing:
+			items[i].bearing = geo.InitialBearing(origin.Lat, origin.Lon, hub.Lat, hub.Lon)
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b item) int {
+		var c int
+		switch key {
+		case SortDistance:
+			c = cmp.Compare(a.hub.DistanceKm, b.hub.DistanceKm)
+		case SortTravelTime:
+			c = cmp.Compare(a.hub.TravelTime, b.hub.TravelTime)
+		case SortName:
+			c = cmp.Or(cmp.Compare(a.name, b.name), cmp.Compare(a.hub.Name, b.hub.Name))
+		case SortBearing:
+			c = cmp.Compare(a.bearing, b.bearing)
+		case SortType:
+			c = compareTypes(a.hub.Type, b.hub.Type)
+		case SortScore:
+			c = cmp.Compare(a.hub.Score, b.hub.Score)
+		}
+		if descending {
+			c = -c
+		}
+		return cmp.Or(c,
+			cmp.Compare(a.hub.DistanceKm, b.hub.DistanceKm),
+			cmp.Compare(a.hub.ID, b.hub.ID),
+		)
+	})
+	for i := range items {
+		hubs[i] = items[i].hub
+	}
+}
@@ -0,0 +1,145 @@
+package finder
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantKey   SortKey
+		wantOrder Order
+		wantErr   bool
+	}{
+		{input: "distance", wantKey: SortDistance},
+		{input: " Name:DESC ", wantKey: SortName, wantOrder: OrderDescending},
+		{input: "bearing:asc", wantKey: SortBearing, wantOrder: OrderAscending},
+		{input: "travel-time", wantKey: SortTravelTime},
+		{input: "score", wantKey: SortScore},
+		{input: "size", wantErr: true},
+		{input: "name:up", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			key, order, err := ParseSort(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %s:%s", tt.input, key, order)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key != tt.wantKey || order != tt.wantOrder {
+				t.Errorf("got %s:%s, want %s:%s", key, order, tt.wantKey, tt.wantOrder)
+			}
+		})
+	}
+}
+
+// scoreByName scores hubs by the length of their name.
+type scoreByName struct{}
+
+func (scoreByName) Score(_ geo.Point, hub model.HubWithDistance) (float64, []model.ScoreFactor) {
+	return float64(len(hub.Name)), nil
+}
+
+// compassHubs returns hubs around (0, 0), with an equal-distance pair to
+// check the ID tie-break.
+func compassHubs() []model.Hub {
+	return []model.Hub{
+		{ID: "north", Name: "Nord", Lat: 0.1, Lon: 0, Type: "small_airport"},
+		{ID: "east", Name: "Est", Lat: 0, Lon: 0.2, Type: "large_airport"},
+		{ID: "south", Name: "Sud", Lat: -0.3, Lon: 0, Type: "heliport"},
+		{ID: "west-a", Name: "Ouest", Lat: 0, Lon: -0.4},
+		{ID: "west-b", Name: "ouest", Lat: 0, Lon: -0.4, Type: "spaceport"},
+		{ID: "northeast", Name: "Nord-Est", Lat: 0.5, Lon: 0.5, Type: "large_airport"},
+	}
+}
+
+func TestSearch_Sort(t *testing.T) {
+	tests := []struct {
+		key   SortKey
+		order Order
+		want  []string
+	}{
+		{SortDistance, OrderDefault, []string{"north", "east", "south", "west-a", "west-b", "northeast"}},
+		{SortDistance, OrderDescending, []string{"northeast", "west-a", "west-b", "south", "east", "north"}},
+		{SortName, OrderDefault, []string{"east", "north", "northeast", "west-a", "west-b", "south"}},
+		{SortName, OrderDescending, []string{"south", "west-b", "west-a", "northeast", "north", "east"}},
+		{SortBearing, OrderDefault, []string{"north", "northeast", "east", "south", "west-a", "west-b"}},
+		{SortType, OrderDefault, []string{"east", "northeast", "north", "south", "west-b", "west-a"}},
+		{SortType, OrderDescending, []string{"west-a", "west-b", "south", "north", "east", "northeast"}},
+		{SortScore, OrderDefault, []string{"northeast", "west-a", "west-b", "north", "east", "south"}},
+		{SortScore, OrderAscending, []string{"northeast", "west-a", "west-b", "north", "east", "south"}},
+		{SortScore, OrderDescending, []string{"east", "south", "north", "west-a", "west-b", "northeast"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.key)+":"+string(tt.order), func(t *testing.T) {
+			// Shuffle the repository so that only the sort decides the order.
+			hubs := compassHubs()
+			rand.Shuffle(len(hubs), func(i, j int) { hubs[i], hubs[j] = hubs[j], hubs[i] })
+			f := New(&mockRepository{hubs: hubs}, WithScorer(scoreByName{}))
+
+			result, err := f.Search(context.Background(), Query{Lat: 0, Lon: 0, RadiusKm: 100, SortBy: tt.key, Order: tt.order})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, hub := range result.Hubs {
+				got = append(got, hub.ID)
+				if hub.Score != float64(len(hub.Name)) {
+					t.Errorf("%s: got score %g, want %d", hub.ID, hub.Score, len(hub.Name))
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch_SortedPagesMatchFullSearch(t *testing.T) {
+	f := New(&mockRepository{hubs: gridHubs()})
+
+	for _, key := range []SortKey{SortDistance, SortName, SortBearing} {
+		query := Query{Lat: 47.52, Lon: 19.03, RadiusKm: 60, SortBy: key, Order: OrderDescending}
+		full, err := f.Search(context.Background(), query)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", key, err)
+		}
+
+		var paged []model.HubWithDistance
+		query.Limit = 23
+		for {
+			page, err := f.Search(context.Background(), query)
+			if err != nil {
+				t.Fatalf("%s: unexpected error at offset %d: %v", key, query.Offset, err)
+			}
+			paged = append(paged, page.Hubs...)
+			if !page.HasMore {
+				break
+			}
+			query.Offset = page.NextOffset
+		}
+
+		if len(paged) != len(full.Hubs) {
+			t.Fatalf("%s: paged search returned %d hubs, full search %d", key, len(paged), len(full.Hubs))
+		}
+		for i := range paged {
+			if paged[i].ID != full.Hubs[i].ID {
+				t.Fatalf("%s: position %d: paged %s, full %s", key, i, paged[i].ID, full.Hubs[i].ID)
+			}
+		}
+	}
+}
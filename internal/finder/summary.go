@@ -0,0 +1,187 @@
+package finder
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultHistogramBins = 10
+
+// MaxHistogramBins is the largest number of histogram bins Summarize accepts.
+const MaxHistogramBins = 1000
+
+// SummaryPercentiles lists the distance percentiles reported by Summarize.
+var SummaryPercentiles = []float64{25, 50, 75, 90, 95, 99}
+
+// Summary describes the hubs within the radius of a query without listing
+// them.
+type Summary struct {
+	Count int `json:"count"`
+	// ByCountry and ByType count the hubs per country and type, most
+	// common first. Hubs without a country or type are counted under an
+	// empty key.
+	ByCountry []GroupCount `json:"by_country"`
+	ByType    []GroupCount `json:"by_type"`
+	// Nearest and Farthest are nil when no hubs were found.
+	Nearest        *model.HubWithDistance `json:"nearest,omitempty"`
+	Farthest       *model.HubWithDistance `json:"farthest,omitempty"`
+	MeanDistanceKm float64                `json:"mean_distance_km"`
+	// Percentiles holds the distances at SummaryPercentiles, interpolated
+	// linearly between the closest ranks. It is empty when no hubs were
+	// found.
+	Percentiles []Percentile `json:"percentiles"`
+	// Histogram splits the radius into bins of equal width.
+	Histogram []HistogramBin `json:"histogram"`
+
+	// Warnings, Stale, SnapshotAge and SourceWarnings are as in Result.
+	Warnings       []repository.RowWarning    `json:"warnings,omitempty"`
+	Stale          bool                       `json:"stale,omitempty"`
+	SnapshotAge    time.Duration              `json:"snapshot_age_ns,omitempty"`
+	SourceWarnings []repository.SourceWarning `json:"source_warnings,omitempty"`
+}
+
+// GroupCount is the number of hubs sharing a country or type.
+type GroupCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Percentile is the distance within which Percent percent of the hubs lie.
+type Percentile struct {
+	Percent    float64 `json:"percent"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// HistogramBin counts the hubs from MinKm up to, but not including, MaxKm.
+// The last bin includes MaxKm.
+type HistogramBin struct {
+	MinKm float64 `json:"min_km"`
+	MaxKm float64 `json:"max_km"`
+	Count int     `json:"count"`
+}
+
+// Summarize finds every hub within q.RadiusKm of the query point, like
+// Search without a limit, and returns counts and distance statistics
+// instead of the hubs. The distance histogram has the given number of
+// bins, up to MaxHistogramBins; zero means 10. q.Limit, q.Offset and the
+// sort are ignored, and hubs are neither estimated nor scored.
+func (f *Finder) Summarize(ctx context.Context, q Query, bins int) (_ Summary, err error) {
+	lat, lon, radiusKm := q.Lat, q.Lon, q.RadiusKm
+	start := time.Now()
+
+	ctx, span := f.tracer.Start(ctx, "Finder.Summarize", trace.WithAttributes(
+		attribute.Float64("hubfinder.lat", lat),
+		attribute.Float64("hubfinder.lon", lon),
+		attribute.Float64("hubfinder.radius_km", radiusKm),
+	))
+	defer func() {
+		f.metrics.ObserveQuery("summarize", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	if bins < 0 || bins > MaxHistogramBins {
+		return Summary{}, fmt.Errorf("%w: %d", ErrInvalidBins, bins)
+	}
+	if bins == 0 {
+		bins = defaultHistogramBins
+	}
+
+	found, err := f.collectNearby(ctx, lat, lon, radiusKm)
+	if err != nil {
+		return Summary{}, err
+	}
+	summary := summarize(found.hubs, radiusKm, bins)
+	summary.Warnings = found.warnings
+	summary.Stale = found.stale
+	summary.SnapshotAge = found.snapshotAge
+	summary.SourceWarnings = found.sourceWarnings
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.candidate_count", found.candidates),
+		attribute.Int("hubfinder.result_count", summary.Count),
+	)
+	f.logger.InfoContext(ctx, "summary finished",
+		"lat", lat, "lon", lon, "radius_km", radiusKm,
+		"candidates", found.candidates,
+		"results", summary.Count,
+		"duration", time.Since(start),
+	)
+	return summary, nil
+}
+
+// summarize computes the statistics of hubs, which are sorted by distance
+// and lie within radiusKm.
+func summarize(hubs []model.HubWithDistance, radiusKm float64, bins int) Summary {
+	summary := Summary{
+		Count:       len(hubs),
+		Percentiles: []Percentile{},
+		Histogram:   make([]HistogramBin, bins),
+	}
+
+	width := radiusKm / float64(bins)
+	for i := range summary.Histogram {
+		summary.Histogram[i] = HistogramBin{MinKm: float64(i) * width, MaxKm: float64(i+1) * width}
+	}
+	summary.Histogram[bins-1].MaxKm = radiusKm
+
+	byCountry := make(map[string]int)
+	byType := make(map[string]int)
+	var total float64
+	for _, hub := range hubs {
+		byCountry[hub.Country]++
+		byType[hub.Type]++
+		total += hub.DistanceKm
+
+		bin := bins - 1
+		if width > 0 {
+			bin = min(int(hub.DistanceKm/width), bins-1)
+		}
+		summary.Histogram[bin].Count++
+	}
+	summary.ByCountry = groupCounts(byCountry)
+	summary.ByType = groupCounts(byType)
+
+	if len(hubs) == 0 {
+		return summary
+	}
+	summary.Nearest = &hubs[0]
+	summary.Farthest = &hubs[len(hubs)-1]
+	summary.MeanDistanceKm = total / float64(len(hubs))
+	for _, p := range SummaryPercentiles {
+		summary.Percentiles = append(summary.Percentiles, Percentile{Percent: p, DistanceKm: percentile(hubs, p)})
+	}
+	return summary
+}
+
+// groupCounts orders counts from the most to the least common, breaking
+// ties on the key.
+func groupCounts(counts map[string]int) []GroupCount {
+	groups := make([]GroupCount, 0, len(counts))
+	for key, count := range counts {
+		groups = append(groups, GroupCount{Key: key, Count: count})
+	}
+	slices.SortFunc(groups, func(a, b GroupCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Key, b.Key))
+	})
+	return groups
+}
+
+// percentile returns the p-th percentile of the distances of hubs, which
+// are sorted by distance, interpolating between the closest ranks.
+func percentile(hubs []model.HubWithDistance, p float64) float64 {
+	rank := p / 100 * float64(len(hubs)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	fraction := rank - float64(lower)
+	return hubs[lower].DistanceKm + fraction*(hubs[upper].DistanceKm-hubs[lower].DistanceKm)
+}
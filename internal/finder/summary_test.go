@@ -0,0 +1,132 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+func TestSummarize_Statistics(t *testing.T) {
+	hubs := []model.HubWithDistance{
+		{Hub: model.Hub{ID: "a", Country: "HU", Type: "large_airport"}, DistanceKm: 5},
+		{Hub: model.Hub{ID: "b", Country: "HU", Type: "small_airport"}, DistanceKm: 15},
+		{Hub: model.Hub{ID: "c", Country: "AT", Type: "small_airport"}, DistanceKm: 25},
+		{Hub: model.Hub{ID: "d", Type: "heliport"}, DistanceKm: 40},
+		{Hub: model.Hub{ID: "e", Country: "SK", Type: "small_airport"}, DistanceKm: 100},
+	}
+
+	got := summarize(hubs, 100, 4)
+
+	if got.Count != 5 {
+		t.Errorf("got count %d, want 5", got.Count)
+	}
+	if got.Nearest == nil || got.Nearest.ID != "a" {
+		t.Errorf("got nearest %v, want a", got.Nearest)
+	}
+	if got.Farthest == nil || got.Farthest.ID != "e" {
+		t.Errorf("got farthest %v, want e", got.Farthest)
+	}
+	if got.MeanDistanceKm != 37 {
+		t.Errorf("got mean %v km, want 37 km", got.MeanDistanceKm)
+	}
+
+	wantCountries := []GroupCount{{"HU", 2}, {"", 1}, {"AT", 1}, {"SK", 1}}
+	if !slices.Equal(got.ByCountry, wantCountries) {
+		t.Errorf("got countries %v, want %v", got.ByCountry, wantCountries)
+	}
+	wantTypes := []GroupCount{{"small_airport", 3}, {"heliport", 1}, {"large_airport", 1}}
+	if !slices.Equal(got.ByType, wantTypes) {
+		t.Errorf("got types %v, want %v", got.ByType, wantTypes)
+	}
+
+	wantPercentiles := map[float64]float64{25: 15, 50: 25, 75: 40, 90: 76, 95: 88, 99: 97.6}
+	if len(got.Percentiles) != len(SummaryPercentiles) {
+		t.Fatalf("got %d percentiles, want %d", len(got.Percentiles), len(SummaryPercentiles))
+	}
+	for _, p := range got.Percentiles {
+		if want := wantPercentiles[p.Percent]; math.Abs(p.DistanceKm-want) > 1e-9 {
+			t.Errorf("got p%v %v km, want %v km", p.Percent, p.DistanceKm, want)
+		}
+	}
+
+	wantHistogram := []HistogramBin{
+		{MinKm: 0, MaxKm: 25, Count: 2},
+		{MinKm: 25, MaxKm: 50, Count: 2},
+		{MinKm: 50, MaxKm: 75, Count: 0},
+		{MinKm: 75, MaxKm: 100, Count: 1},
+	}
+	if !slices.Equal(got.Histogram, wantHistogram) {
+		t.Errorf("got histogram %v, want %v", got.Histogram, wantHistogram)
+	}
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	got := summarize(nil, 50, 5)
+
+	if got.Count != 0 || got.Nearest != nil || got.Farthest != nil || got.MeanDistanceKm != 0 {
+		t.Errorf("got %+v, want an empty summary", got)
+	}
+	if len(got.Percentiles) != 0 || len(got.ByCountry) != 0 || len(got.ByType) != 0 {
+		t.Errorf("got percentiles %v and groups %v %v, want none", got.Percentiles, got.ByCountry, got.ByType)
+	}
+	if len(got.Histogram) != 5 {
+		t.Fatalf("got %d bins, want 5", len(got.Histogram))
+	}
+	for _, bin := range got.Histogram {
+		if bin.Count != 0 {
+			t.Errorf("got bin %v, want no hubs", bin)
+		}
+	}
+}
+
+func TestFinder_Summarize(t *testing.T) {
+	f := New(&mockRepository{hubs: gridHubs()}, WithScorer(scoreByName{}))
+	q := Query{Lat: 47.5, Lon: 19.0, RadiusKm: 30, Limit: 3, Offset: 2, SortBy: SortName}
+
+	got, err := f.Summarize(context.Background(), q, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	all, err := f.Search(context.Background(), Query{Lat: 47.5, Lon: 19.0, RadiusKm: 30})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Count != len(all.Hubs) {
+		t.Errorf("got count %d, want %d", got.Count, len(all.Hubs))
+	}
+	if len(got.Histogram) != defaultHistogramBins {
+		t.Errorf("got %d bins, want %d", len(got.Histogram), defaultHistogramBins)
+	}
+	var binned int
+	for _, bin := range got.Histogram {
+		binned += bin.Count
+	}
+	if binned != got.Count {
+		t.Errorf("got %d hubs in the histogram, want %d", binned, got.Count)
+	}
+	if got.Nearest.ID != all.Hubs[0].ID || got.Farthest.ID != all.Hubs[len(all.Hubs)-1].ID {
+		t.Errorf("got nearest %s and farthest %s, want %s and %s",
+			got.Nearest.ID, got.Farthest.ID, all.Hubs[0].ID, all.Hubs[len(all.Hubs)-1].ID)
+	}
+	if got.Nearest.Score != 0 {
+		t.Errorf("got nearest hub scored %v, want summaries not to score hubs", got.Nearest.Score)
+	}
+}
+
+func TestFinder_SummarizeErrors(t *testing.T) {
+	f := New(&mockRepository{hubs: gridHubs()})
+
+	for _, bins := range []int{-1, MaxHistogramBins + 1} {
+		if _, err := f.Summarize(context.Background(), Query{Lat: 47.5, Lon: 19.0, RadiusKm: 30}, bins); !errors.Is(err, ErrInvalidBins) {
+			t.Errorf("got %v for %d bins, want ErrInvalidBins", err, bins)
+		}
+	}
+	if _, err := f.Summarize(context.Background(), Query{Lat: 91, Lon: 19.0, RadiusKm: 30}, 0); !IsInvalidArgument(err) {
+		t.Errorf("got %v, want an invalid argument error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// TravelEstimator estimates how long it takes to get from origin to each of
+// hubs. It returns one duration per hub, in the same order.
+type TravelEstimator interface {
+	EstimateTravel(ctx context.Context, origin geo.Point, hubs []model.HubWithDistance) ([]time.Duration, error)
+}
+
+// WithTravelEstimator makes searches fill in HubWithDistance.TravelTime
+// using e, and allows sorting by it.
+func WithTravelEstimator(e TravelEstimator) Option {
+	return func(f *Finder) {
+		f.travel = e
+	}
+}
+
+// Profile describes how quickly a mode of transport covers ground.
+type Profile struct {
+	Name string
+	// SpeedKmh is the average speed along the route.
+	SpeedKmh float64
+	// DetourFactor is the ratio of the route length to the straight-line
+	// distance.
+	DetourFactor float64
+	// Overhead is a fixed time added to every trip, such as waiting for a
+	// train.
+	Overhead time.Duration
+}
+
+// Built-in profiles for rough estimates.
+var (
+	ProfileCar     = Profile{Name: "car", SpeedKmh: 60, DetourFactor: 1.3}
+	ProfileRail    = Profile{Name: "rail", SpeedKmh: 80, DetourFactor: 1.2, Overhead: 20 * time.Minute}
+	ProfileWalking = Profile{Name: "walking", SpeedKmh: 5, DetourFactor: 1.3}
+)
+
+// Profiles lists the built-in profiles.
+var Profiles = []Profile{ProfileCar, ProfileRail, ProfileWalking}
+
+// ParseProfile returns the built-in profile with the given name.
+func ParseProfile(name string) (Profile, error) {
+	for _, p := range Profiles {
+		if strings.EqualFold(strings.TrimSpace(name), p.Name) {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("invalid travel mode %q: must be car, rail or walking", name)
+}
+
+// Validate checks that the speed is positive and the detour factor at
+// least 1.
+func (p Profile) Validate() error {
+	switch {
+	case !(p.SpeedKmh > 0):
+		return fmt.Errorf("profile %s: speed must be positive", p.Name)
+	case !(p.DetourFactor >= 1):
+		return fmt.Errorf("profile %s: detour factor must be at least 1", p.Name)
+	case p.Overhead < 0:
+		return fmt.Errorf("profile %s: overhead cannot be negative", p.Name)
+	}
+	return nil
+}
+
+// Duration estimates the time to travel distanceKm in a straight line.
+func (p Profile) Duration(distanceKm float64) time.Duration {
+	hours := distanceKm * p.DetourFactor / p.SpeedKmh
+	return p.Overhead + time.Duration(hours*float64(time.Hour)).Round(time.Second)
+}
+
+// EstimateTravel implements TravelEstimator from the straight-line
+// distances alone.
+func (p Profile) EstimateTravel(_ context.Context, _ geo.Point, hubs []model.HubWithDistance) ([]time.Duration, error) {
+	durations := make([]time.Duration, len(hubs))
+	for i, hub := range hubs {
+		durations[i] = p.Duration(hub.DistanceKm)
+	}
+	return durations, nil
+}
+
+// estimateTravel fills in the travel times of hubs, if the Finder has a
+// TravelEstimator.
+func (f *Finder) estimateTravel(ctx context.Context, lat, lon float64, hubs []model.HubWithDistance) error {
+	if f.travel == nil || len(hubs) == 0 {
+		return nil
+	}
+	durations, err := f.travel.EstimateTravel(ctx, geo.Point{Lat: lat, Lon: lon}, hubs)
+	if err != nil {
+		return fmt.Errorf("estimate travel times: %w", err)
+	}
+	if len(durations) != len(hubs) {
+		return fmt.Errorf("estimate travel times: got %d durations for %d hubs", len(durations), len(hubs))
+	}
+	for i := range hubs {
+		hubs[i].TravelTime = durations[i]
+	}
+	return nil
+}
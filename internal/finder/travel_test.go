@@ -0,0 +1,127 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// stubEstimator returns fixed travel times by hub ID and records the hubs
+// it was asked about.
+type stubEstimator struct {
+	durations map[string]time.Duration
+	err       error
+	asked     []string
+}
+
+func (s *stubEstimator) EstimateTravel(_ context.Context, _ geo.Point, hubs []model.HubWithDistance) ([]time.Duration, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	durations := make([]time.Duration, len(hubs))
+	for i, hub := range hubs {
+		s.asked = append(s.asked, hub.ID)
+		durations[i] = s.durations[hub.ID]
+	}
+	return durations, nil
+}
+
+func TestProfile_Duration(t *testing.T) {
+	tests := []struct {
+		profile    Profile
+		distanceKm float64
+		want       time.Duration
+	}{
+		{ProfileCar, 0, 0},
+		{ProfileCar, 60, 78 * time.Minute},
+		{ProfileRail, 80, 20*time.Minute + 72*time.Minute},
+		{ProfileWalking, 5, 78 * time.Minute},
+		{Profile{Name: "custom", SpeedKmh: 100, DetourFactor: 1}, 50, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tt.profile.Duration(tt.distanceKm); got != tt.want {
+			t.Errorf("%s.Duration(%g) = %v, want %v", tt.profile.Name, tt.distanceKm, got, tt.want)
+		}
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	for _, name := range []string{"car", "Rail", " walking "} {
+		if _, err := ParseProfile(name); err != nil {
+			t.Errorf("ParseProfile(%q) returned error: %v", name, err)
+		}
+	}
+	if _, err := ParseProfile("bicycle"); err == nil {
+		t.Error("ParseProfile(bicycle) returned no error")
+	}
+
+	for _, p := range []Profile{
+		{Name: "still", SpeedKmh: 0, DetourFactor: 1},
+		{Name: "shortcut", SpeedKmh: 50, DetourFactor: 0.9},
+		{Name: "early", SpeedKmh: 50, DetourFactor: 1, Overhead: -time.Minute},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: Validate returned no error", p.Name)
+		}
+	}
+}
+
+func TestSearch_TravelTime(t *testing.T) {
+	repo := &mockRepository{hubs: []model.Hub{
+		{ID: "a", Name: "A", Lat: 47.5, Lon: 19.01},
+		{ID: "b", Name: "B", Lat: 47.5, Lon: 19.02},
+		{ID: "c", Name: "C", Lat: 47.5, Lon: 19.03},
+		{ID: "d", Name: "D", Lat: 47.5, Lon: 19.04},
+	}}
+	durations := map[string]time.Duration{"a": 30 * time.Minute, "b": 10 * time.Minute, "c": 20 * time.Minute, "d": 10 * time.Minute}
+
+	tests := []struct {
+		name      string
+		query     Query
+		want      []string
+		wantAsked []string
+	}{
+		{"by distance", Query{Lat: 47.5, Lon: 19.0, RadiusKm: 10}, []string{"a", "b", "c", "d"}, []string{"a", "b", "c", "d"}},
+		{"by distance estimates the page only", Query{Lat: 47.5, Lon: 19.0, RadiusKm: 10, Limit: 2, Offset: 1}, []string{"b", "c"}, []string{"b", "c"}},
+		{"by travel time", Query{Lat: 47.5, Lon: 19.0, RadiusKm: 10, SortBy: SortTravelTime}, []string{"b", "d", "c", "a"}, []string{"a", "b", "c", "d"}},
+		{"by travel time paged", Query{Lat: 47.5, Lon: 19.0, RadiusKm: 10, Limit: 2, Offset: 1, SortBy: SortTravelTime}, []string{"d", "c"}, []string{"a", "b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimator := &stubEstimator{durations: durations}
+			result, err := New(repo, WithTravelEstimator(estimator)).Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, hub := range result.Hubs {
+				got = append(got, hub.ID)
+				if hub.TravelTime != durations[hub.ID] {
+					t.Errorf("%s: got travel time %v, want %v", hub.ID, hub.TravelTime, durations[hub.ID])
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got hubs %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(estimator.asked, tt.wantAsked) {
+				t.Errorf("estimated %v, want %v", estimator.asked, tt.wantAsked)
+			}
+		})
+	}
+}
+
+func TestSearch_TravelEstimatorError(t *testing.T) {
+	repo := &mockRepository{hubs: []model.Hub{{ID: "a", Name: "A", Lat: 47.5, Lon: 19.01}}}
+	estimatorErr := errors.New("routing engine unavailable")
+	f := New(repo, WithTravelEstimator(&stubEstimator{err: estimatorErr}))
+
+	if _, err := f.Search(context.Background(), Query{Lat: 47.5, Lon: 19.0, RadiusKm: 10}); !errors.Is(err, estimatorErr) {
+		t.Errorf("got error %v, want %v", err, estimatorErr)
+	}
+}
@@ -1,10 +1,18 @@
 package geo
 
 import (
-	"fmt"
+	"errors"
 	"math"
 )
 
+// Validation errors returned for out-of-range input. Callers can test for
+// them with errors.Is.
+var (
+	ErrInvalidLatitude  = errors.New("latitude must be between -90 and 90 degrees")
+	ErrInvalidLongitude = errors.New("longitude must be between -180 and 180 degrees")
+	ErrNegativeRadius   = errors.New("radius cannot be negative")
+)
+
 const (
 	EarthRadiusKm = 6371.0
 
@@ -14,18 +22,30 @@ const (
 	maxLonRad = math.Pi
 )
 
+// ValidateCoordinates checks that lat and lon are valid coordinates in
+// degrees, reporting ErrInvalidLatitude or ErrInvalidLongitude otherwise.
+func ValidateCoordinates(lat, lon float64) error {
+	if lat < -90 || lat > 90 || math.IsNaN(lat) {
+		return ErrInvalidLatitude
+	}
+	if lon < -180 || lon > 180 || math.IsNaN(lon) {
+		return ErrInvalidLongitude
+	}
+	return nil
+}
+
 // CalculateBoundingBox calculates the bounding box for a circle defined by a center point
 // (lat, lon in degrees) and a radius in kilometers.
 // It returns the minimum and maximum latitudes and longitudes that define the bounding box.
 func CalculateBoundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64, err error) {
 	if radiusKm < 0 {
-		return 0, 0, 0, 0, fmt.Errorf("radius cannot be negative")
+		return 0, 0, 0, 0, ErrNegativeRadius
 	}
 	if lat < -90 || lat > 90 {
-		return 0, 0, 0, 0, fmt.Errorf("latitude must be between -90 and 90 degrees")
+		return 0, 0, 0, 0, ErrInvalidLatitude
 	}
 	if lon < -180 || lon > 180 {
-		return 0, 0, 0, 0, fmt.Errorf("longitude must be between -180 and 180 degrees")
+		return 0, 0, 0, 0, ErrInvalidLongitude
 	}
 
 	latRad := degToRad(lat)
@@ -62,10 +82,10 @@ func CalculateBoundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, m
 // Source: https://www.movable-type.co.uk/scripts/latlong.html
 func HaversineDistance(lat1, lon1, lat2, lon2 float64) (float64, error) {
 	if lat1 < -90 || lat1 > 90 || lat2 < -90 || lat2 > 90 {
-		return 0, fmt.Errorf("latitude must be between -90 and 90 degrees")
+		return 0, ErrInvalidLatitude
 	}
 	if lon1 < -180 || lon1 > 180 || lon2 < -180 || lon2 > 180 {
-		return 0, fmt.Errorf("longitude must be between -180 and 180 degrees")
+		return 0, ErrInvalidLongitude
 	}
 
 	lat1Rad := degToRad(lat1)
@@ -84,6 +104,25 @@ func HaversineDistance(lat1, lon1, lat2, lon2 float64) (float64, error) {
 	return EarthRadiusKm * c, nil
 }
 
+// InitialBearing returns the compass direction in degrees, from 0 (north)
+// clockwise to just under 360, in which the great-circle path from
+// (lat1, lon1) to (lat2, lon2) sets off. It is 0 for identical points. The
+// coordinates are assumed to be valid.
+// Source: https://www.movable-type.co.uk/scripts/latlong.html
+func InitialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	lat1Rad := degToRad(lat1)
+	lat2Rad := degToRad(lat2)
+	deltaLon := degToRad(lon2 - lon1)
+
+	y := math.Sin(deltaLon) * math.Cos(lat2Rad)
+	x := math.Cos(lat1Rad)*math.Sin(lat2Rad) - math.Sin(lat1Rad)*math.Cos(lat2Rad)*math.Cos(deltaLon)
+	bearing := math.Mod(radToDeg(math.Atan2(y, x))+360, 360)
+	if bearing >= 360 {
+		bearing = 0
+	}
+	return bearing
+}
+
 func degToRad(deg float64) float64 {
 	return deg * math.Pi / 180
 }
@@ -1,6 +1,7 @@
 package geo
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"testing"
@@ -555,22 +556,23 @@ func TestHaversineDistanceValidation(t *testing.T) {
 		name       string
 		lat1, lon1 float64
 		lat2, lon2 float64
+		expected   error
 	}{
-		{"lat1 too high", 91, 0, 0, 0},
-		{"lat1 too low", -91, 0, 0, 0},
-		{"lat2 too high", 0, 0, 91, 0},
-		{"lat2 too low", 0, 0, -91, 0},
-		{"lon1 too high", 0, 181, 0, 0},
-		{"lon1 too low", 0, -181, 0, 0},
-		{"lon2 too high", 0, 0, 0, 181},
-		{"lon2 too low", 0, 0, 0, -181},
+		{"lat1 too high", 91, 0, 0, 0, ErrInvalidLatitude},
+		{"lat1 too low", -91, 0, 0, 0, ErrInvalidLatitude},
+		{"lat2 too high", 0, 0, 91, 0, ErrInvalidLatitude},
+		{"lat2 too low", 0, 0, -91, 0, ErrInvalidLatitude},
+		{"lon1 too high", 0, 181, 0, 0, ErrInvalidLongitude},
+		{"lon1 too low", 0, -181, 0, 0, ErrInvalidLongitude},
+		{"lon2 too high", 0, 0, 0, 181, ErrInvalidLongitude},
+		{"lon2 too low", 0, 0, 0, -181, ErrInvalidLongitude},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := HaversineDistance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
-			if err == nil {
-				t.Error("expected error, got nil")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("got error %v, want %v", err, tt.expected)
 			}
 		})
 	}
@@ -581,19 +583,69 @@ func TestCalculateBoundingBoxValidation(t *testing.T) {
 		name     string
 		lat, lon float64
 		radiusKm float64
+		expected error
 	}{
-		{"Negative radius", 40.0, -74.0, -10},
-		{"Latitude too high", 91.0, 0.0, 50},
-		{"Latitude too low", -91.0, 0.0, 50},
-		{"Longitude too high", 0.0, 181.0, 50},
-		{"Longitude too low", 0.0, -181.0, 50},
+		{"Negative radius", 40.0, -74.0, -10, ErrNegativeRadius},
+		{"Latitude too high", 91.0, 0.0, 50, ErrInvalidLatitude},
+		{"Latitude too low", -91.0, 0.0, 50, ErrInvalidLatitude},
+		{"Longitude too high", 0.0, 181.0, 50, ErrInvalidLongitude},
+		{"Longitude too low", 0.0, -181.0, 50, ErrInvalidLongitude},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			_, _, _, _, err := CalculateBoundingBox(tt.lat, tt.lon, tt.radiusKm)
-			if err == nil {
-				t.Error("Expected error, got nil")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("got error %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		expected error
+	}{
+		{"Valid", 47.5, 19.0, nil},
+		{"Corners", -90, 180, nil},
+		{"Latitude too high", 90.5, 0, ErrInvalidLatitude},
+		{"Latitude NaN", math.NaN(), 0, ErrInvalidLatitude},
+		{"Longitude too low", 0, -180.5, ErrInvalidLongitude},
+		{"Longitude NaN", 0, math.NaN(), ErrInvalidLongitude},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCoordinates(tt.lat, tt.lon)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("got error %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInitialBearing(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{"Same point", 47.5, 19.0, 47.5, 19.0, 0},
+		{"Due north", 0, 0, 10, 0, 0},
+		{"Due east on the equator", 0, 0, 0, 10, 90},
+		{"Due south", 10, 0, 0, 0, 180},
+		{"Due west on the equator", 0, 0, 0, -10, 270},
+		{"Across the date line", 0, 179, 0, -179, 90},
+		{"Budapest to London", 47.4979, 19.0402, 51.5074, -0.1278, 295.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InitialBearing(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if !floatEquals(got, tt.expected, 0.5) {
+				t.Errorf("InitialBearing(%g, %g, %g, %g) = %f, want %f", tt.lat1, tt.lon1, tt.lat2, tt.lon2, got, tt.expected)
 			}
 		})
 	}
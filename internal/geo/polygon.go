@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidPolygon is returned for polygons with too few vertices.
+var ErrInvalidPolygon = errors.New("invalid polygon")
+
+// Point is a position in degrees.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// ValidatePolygon checks that polygon has at least three vertices with valid
+// coordinates. Polygons are treated as planar in latitude and longitude and
+// must not cross the antimeridian. Out-of-range vertices are reported with
+// ErrInvalidLatitude or ErrInvalidLongitude.
+func ValidatePolygon(polygon []Point) error {
+	if len(polygon) < 3 {
+		return fmt.Errorf("%w: must have at least 3 vertices, got %d", ErrInvalidPolygon, len(polygon))
+	}
+	for i, p := range polygon {
+		if p.Lat < -90 || p.Lat > 90 || math.IsNaN(p.Lat) {
+			return fmt.Errorf("vertex %d: %w", i, ErrInvalidLatitude)
+		}
+		if p.Lon < -180 || p.Lon > 180 || math.IsNaN(p.Lon) {
+			return fmt.Errorf("vertex %d: %w", i, ErrInvalidLongitude)
+		}
+	}
+	return nil
+}
+
+// PolygonBounds returns the bounding box of a validated polygon.
+func PolygonBounds(polygon []Point) (minLat, maxLat, minLon, maxLon float64) {
+	minLat, maxLat = math.Inf(1), math.Inf(-1)
+	minLon, maxLon = math.Inf(1), math.Inf(-1)
+	for _, p := range polygon {
+		minLat = math.Min(minLat, p.Lat)
+		maxLat = math.Max(maxLat, p.Lat)
+		minLon = math.Min(minLon, p.Lon)
+		maxLon = math.Max(maxLon, p.Lon)
+	}
+	return minLat, maxLat, minLon, maxLon
+}
+
+// PointInPolygon reports whether (lat, lon) lies inside polygon or on its
+// boundary, using the even-odd ray casting rule.
+func PointInPolygon(lat, lon float64, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if onSegment(lat, lon, a, b) {
+			return true
+		}
+		if (a.Lat > lat) != (b.Lat > lat) {
+			crossLon := a.Lon + (lat-a.Lat)*(b.Lon-a.Lon)/(b.Lat-a.Lat)
+			if lon < crossLon {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+func onSegment(lat, lon float64, a, b Point) bool {
+	const epsilon = 1e-12
+	cross := (b.Lon-a.Lon)*(lat-a.Lat) - (b.Lat-a.Lat)*(lon-a.Lon)
+	if math.Abs(cross) > epsilon {
+		return false
+	}
+	return lat >= math.Min(a.Lat, b.Lat)-epsilon && lat <= math.Max(a.Lat, b.Lat)+epsilon &&
+		lon >= math.Min(a.Lon, b.Lon)-epsilon && lon <= math.Max(a.Lon, b.Lon)+epsilon
+}
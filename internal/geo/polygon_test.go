@@ -0,0 +1,64 @@
+package geo
+
+import (
+	"errors"
+	"testing"
+)
+
+var square = []Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}}
+
+func TestValidatePolygon(t *testing.T) {
+	tests := []struct {
+		name     string
+		polygon  []Point
+		expected error
+	}{
+		{"valid square", square, nil},
+		{"too few vertices", []Point{{0, 0}, {1, 1}}, ErrInvalidPolygon},
+		{"latitude out of range", []Point{{0, 0}, {91, 0}, {0, 1}}, ErrInvalidLatitude},
+		{"longitude out of range", []Point{{0, 0}, {1, 181}, {0, 1}}, ErrInvalidLongitude},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePolygon(tt.polygon)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("ValidatePolygon() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPolygonBounds(t *testing.T) {
+	minLat, maxLat, minLon, maxLon := PolygonBounds([]Point{{-5, 3}, {7, -2}, {1, 9}})
+	if minLat != -5 || maxLat != 7 || minLon != -2 || maxLon != 9 {
+		t.Errorf("got (%g, %g, %g, %g), want (-5, 7, -2, 9)", minLat, maxLat, minLon, maxLon)
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	concave := []Point{{0, 0}, {10, 0}, {10, 10}, {5, 5}, {0, 10}}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		polygon  []Point
+		expected bool
+	}{
+		{"centre of square", 5, 5, square, true},
+		{"outside square", 15, 5, square, false},
+		{"on edge", 0, 5, square, true},
+		{"on vertex", 10, 10, square, true},
+		{"inside concave part", 2, 5, concave, true},
+		{"in concave notch", 5, 8, concave, false},
+		{"left of polygon on vertex latitude", 5, -1, concave, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInPolygon(tt.lat, tt.lon, tt.polygon); got != tt.expected {
+				t.Errorf("PointInPolygon(%g, %g) = %v, want %v", tt.lat, tt.lon, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+// Package grpcserver exposes a finder.Finder over the hubfinder.v1 gRPC API.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/api/hubfinderv1"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Server implements hubfinderv1.HubFinderServiceServer.
+type Server struct {
+	hubfinderv1.UnimplementedHubFinderServiceServer
+
+	finder         *finder.Finder
+	logger         *slog.Logger
+	defaultTimeout time.Duration
+}
+
+// Option configures optional Server behaviour.
+type Option func(*Server)
+
+// WithLogger sets the logger used for failed calls. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithDefaultTimeout bounds calls whose client did not set a deadline. Zero,
+// the default, leaves such calls unbounded.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.defaultTimeout = d
+	}
+}
+
+func New(f *finder.Finder, opts ...Option) *Server {
+	s := &Server{finder: f}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	s.logger = s.logger.With("component", "grpcserver")
+	return s
+}
+
+// Register adds the service to gs.
+func (s *Server) Register(gs *grpc.Server) {
+	hubfinderv1.RegisterHubFinderServiceServer(gs, s)
+}
+
+func (s *Server) FindNearby(ctx context.Context, req *hubfinderv1.FindNearbyRequest) (*hubfinderv1.FindNearbyResponse, error) {
+	ctx, cancel := s.withDeadline(ctx)
+	defer cancel()
+
+	if err := validateCenter(req.GetCenter()); err != nil {
+		return nil, err
+	}
+	if err := validateRadius("radius_km", req.GetRadiusKm()); err != nil {
+		return nil, err
+	}
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset cannot be negative")
+	}
+	sortBy, order, err := parseSort(req.GetSort())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.finder.Search(ctx, finder.Query{
+		Lat:      req.GetCenter().GetLat(),
+		Lon:      req.GetCenter().GetLon(),
+		RadiusKm: req.GetRadiusKm(),
+		Limit:    int(req.GetLimit()),
+		Offset:   int(req.GetOffset()),
+		SortBy:   sortBy,
+		Order:    order,
+	})
+	if err != nil {
+		return nil, s.toStatus(ctx, "FindNearby", err)
+	}
+
+	return &hubfinderv1.FindNearbyResponse{
+		Hubs:           toNearbyHubs(result.Hubs),
+		Warnings:       toWarnings(result.Warnings),
+		HasMore:        result.HasMore,
+		NextOffset:     int32(result.NextOffset),
+		Stale:          result.Stale,
+		SnapshotAge:    snapshotAge(result.Stale, result.SnapshotAge),
+		SourceWarnings: toSourceWarnings(result.SourceWarnings),
+	}, nil
+}
+
+// StreamNearby sends a staleness notice if the hubs come from a snapshot and
+// any row warnings first, followed by one message per hub in the requested
+// order.
+func (s *Server) StreamNearby(req *hubfinderv1.StreamNearbyRequest, stream grpc.ServerStreamingServer[hubfinderv1.StreamNearbyResponse]) error {
+	ctx, cancel := s.withDeadline(stream.Context())
+	defer cancel()
+
+	if err := validateCenter(req.GetCenter()); err != nil {
+		return err
+	}
+	if err := validateRadius("radius_km", req.GetRadiusKm()); err != nil {
+		return err
+	}
+	sortBy, order, err := parseSort(req.GetSort())
+	if err != nil {
+		return err
+	}
+
+	result, err := s.finder.Search(ctx, finder.Query{
+		Lat:      req.GetCenter().GetLat(),
+		Lon:      req.GetCenter().GetLon(),
+		RadiusKm: req.GetRadiusKm(),
+		SortBy:   sortBy,
+		Order:    order,
+	})
+	if err != nil {
+		return s.toStatus(ctx, "StreamNearby", err)
+	}
+
+	if result.Stale {
+		if err := stream.Send(&hubfinderv1.StreamNearbyResponse{
+			Item: &hubfinderv1.StreamNearbyResponse_Stale{Stale: &hubfinderv1.Staleness{
+				SnapshotAge: durationpb.New(result.SnapshotAge),
+			}},
+		}); err != nil {
+			return err
+		}
+	}
+	for _, warning := range toSourceWarnings(result.SourceWarnings) {
+		if err := stream.Send(&hubfinderv1.StreamNearbyResponse{
+			Item: &hubfinderv1.StreamNearbyResponse_SourceWarning{SourceWarning: warning},
+		}); err != nil {
+			return err
+		}
+	}
+	for _, warning := range toWarnings(result.Warnings) {
+		if err := stream.Send(&hubfinderv1.StreamNearbyResponse{
+			Item: &hubfinderv1.StreamNearbyResponse_Warning{Warning: warning},
+		}); err != nil {
+			return err
+		}
+	}
+	for _, hub := range toNearbyHubs(result.Hubs) {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(&hubfinderv1.StreamNearbyResponse{
+			Item: &hubfinderv1.StreamNearbyResponse_Hub{Hub: hub},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) FindNearest(ctx context.Context, req *hubfinderv1.FindNearestRequest) (*hubfinderv1.FindNearestResponse, error) {
+	ctx, cancel := s.withDeadline(ctx)
+	defer cancel()
+
+	if err := validateCenter(req.GetCenter()); err != nil {
+		return nil, err
+	}
+	if req.GetCount() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "count must be positive")
+	}
+	if err := validateRadius("max_radius_km", req.GetMaxRadiusKm()); err != nil {
+		return nil, err
+	}
+
+	result, err := s.finder.FindNearest(ctx, req.GetCenter().GetLat(), req.GetCenter().GetLon(), int(req.GetCount()), req.GetMaxRadiusKm())
+	if err != nil {
+		return nil, s.toStatus(ctx, "FindNearest", err)
+	}
+
+	return &hubfinderv1.FindNearestResponse{
+		Hubs:           toNearbyHubs(result.Hubs),
+		Warnings:       toWarnings(result.Warnings),
+		Stale:          result.Stale,
+		SnapshotAge:    snapshotAge(result.Stale, result.SnapshotAge),
+		SourceWarnings: toSourceWarnings(result.SourceWarnings),
+	}, nil
+}
+
+func (s *Server) FindInPolygon(ctx context.Context, req *hubfinderv1.FindInPolygonRequest) (*hubfinderv1.FindInPolygonResponse, error) {
+	ctx, cancel := s.withDeadline(ctx)
+	defer cancel()
+
+	polygon := make([]geo.Point, 0, len(req.GetVertices()))
+	for _, v := range req.GetVertices() {
+		polygon = append(polygon, geo.Point{Lat: v.GetLat(), Lon: v.GetLon()})
+	}
+	if err := geo.ValidatePolygon(polygon); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result, err := s.finder.FindInPolygon(ctx, polygon)
+	if err != nil {
+		return nil, s.toStatus(ctx, "FindInPolygon", err)
+	}
+
+	hubs := make([]*hubfinderv1.Hub, 0, len(result.Hubs))
+	for _, hub := range result.Hubs {
+		hubs = append(hubs, toHub(hub))
+	}
+	return &hubfinderv1.FindInPolygonResponse{
+		Hubs:           hubs,
+		Warnings:       toWarnings(result.Warnings),
+		Stale:          result.Stale,
+		SnapshotAge:    snapshotAge(result.Stale, result.SnapshotAge),
+		SourceWarnings: toSourceWarnings(result.SourceWarnings),
+	}, nil
+}
+
+func (s *Server) GetHub(ctx context.Context, req *hubfinderv1.GetHubRequest) (*hubfinderv1.GetHubResponse, error) {
+	ctx, cancel := s.withDeadline(ctx)
+	defer cancel()
+
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id must not be empty")
+	}
+
+	hub, err := s.finder.GetHub(ctx, req.GetId())
+	if err != nil {
+		return nil, s.toStatus(ctx, "GetHub", err)
+	}
+	return &hubfinderv1.GetHubResponse{Hub: toHub(hub)}, nil
+}
+
+// withDeadline applies the default timeout to calls without a deadline. The
+// client's deadline, if any, already bounds ctx.
+func (s *Server) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || s.defaultTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.defaultTimeout)
+}
+
+// toStatus maps a finder error to a gRPC status using the error types of the
+// geo, finder and repository packages.
+func (s *Server) toStatus(ctx context.Context, method string, err error) error {
+	var (
+		backendErr *repository.BackendError
+		malformed  *repository.MalformedRowError
+	)
+	code := codes.Internal
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled):
+		code = codes.Canceled
+	case finder.IsInvalidArgument(err):
+		code = codes.InvalidArgument
+	case errors.Is(err, repository.ErrNotFound):
+		code = codes.NotFound
+	case errors.As(err, &backendErr):
+		if backendErr.Retryable {
+			code = codes.Unavailable
+		}
+	case errors.As(err, &malformed):
+		code = codes.DataLoss
+	}
+
+	switch code {
+	case codes.Internal, codes.Unavailable, codes.DataLoss:
+		s.logger.ErrorContext(ctx, "call failed", "method", method, "code", code.String(), "error", err)
+	}
+	return status.Error(code, err.Error())
+}
+
+func validateCenter(p *hubfinderv1.Point) error {
+	if p == nil {
+		return status.Error(codes.InvalidArgument, "center is required")
+	}
+	if math.IsNaN(p.GetLat()) || p.GetLat() < -90 || p.GetLat() > 90 {
+		return status.Error(codes.InvalidArgument, "center.lat must be between -90 and 90 degrees")
+	}
+	if math.IsNaN(p.GetLon()) || p.GetLon() < -180 || p.GetLon() > 180 {
+		return status.Error(codes.InvalidArgument, "center.lon must be between -180 and 180 degrees")
+	}
+	return nil
+}
+
+func validateRadius(field string, radiusKm float64) error {
+	if math.IsNaN(radiusKm) || math.IsInf(radiusKm, 0) || radiusKm < 0 {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("%s must be a non-negative number", field))
+	}
+	return nil
+}
+
+// parseSort parses the sort field of a request, which defaults to distance.
+func parseSort(sort string) (finder.SortKey, finder.Order, error) {
+	if sort == "" {
+		return finder.SortDistance, finder.OrderDefault, nil
+	}
+	key, order, err := finder.ParseSort(sort)
+	if err != nil {
+		return "", "", status.Error(codes.InvalidArgument, "sort: "+err.Error())
+	}
+	// The server has no travel estimator to sort by.
+	if key == finder.SortTravelTime {
+		return "", "", status.Error(codes.InvalidArgument, "sort: travel-time is not supported by the gRPC API")
+	}
+	return key, order, nil
+}
+
+// snapshotAge returns the age to report for a result, which is only set for
+// stale results.
+func snapshotAge(stale bool, age time.Duration) *durationpb.Duration {
+	if !stale {
+		return nil
+	}
+	return durationpb.New(age)
+}
+
+func toHub(hub model.Hub) *hubfinderv1.Hub {
+	return &hubfinderv1.Hub{
+		Id:             hub.ID,
+		Name:           hub.Name,
+		Location:       &hubfinderv1.Point{Lat: hub.Lat, Lon: hub.Lon},
+		Type:           hub.Type,
+		Routes:         int32(hub.Routes),
+		PassengerClass: hub.PassengerClass,
+		Country:        hub.Country,
+		Source:         hub.Source,
+	}
+}
+
+func toNearbyHubs(hubs []model.HubWithDistance) []*hubfinderv1.NearbyHub {
+	out := make([]*hubfinderv1.NearbyHub, 0, len(hubs))
+	for _, hub := range hubs {
+		nearby := &hubfinderv1.NearbyHub{Hub: toHub(hub.Hub), DistanceKm: hub.DistanceKm, Score: hub.Score}
+		for _, factor := range hub.ScoreFactors {
+			nearby.ScoreFactors = append(nearby.ScoreFactors, &hubfinderv1.ScoreFactor{Name: factor.Name, Value: factor.Value, Weight: factor.Weight})
+		}
+		out = append(out, nearby)
+	}
+	return out
+}
+
+func toWarnings(warnings []repository.RowWarning) []*hubfinderv1.RowWarning {
+	out := make([]*hubfinderv1.RowWarning, 0, len(warnings))
+	for _, w := range warnings {
+		out = append(out, &hubfinderv1.RowWarning{Id: w.ID, Reason: w.Reason})
+	}
+	return out
+}
+
+func toSourceWarnings(warnings []repository.SourceWarning) []*hubfinderv1.SourceWarning {
+	out := make([]*hubfinderv1.SourceWarning, 0, len(warnings))
+	for _, w := range warnings {
+		out = append(out, &hubfinderv1.SourceWarning{Source: w.Source, Error: w.Err.Error()})
+	}
+	return out
+}
@@ -0,0 +1,410 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/api/hubfinderv1"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type stubRepository struct {
+	hubs      []model.Hub
+	warnings  []repository.RowWarning
+	returnErr error
+	// block makes every call wait until its context is done.
+	block bool
+	// snapshotAge, if set, marks results as served from a snapshot that old.
+	snapshotAge time.Duration
+}
+
+func (r *stubRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	return result.Hubs, err
+}
+
+func (r *stubRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (repository.Result, error) {
+	if r.block {
+		<-ctx.Done()
+		return repository.Result{}, ctx.Err()
+	}
+	if r.returnErr != nil {
+		return repository.Result{}, r.returnErr
+	}
+	var hubs []model.Hub
+	for _, h := range r.hubs {
+		if h.Lat >= minLat && h.Lat <= maxLat && h.Lon >= minLon && h.Lon <= maxLon {
+			hubs = append(hubs, h)
+		}
+	}
+	return repository.Result{Hubs: hubs, Warnings: r.warnings, Stale: r.snapshotAge > 0, SnapshotAge: r.snapshotAge}, nil
+}
+
+var testHubs = []model.Hub{
+	{ID: "BUD", Name: "Budapest Ferenc Liszt", Lat: 47.4298, Lon: 19.2611},
+	{ID: "VIE", Name: "Vienna", Lat: 48.1103, Lon: 16.5697},
+	{ID: "PRG", Name: "Prague", Lat: 50.1008, Lon: 14.26},
+}
+
+func newTestClient(t *testing.T, repo repository.Repository, opts ...Option) hubfinderv1.HubFinderServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	New(finder.New(repo), opts...).Register(gs)
+	go func() { _ = gs.Serve(listener) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return hubfinderv1.NewHubFinderServiceClient(conn)
+}
+
+func TestFindNearby(t *testing.T) {
+	client := newTestClient(t, &stubRepository{
+		hubs:     testHubs,
+		warnings: []repository.RowWarning{{ID: "broken", Reason: "missing lat"}},
+	})
+
+	resp, err := client.FindNearby(context.Background(), &hubfinderv1.FindNearbyRequest{
+		Center:   &hubfinderv1.Point{Lat: 47.4979, Lon: 19.0402},
+		RadiusKm: 300,
+		Limit:    1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.GetHubs()) != 1 || resp.GetHubs()[0].GetHub().GetId() != "BUD" {
+		t.Fatalf("expected BUD, got %v", resp.GetHubs())
+	}
+	if d := resp.GetHubs()[0].GetDistanceKm(); d < 17 || d > 19 {
+		t.Errorf("expected a distance of about 18 km, got %f", d)
+	}
+	if !resp.GetHasMore() || resp.GetNextOffset() != 1 {
+		t.Errorf("expected another page at offset 1, got has_more=%v next_offset=%d", resp.GetHasMore(), resp.GetNextOffset())
+	}
+	if len(resp.GetWarnings()) != 1 || resp.GetWarnings()[0].GetId() != "broken" {
+		t.Errorf("expected the broken row warning, got %v", resp.GetWarnings())
+	}
+}
+
+func TestFindNearby_Sort(t *testing.T) {
+	client := newTestClient(t, &stubRepository{hubs: testHubs})
+
+	resp, err := client.FindNearby(context.Background(), &hubfinderv1.FindNearbyRequest{
+		Center:   &hubfinderv1.Point{Lat: 47.4979, Lon: 19.0402},
+		RadiusKm: 500,
+		Sort:     "name:desc",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, hub := range resp.GetHubs() {
+		got = append(got, hub.GetHub().GetId())
+	}
+	if want := []string{"VIE", "PRG", "BUD"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestToHub(t *testing.T) {
+	got := toHub(model.Hub{
+		ID: "BUD", Name: "Budapest", Lat: 47.4298, Lon: 19.2611,
+		Type: "large_airport", Country: "HU", Routes: 152, PassengerClass: "medium",
+	})
+	want := &hubfinderv1.Hub{
+		Id: "BUD", Name: "Budapest", Location: &hubfinderv1.Point{Lat: 47.4298, Lon: 19.2611},
+		Type: "large_airport", Country: "HU", Routes: 152, PassengerClass: "medium",
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestStreamNearby(t *testing.T) {
+	client := newTestClient(t, &stubRepository{
+		hubs:     testHubs,
+		warnings: []repository.RowWarning{{ID: "broken", Reason: "missing lat"}},
+	})
+
+	stream, err := client.StreamNearby(context.Background(), &hubfinderv1.StreamNearbyRequest{
+		Center:   &hubfinderv1.Point{Lat: 47.4979, Lon: 19.0402},
+		RadiusKm: 1000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ids []string
+	var warnings int
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch item := msg.GetItem().(type) {
+		case *hubfinderv1.StreamNearbyResponse_Hub:
+			ids = append(ids, item.Hub.GetHub().GetId())
+		case *hubfinderv1.StreamNearbyResponse_Warning:
+			if len(ids) > 0 {
+				t.Error("expected warnings before hubs")
+			}
+			warnings++
+		}
+	}
+
+	if want := []string{"BUD", "VIE", "PRG"}; len(ids) != 3 || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if warnings != 1 {
+		t.Errorf("expected 1 warning, got %d", warnings)
+	}
+}
+
+func TestPartialResults(t *testing.T) {
+	multi, err := repository.NewMultiRepository(repository.MultiConfig{
+		Sources: []repository.Source{
+			{Name: "cloudant", Repository: &stubRepository{returnErr: errors.New("connection refused")}},
+			{Name: "store", Repository: &stubRepository{hubs: testHubs}},
+		},
+		FailurePolicy: repository.FailurePolicyPartial,
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("create multi repository: %v", err)
+	}
+	client := newTestClient(t, multi)
+	center := &hubfinderv1.Point{Lat: 47.4979, Lon: 19.0402}
+
+	resp, err := client.FindNearby(context.Background(), &hubfinderv1.FindNearbyRequest{Center: center, RadiusKm: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.GetHubs()) != 1 || resp.GetHubs()[0].GetHub().GetSource() != "store" {
+		t.Errorf("expected BUD from the store, got %v", resp.GetHubs())
+	}
+	if warnings := resp.GetSourceWarnings(); len(warnings) != 1 || warnings[0].GetSource() != "cloudant" || warnings[0].GetError() != "connection refused" {
+		t.Errorf("expected a warning about the cloudant source, got %v", warnings)
+	}
+
+	stream, err := client.StreamNearby(context.Background(), &hubfinderv1.StreamNearbyRequest{Center: center, RadiusKm: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.GetSourceWarning().GetSource() != "cloudant" {
+		t.Errorf("expected the stream to start with a source warning, got %v", first)
+	}
+}
+
+func TestStaleResults(t *testing.T) {
+	client := newTestClient(t, &stubRepository{hubs: testHubs, snapshotAge: 90 * time.Minute})
+	center := &hubfinderv1.Point{Lat: 47.4979, Lon: 19.0402}
+
+	resp, err := client.FindNearby(context.Background(), &hubfinderv1.FindNearbyRequest{Center: center, RadiusKm: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.GetStale() || resp.GetSnapshotAge().AsDuration() != 90*time.Minute {
+		t.Errorf("expected a stale result 90m old, got stale=%v age=%v", resp.GetStale(), resp.GetSnapshotAge())
+	}
+
+	stream, err := client.StreamNearby(context.Background(), &hubfinderv1.StreamNearbyRequest{Center: center, RadiusKm: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.GetStale().GetSnapshotAge().AsDuration() != 90*time.Minute {
+		t.Errorf("expected the stream to start with a staleness notice, got %v", first)
+	}
+}
+
+func TestFindNearest(t *testing.T) {
+	client := newTestClient(t, &stubRepository{hubs: testHubs})
+
+	resp, err := client.FindNearest(context.Background(), &hubfinderv1.FindNearestRequest{
+		Center: &hubfinderv1.Point{Lat: 50.0, Lon: 14.0},
+		Count:  2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.GetHubs()) != 2 || resp.GetHubs()[0].GetHub().GetId() != "PRG" || resp.GetHubs()[1].GetHub().GetId() != "VIE" {
+		t.Errorf("expected PRG and VIE, got %v", resp.GetHubs())
+	}
+}
+
+func TestFindInPolygon(t *testing.T) {
+	client := newTestClient(t, &stubRepository{hubs: testHubs})
+
+	resp, err := client.FindInPolygon(context.Background(), &hubfinderv1.FindInPolygonRequest{
+		Vertices: []*hubfinderv1.Point{{Lat: 47, Lon: 16}, {Lat: 49, Lon: 16}, {Lat: 49, Lon: 20}, {Lat: 47, Lon: 20}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.GetHubs()) != 2 || resp.GetHubs()[0].GetId() != "BUD" || resp.GetHubs()[1].GetId() != "VIE" {
+		t.Errorf("expected BUD and VIE, got %v", resp.GetHubs())
+	}
+}
+
+func TestGetHub(t *testing.T) {
+	client := newTestClient(t, &stubRepository{hubs: testHubs})
+
+	resp, err := client.GetHub(context.Background(), &hubfinderv1.GetHubRequest{Id: "VIE"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetHub().GetName() != "Vienna" || resp.GetHub().GetLocation().GetLat() != 48.1103 {
+		t.Errorf("unexpected hub %v", resp.GetHub())
+	}
+
+	_, err = client.GetHub(context.Background(), &hubfinderv1.GetHubRequest{Id: "LHR"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	client := newTestClient(t, &stubRepository{hubs: testHubs})
+	ctx := context.Background()
+	center := &hubfinderv1.Point{Lat: 47.5, Lon: 19.0}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"missing center", func() error {
+			_, err := client.FindNearby(ctx, &hubfinderv1.FindNearbyRequest{RadiusKm: 10})
+			return err
+		}},
+		{"latitude out of range", func() error {
+			_, err := client.FindNearby(ctx, &hubfinderv1.FindNearbyRequest{Center: &hubfinderv1.Point{Lat: 91}, RadiusKm: 10})
+			return err
+		}},
+		{"longitude out of range", func() error {
+			_, err := client.FindNearest(ctx, &hubfinderv1.FindNearestRequest{Center: &hubfinderv1.Point{Lon: -181}, Count: 1})
+			return err
+		}},
+		{"negative radius", func() error {
+			_, err := client.FindNearby(ctx, &hubfinderv1.FindNearbyRequest{Center: center, RadiusKm: -1})
+			return err
+		}},
+		{"negative offset", func() error {
+			_, err := client.FindNearby(ctx, &hubfinderv1.FindNearbyRequest{Center: center, RadiusKm: 10, Offset: -1})
+			return err
+		}},
+		{"unknown sort", func() error {
+			_, err := client.FindNearby(ctx, &hubfinderv1.FindNearbyRequest{Center: center, RadiusKm: 10, Sort: "size"})
+			return err
+		}},
+		{"travel-time sort", func() error {
+			_, err := client.FindNearby(ctx, &hubfinderv1.FindNearbyRequest{Center: center, RadiusKm: 10, Sort: "travel-time"})
+			return err
+		}},
+		{"zero count", func() error {
+			_, err := client.FindNearest(ctx, &hubfinderv1.FindNearestRequest{Center: center})
+			return err
+		}},
+		{"degenerate polygon", func() error {
+			_, err := client.FindInPolygon(ctx, &hubfinderv1.FindInPolygonRequest{Vertices: []*hubfinderv1.Point{center, center}})
+			return err
+		}},
+		{"empty id", func() error {
+			_, err := client.GetHub(ctx, &hubfinderv1.GetHubRequest{})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call()); code != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument, got %v", code)
+			}
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{"retryable backend failure", &repository.BackendError{Op: "post search", Retryable: true, Err: errors.New("connection refused")}, codes.Unavailable},
+		{"permanent backend failure", &repository.BackendError{Op: "post search", StatusCode: 401, Err: errors.New("unauthorized")}, codes.Internal},
+		{"malformed row", &repository.MalformedRowError{RowWarning: repository.RowWarning{ID: "x", Reason: "missing lat"}}, codes.DataLoss},
+		{"untyped error", errors.New("boom"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &stubRepository{returnErr: tt.err})
+
+			_, err := client.FindNearby(context.Background(), &hubfinderv1.FindNearbyRequest{
+				Center:   &hubfinderv1.Point{Lat: 47.5, Lon: 19.0},
+				RadiusKm: 10,
+			})
+			if code := status.Code(err); code != tt.expected {
+				t.Errorf("got %v, want %v", code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeadlines(t *testing.T) {
+	req := &hubfinderv1.FindNearbyRequest{Center: &hubfinderv1.Point{Lat: 47.5, Lon: 19.0}, RadiusKm: 10}
+
+	t.Run("client deadline", func(t *testing.T) {
+		client := newTestClient(t, &stubRepository{block: true})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := client.FindNearby(ctx, req); status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("expected DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("default timeout", func(t *testing.T) {
+		client := newTestClient(t, &stubRepository{block: true}, WithDefaultTimeout(50*time.Millisecond))
+
+		if _, err := client.FindNearby(context.Background(), req); status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("expected DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package hubimport
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// columnNames lists the accepted header names of each column, compared
+// case-insensitively.
+var columnNames = map[string][]string{
+	"id":   {"id", "_id"},
+	"name": {"name"},
+	"lat":  {"lat", "latitude"},
+	"lon":  {"lon", "lng", "long", "longitude"},
+
+	"type":            {"type"},
+	"country":         {"country"},
+	"routes":          {"routes"},
+	"passenger_class": {"passenger_class"},
+}
+
+// Record is one hub read from an import file.
+type Record struct {
+	// Line is the line number of the record in the file.
+	Line int
+	Hub  model.Hub
+	// Err is set if the record is not a valid hub. Such records are
+	// reported but not imported.
+	Err error
+}
+
+// ReadCSV reads hubs from CSV with a header row naming the name, lat and
+// lon columns and, optionally, id, type, country, routes and
+// passenger_class columns; other columns are ignored.
+// Records without an ID get a StableID. Invalid records and records whose
+// ID repeats an earlier one are returned with Err set; only a missing
+// column or unreadable CSV fails the whole file.
+func ReadCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read CSV header: file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+	columns, err := findColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	seen := make(map[string]int)
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		record := parseRecord(fields, columns)
+		record.Line = line
+		if record.Err == nil {
+			if first, ok := seen[record.Hub.ID]; ok {
+				record.Err = fmt.Errorf("duplicate ID %q, first used on line %d", record.Hub.ID, first)
+			} else {
+				seen[record.Hub.ID] = line
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// findColumns maps each known column to its index in header, or -1 for an
+// absent column.
+func findColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(columnNames))
+	for column := range columnNames {
+		columns[column] = -1
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range columnNames {
+			for _, alias := range aliases {
+				if name == alias && columns[column] == -1 {
+					columns[column] = i
+				}
+			}
+		}
+	}
+	for _, column := range []string{"name", "lat", "lon"} {
+		if columns[column] == -1 {
+			return nil, fmt.Errorf("CSV header has no %s column", column)
+		}
+	}
+	return columns, nil
+}
+
+func parseRecord(fields []string, columns map[string]int) Record {
+	field := func(column string) string {
+		i := columns[column]
+		if i < 0 || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	hub := model.Hub{
+		ID:             field("id"),
+		Name:           field("name"),
+		Type:           field("type"),
+		Country:        field("country"),
+		PassengerClass: field("passenger_class"),
+	}
+	if hub.Name == "" {
+		return Record{Hub: hub, Err: errors.New("missing name")}
+	}
+	var err error
+	if hub.Lat, err = strconv.ParseFloat(field("lat"), 64); err != nil {
+		return Record{Hub: hub, Err: fmt.Errorf("lat %q is not a number", field("lat"))}
+	}
+	if hub.Lon, err = strconv.ParseFloat(field("lon"), 64); err != nil {
+		return Record{Hub: hub, Err: fmt.Errorf("lon %q is not a number", field("lon"))}
+	}
+	if routes := field("routes"); routes != "" {
+		if hub.Routes, err = strconv.Atoi(routes); err != nil || hub.Routes < 0 {
+			return Record{Hub: hub, Err: fmt.Errorf("routes %q is not a whole number", routes)}
+		}
+	}
+	if hub.ID == "" {
+		hub.ID = StableID(hub.Name, hub.Lat, hub.Lon)
+	}
+	return Record{Hub: hub, Err: repository.ValidateHub(hub)}
+}
+
+// StableID derives a document ID from a hub's name and position, so
+// importing the same file again updates the same documents instead of
+// creating duplicates. Positions are compared to five decimal places,
+// about one meter.
+func StableID(name string, lat, lon float64) string {
+	key := fmt.Sprintf("%s|%.5f|%.5f", strings.ToLower(strings.Join(strings.Fields(name), " ")), lat, lon)
+	sum := sha256.Sum256([]byte(key))
+	return "hub-" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,174 @@
+// Package hubimport loads hubs from files into a writable repository.
+package hubimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+const (
+	defaultBatchSize   = 500
+	defaultConcurrency = 4
+)
+
+// Importer writes records to a repository in concurrent batches.
+type Importer struct {
+	target      repository.WritableRepository
+	batchSize   int
+	concurrency int
+	logger      *slog.Logger
+}
+
+type Config struct {
+	Target repository.WritableRepository
+	// BatchSize is the number of hubs written per request. Defaults to 500.
+	BatchSize int
+	// Concurrency is the number of requests in flight at once. Defaults to 4.
+	Concurrency int
+	// Logger receives progress messages. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Report summarizes an import.
+type Report struct {
+	Records int
+	// Written counts hubs created or updated.
+	Written int
+	// Unchanged counts hubs that were already stored as given.
+	Unchanged int
+	// Invalid counts records that were not valid hubs.
+	Invalid int
+	// Conflicts counts hubs changed concurrently by someone else.
+	Conflicts int
+	// Failed counts hubs that could not be written for any other reason.
+	Failed int
+	// Problems lists every record that was not imported, in file order.
+	Problems []Problem
+}
+
+// Problem describes a record that was not imported.
+type Problem struct {
+	Line int
+	ID   string
+	Err  error
+}
+
+func (p Problem) String() string {
+	if p.ID == "" {
+		return fmt.Sprintf("line %d: %v", p.Line, p.Err)
+	}
+	return fmt.Sprintf("line %d (%s): %v", p.Line, p.ID, p.Err)
+}
+
+func New(cfg Config) (*Importer, error) {
+	if cfg.Target == nil {
+		return nil, fmt.Errorf("hubimport: target is required")
+	}
+
+	im := &Importer{
+		target:      cfg.Target,
+		batchSize:   cfg.BatchSize,
+		concurrency: cfg.Concurrency,
+		logger:      cfg.Logger,
+	}
+	if im.batchSize <= 0 {
+		im.batchSize = defaultBatchSize
+	}
+	if im.concurrency <= 0 {
+		im.concurrency = defaultConcurrency
+	}
+	if im.logger == nil {
+		im.logger = slog.Default()
+	}
+	im.logger = im.logger.With("component", "hubimport")
+	return im, nil
+}
+
+// Import writes the valid records and reports the outcome of every record.
+// A batch that fails as a whole marks all of its records as failed and the
+// other batches carry on, so the import can simply be run again. The error
+// is only set if ctx ends first.
+func (im *Importer) Import(ctx context.Context, records []Record) (Report, error) {
+	report := Report{Records: len(records)}
+	outcomes := make([]error, len(records))
+
+	var valid []int
+	for i, record := range records {
+		if record.Err != nil {
+			outcomes[i] = record.Err
+			report.Invalid++
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, im.concurrency)
+		done int
+	)
+	for start := 0; start < len(valid); start += im.batchSize {
+		batch := valid[start:min(start+im.batchSize, len(valid))]
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			hubs := make([]model.Hub, len(batch))
+			for j, i := range batch {
+				hubs[j] = records[i].Hub
+			}
+			results, err := im.target.BulkUpsert(ctx, hubs)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for j, i := range batch {
+				switch {
+				case err != nil:
+					outcomes[i] = err
+					report.Failed++
+				case results[j].Err != nil:
+					outcomes[i] = results[j].Err
+					if errors.Is(results[j].Err, repository.ErrConflict) {
+						report.Conflicts++
+					} else {
+						report.Failed++
+					}
+				case results[j].Unchanged:
+					report.Unchanged++
+				default:
+					report.Written++
+				}
+			}
+			done += len(batch)
+			if err != nil {
+				im.logger.WarnContext(ctx, "batch failed", "hubs", len(batch), "error", err)
+			}
+			im.logger.InfoContext(ctx, "batch processed", "hubs", len(batch), "done", done, "total", len(valid))
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	for i, err := range outcomes {
+		if err != nil {
+			report.Problems = append(report.Problems, Problem{Line: records[i].Line, ID: records[i].Hub.ID, Err: err})
+		}
+	}
+	return report, nil
+}
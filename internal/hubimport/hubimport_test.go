@@ -0,0 +1,263 @@
+package hubimport_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/hubimport"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// memoryTarget is a WritableRepository kept in memory. Hubs whose ID is in
+// conflicts always fail with ErrConflict, and requests containing a hub in
+// failBatch fail as a whole.
+type memoryTarget struct {
+	mu        sync.Mutex
+	hubs      map[string]model.Hub
+	revs      map[string]int
+	requests  int
+	conflicts map[string]bool
+	failBatch string
+}
+
+func newMemoryTarget() *memoryTarget {
+	return &memoryTarget{hubs: map[string]model.Hub{}, revs: map[string]int{}, conflicts: map[string]bool{}}
+}
+
+func (m *memoryTarget) Revision(_ context.Context, id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.hubs[id]; !ok {
+		return "", repository.ErrNotFound
+	}
+	return fmt.Sprint(m.revs[id]), nil
+}
+
+func (m *memoryTarget) Put(context.Context, model.Hub, string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *memoryTarget) Delete(context.Context, string, string) error {
+	return errors.New("not implemented")
+}
+
+func (m *memoryTarget) BulkUpsert(_ context.Context, hubs []model.Hub) ([]repository.WriteResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+
+	for _, hub := range hubs {
+		if hub.ID == m.failBatch {
+			return nil, &repository.BackendError{Op: "post bulk docs", StatusCode: 500, Retryable: true, Err: errors.New("boom")}
+		}
+	}
+	results := make([]repository.WriteResult, len(hubs))
+	for i, hub := range hubs {
+		results[i].ID = hub.ID
+		switch current, exists := m.hubs[hub.ID]; {
+		case m.conflicts[hub.ID]:
+			results[i].Err = repository.ErrConflict
+		case exists && current == hub:
+			results[i].Unchanged = true
+		default:
+			m.hubs[hub.ID] = hub
+			m.revs[hub.ID]++
+		}
+		results[i].Rev = fmt.Sprint(m.revs[hub.ID])
+	}
+	return results, nil
+}
+
+const airportsCSV = `iata,Name,Latitude,Longitude,country
+,Budapest Ferenc Liszt,47.4369,19.2556,HU
+,Heathrow,51.47,-0.4543,GB
+,No Position,,,XX
+,North of North,91,0,XX
+,Sydney,-33.9461,151.1772,AU
+`
+
+func TestReadCSV(t *testing.T) {
+	records, err := hubimport.ReadCSV(strings.NewReader("id,name,lat,lon\n" +
+		"BUD,Budapest,47.4369,19.2556\n" +
+		"LHR,Heathrow,51.47,-0.4543\n" +
+		"BUD,Budapest again,47.4369,19.2556\n" +
+		"XXX,,1,1\n" +
+		"BAD,Bad,north,1\n" +
+		"FAR,Far,0,181\n" +
+		",Derived,1.5,2.5\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		line    int
+		id      string
+		wantErr error
+		errText string
+	}{
+		{line: 2, id: "BUD"},
+		{line: 3, id: "LHR"},
+		{line: 4, id: "BUD", errText: "duplicate ID"},
+		{line: 5, id: "XXX", errText: "missing name"},
+		{line: 6, id: "BAD", errText: "not a number"},
+		{line: 7, id: "FAR", wantErr: geo.ErrInvalidLongitude},
+		{line: 8, id: hubimport.StableID("Derived", 1.5, 2.5)},
+	}
+	if len(records) != len(tests) {
+		t.Fatalf("got %d records, want %d", len(records), len(tests))
+	}
+	for i, tt := range tests {
+		got := records[i]
+		if got.Line != tt.line || got.Hub.ID != tt.id {
+			t.Errorf("record %d: got line %d ID %q, want line %d ID %q", i, got.Line, got.Hub.ID, tt.line, tt.id)
+		}
+		switch {
+		case tt.wantErr != nil:
+			if !errors.Is(got.Err, tt.wantErr) {
+				t.Errorf("record %d: got error %v, want %v", i, got.Err, tt.wantErr)
+			}
+		case tt.errText != "":
+			if got.Err == nil || !strings.Contains(got.Err.Error(), tt.errText) {
+				t.Errorf("record %d: got error %v, want one containing %q", i, got.Err, tt.errText)
+			}
+		case got.Err != nil:
+			t.Errorf("record %d: unexpected error: %v", i, got.Err)
+		}
+	}
+}
+
+func TestReadCSV_OptionalColumns(t *testing.T) {
+	records, err := hubimport.ReadCSV(strings.NewReader("name,lat,lon,Type,country,routes,passenger_class\n" +
+		"Budapest,47.4369,19.2556,large_airport,HU,152,medium\n" +
+		"Sármellék,46.6864,17.159,,,,\n" +
+		"Bad,1,1,small_airport,HU,many,\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	want := model.Hub{
+		ID:             hubimport.StableID("Budapest", 47.4369, 19.2556),
+		Name:           "Budapest",
+		Lat:            47.4369,
+		Lon:            19.2556,
+		Type:           "large_airport",
+		Country:        "HU",
+		Routes:         152,
+		PassengerClass: "medium",
+	}
+	if records[0].Err != nil || records[0].Hub != want {
+		t.Errorf("got %+v (%v), want %+v", records[0].Hub, records[0].Err, want)
+	}
+	if got := records[1].Hub; records[1].Err != nil || got.Type != "" || got.Country != "" || got.Routes != 0 || got.PassengerClass != "" {
+		t.Errorf("got %+v (%v), want a hub without the optional fields", got, records[1].Err)
+	}
+	if err := records[2].Err; err == nil || !strings.Contains(err.Error(), "routes") {
+		t.Errorf("got error %v, want one about routes", err)
+	}
+}
+
+func TestReadCSV_MissingColumn(t *testing.T) {
+	if _, err := hubimport.ReadCSV(strings.NewReader("id,name,lat\nBUD,Budapest,47.4\n")); err == nil {
+		t.Error("expected an error for a header without lon")
+	}
+}
+
+func TestStableID(t *testing.T) {
+	id := hubimport.StableID("Budapest  Ferenc Liszt", 47.4369, 19.2556)
+	if other := hubimport.StableID("budapest ferenc liszt", 47.436900001, 19.2556); other != id {
+		t.Errorf("expected case, spacing and sub-meter differences to keep the ID, got %q and %q", id, other)
+	}
+	if other := hubimport.StableID("Budapest Ferenc Liszt", 47.44, 19.2556); other == id {
+		t.Errorf("expected a different position to change the ID, got %q for both", id)
+	}
+}
+
+func newTestImporter(t *testing.T, target repository.WritableRepository, batchSize int) *hubimport.Importer {
+	t.Helper()
+	im, err := hubimport.New(hubimport.Config{
+		Target:      target,
+		BatchSize:   batchSize,
+		Concurrency: 2,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("create importer: %v", err)
+	}
+	return im
+}
+
+func TestImport_IsIdempotent(t *testing.T) {
+	records, err := hubimport.ReadCSV(strings.NewReader(airportsCSV))
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	target := newMemoryTarget()
+	im := newTestImporter(t, target, 2)
+
+	report, err := im.Import(context.Background(), records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Records != 5 || report.Written != 3 || report.Invalid != 2 || report.Unchanged != 0 {
+		t.Errorf("first import: unexpected report %+v", report)
+	}
+	if target.requests != 2 {
+		t.Errorf("expected 3 valid hubs in 2 requests, got %d", target.requests)
+	}
+	if len(report.Problems) != 2 || report.Problems[0].Line != 4 || report.Problems[1].Line != 5 {
+		t.Errorf("unexpected problems %v", report.Problems)
+	}
+
+	report, err = im.Import(context.Background(), records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Written != 0 || report.Unchanged != 3 || len(target.hubs) != 3 {
+		t.Errorf("second import: unexpected report %+v with %d stored hubs", report, len(target.hubs))
+	}
+}
+
+func TestImport_ReportsFailures(t *testing.T) {
+	var records []hubimport.Record
+	for i := range 6 {
+		id := fmt.Sprintf("hub-%d", i)
+		records = append(records, hubimport.Record{Line: i + 2, Hub: model.Hub{ID: id, Name: id, Lat: float64(i), Lon: 0}})
+	}
+	target := newMemoryTarget()
+	target.conflicts["hub-1"] = true
+	target.failBatch = "hub-4"
+
+	report, err := newTestImporter(t, target, 2).Import(context.Background(), records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Written != 3 || report.Conflicts != 1 || report.Failed != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	var got []string
+	for _, p := range report.Problems {
+		got = append(got, p.ID)
+	}
+	if want := []string{"hub-1", "hub-4", "hub-5"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("problems = %v, want %v", got, want)
+	}
+	if !errors.Is(report.Problems[0].Err, repository.ErrConflict) {
+		t.Errorf("expected a conflict for hub-1, got %v", report.Problems[0].Err)
+	}
+	var backendErr *repository.BackendError
+	if !errors.As(report.Problems[1].Err, &backendErr) {
+		t.Errorf("expected the batch failure for hub-4, got %v", report.Problems[1].Err)
+	}
+}
@@ -0,0 +1,82 @@
+package hubsync
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// Compile-time check that FileStore implements Store.
+var _ Store = (*FileStore)(nil)
+
+// FileStore is a Store kept in a repository.Snapshot file, so the synced
+// hubs can be served with --snapshot. It is not safe for concurrent use.
+type FileStore struct {
+	path string
+	now  func() time.Time
+	hubs map[string]model.Hub
+	seq  string
+}
+
+// OpenFileStore loads the snapshot at path, or starts an empty store if the
+// file does not exist yet.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, now: time.Now, hubs: map[string]model.Hub{}}
+
+	snapshot, err := repository.LoadSnapshot(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, hub := range snapshot.Hubs {
+		s.hubs[hub.ID] = hub
+	}
+	s.seq = snapshot.Seq
+	return s, nil
+}
+
+func (s *FileStore) Checkpoint(context.Context) (string, error) {
+	return s.seq, nil
+}
+
+// Apply rewrites the snapshot file with the changes applied. The store is
+// left unchanged if the file cannot be written.
+func (s *FileStore) Apply(_ context.Context, upserts []model.Hub, deletes []string, seq string) error {
+	hubs := maps.Clone(s.hubs)
+	for _, id := range deletes {
+		delete(hubs, id)
+	}
+	for _, hub := range upserts {
+		hubs[hub.ID] = hub
+	}
+
+	sorted := slices.SortedFunc(maps.Values(hubs), func(a, b model.Hub) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	err := repository.WriteSnapshot(s.path, &repository.Snapshot{
+		CreatedAt: s.now().UTC(),
+		Seq:       seq,
+		Hubs:      sorted,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.hubs = hubs
+	s.seq = seq
+	return nil
+}
+
+// Len returns the number of hubs in the store.
+func (s *FileStore) Len() int {
+	return len(s.hubs)
+}
@@ -0,0 +1,230 @@
+// Package hubsync keeps a local hub store up to date by following the
+// changes feed of the backend database.
+package hubsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// Store is a local, persistent copy of the hub database.
+type Store interface {
+	// Checkpoint returns the sequence of the last applied batch, or an
+	// empty string if nothing has been applied yet.
+	Checkpoint(ctx context.Context) (string, error)
+	// Apply inserts or replaces upserts, removes the hubs with the IDs in
+	// deletes and records seq as the new checkpoint, all at once.
+	Apply(ctx context.Context, upserts []model.Hub, deletes []string, seq string) error
+}
+
+// Compile-time check that the bbolt repository can be used as a Store.
+var _ Store = (*repository.BoltRepository)(nil)
+
+const (
+	defaultBatchSize   = 500
+	defaultPollTimeout = 25 * time.Second
+	defaultRetryDelay  = time.Second
+	maxRetryDelay      = time.Minute
+)
+
+// Syncer copies changes from a ChangesSource into a Store.
+type Syncer struct {
+	source      repository.ChangesSource
+	store       Store
+	batchSize   int
+	pollTimeout time.Duration
+	retryDelay  time.Duration
+	logger      *slog.Logger
+}
+
+type Config struct {
+	Source repository.ChangesSource
+	Store  Store
+	// BatchSize caps the number of changes read per request. Defaults to 500.
+	BatchSize int
+	// PollTimeout is how long a continuous sync waits for new changes in a
+	// single request. Defaults to 25 seconds.
+	PollTimeout time.Duration
+	// RetryDelay is the first wait after a retryable failure in continuous
+	// mode. It doubles after every further failure, up to one minute.
+	// Defaults to one second.
+	RetryDelay time.Duration
+	// Logger receives progress and retry messages. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Stats summarizes the changes applied by a sync.
+type Stats struct {
+	Batches  int
+	Upserted int
+	Deleted  int
+	// Skipped counts changed documents that are not valid hubs. They are
+	// removed from the store.
+	Skipped int
+	// Seq is the checkpoint after the last applied batch.
+	Seq string
+}
+
+func New(cfg Config) (*Syncer, error) {
+	if cfg.Source == nil {
+		return nil, fmt.Errorf("hubsync: source is required")
+	}
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("hubsync: store is required")
+	}
+
+	s := &Syncer{
+		source:      cfg.Source,
+		store:       cfg.Store,
+		batchSize:   cfg.BatchSize,
+		pollTimeout: cfg.PollTimeout,
+		retryDelay:  cfg.RetryDelay,
+		logger:      cfg.Logger,
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultBatchSize
+	}
+	if s.pollTimeout <= 0 {
+		s.pollTimeout = defaultPollTimeout
+	}
+	if s.retryDelay <= 0 {
+		s.retryDelay = defaultRetryDelay
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	s.logger = s.logger.With("component", "hubsync")
+	return s, nil
+}
+
+// SyncOnce applies every change made since the store's checkpoint and
+// returns once the store has caught up.
+func (s *Syncer) SyncOnce(ctx context.Context) (Stats, error) {
+	seq, err := s.store.Checkpoint(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("read checkpoint: %w", err)
+	}
+	stats := Stats{Seq: seq}
+
+	for {
+		page, err := s.source.Changes(ctx, stats.Seq, repository.ChangesOptions{Limit: s.batchSize})
+		if err != nil {
+			return stats, fmt.Errorf("read changes: %w", err)
+		}
+		if err := s.apply(ctx, page, &stats); err != nil {
+			return stats, err
+		}
+		if page.Pending == 0 || len(page.Changes) == 0 {
+			return stats, nil
+		}
+	}
+}
+
+// Run catches up like SyncOnce and then keeps waiting for and applying new
+// changes until ctx is done, which ends it without an error. Retryable
+// backend failures are retried with exponential backoff.
+func (s *Syncer) Run(ctx context.Context) (Stats, error) {
+	seq, err := s.store.Checkpoint(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("read checkpoint: %w", err)
+	}
+	stats := Stats{Seq: seq}
+	delay := s.retryDelay
+
+	for {
+		// Long-poll requests return at once while changes are pending.
+		opts := repository.ChangesOptions{Limit: s.batchSize, Wait: s.pollTimeout}
+		page, err := s.source.Changes(ctx, stats.Seq, opts)
+		switch {
+		case ctx.Err() != nil:
+			return stats, nil
+		case err != nil && isRetryable(err):
+			s.logger.WarnContext(ctx, "reading changes failed, retrying", "error", err, "delay", delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return stats, nil
+			}
+			delay = min(delay*2, maxRetryDelay)
+			continue
+		case err != nil:
+			return stats, fmt.Errorf("read changes: %w", err)
+		}
+		delay = s.retryDelay
+
+		if len(page.Changes) == 0 && page.LastSeq == stats.Seq {
+			continue
+		}
+		if err := s.apply(ctx, page, &stats); err != nil {
+			return stats, err
+		}
+	}
+}
+
+// apply writes one page of changes to the store. Later changes to the same
+// document within the page win.
+func (s *Syncer) apply(ctx context.Context, page repository.ChangesPage, stats *Stats) error {
+	latest := make(map[string]repository.Change, len(page.Changes))
+	order := make([]string, 0, len(page.Changes))
+	for _, change := range page.Changes {
+		if _, seen := latest[change.ID]; !seen {
+			order = append(order, change.ID)
+		}
+		latest[change.ID] = change
+	}
+
+	var (
+		upserts []model.Hub
+		deletes []string
+		skipped int
+	)
+	for _, id := range order {
+		change := latest[id]
+		switch {
+		case change.Deleted:
+			deletes = append(deletes, id)
+		case change.Warning != nil:
+			deletes = append(deletes, id)
+			skipped++
+			s.logger.WarnContext(ctx, "removing document that is not a valid hub", "id", id, "reason", change.Warning.Reason)
+		default:
+			upserts = append(upserts, change.Hub)
+		}
+	}
+
+	seq := page.LastSeq
+	if seq == "" {
+		seq = stats.Seq
+	}
+	if err := s.store.Apply(ctx, upserts, deletes, seq); err != nil {
+		return fmt.Errorf("apply changes: %w", err)
+	}
+
+	stats.Batches++
+	stats.Upserted += len(upserts)
+	stats.Deleted += len(deletes) - skipped
+	stats.Skipped += skipped
+	stats.Seq = seq
+	s.logger.InfoContext(ctx, "changes applied",
+		"upserted", len(upserts),
+		"deleted", len(deletes),
+		"seq", seq,
+		"pending", page.Pending,
+	)
+	return nil
+}
+
+// isRetryable reports whether reading the feed may succeed if repeated.
+func isRetryable(err error) bool {
+	var backendErr *repository.BackendError
+	if errors.As(err, &backendErr) {
+		return backendErr.Retryable
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
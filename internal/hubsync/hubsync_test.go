@@ -0,0 +1,308 @@
+package hubsync_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/hubsync"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// fakeChanges serves a CouchDB-style _changes feed from an in-memory log of
+// document revisions. Sequences are positions in the log.
+type fakeChanges struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	log     []map[string]any
+	changed chan struct{}
+	// failStatus and failures make the next requests fail with that status.
+	failStatus int
+	failures   int
+	sinces     []string
+}
+
+func newFakeChanges(t *testing.T) *fakeChanges {
+	t.Helper()
+
+	f := &fakeChanges{t: t, changed: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{db}/_changes", f.handleChanges)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeChanges) put(id string, fields map[string]any) {
+	doc := map[string]any{"_id": id, "_rev": "1-abc"}
+	for k, v := range fields {
+		doc[k] = v
+	}
+	f.append(map[string]any{"id": id, "doc": doc})
+}
+
+func (f *fakeChanges) putHub(hub model.Hub) {
+	f.put(hub.ID, map[string]any{"lat": hub.Lat, "lon": hub.Lon, "name": hub.Name})
+}
+
+func (f *fakeChanges) remove(id string) {
+	f.append(map[string]any{"id": id, "deleted": true, "doc": map[string]any{"_id": id, "_rev": "2-def", "_deleted": true}})
+}
+
+func (f *fakeChanges) append(entry map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, entry)
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeChanges) failNext(status, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failStatus, f.failures = status, n
+}
+
+func (f *fakeChanges) handleChanges(w http.ResponseWriter, r *http.Request) {
+	// Reading the body lets the server notice when a long-poll client
+	// goes away.
+	io.Copy(io.Discard, r.Body)
+	query := r.URL.Query()
+	since, _ := strconv.Atoi(query.Get("since"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	timeout, _ := strconv.Atoi(query.Get("timeout"))
+
+	f.mu.Lock()
+	f.sinces = append(f.sinces, query.Get("since"))
+	if f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.failStatus)
+		json.NewEncoder(w).Encode(map[string]any{"error": "failed", "reason": "injected"})
+		return
+	}
+	if since >= len(f.log) && query.Get("feed") == "longpoll" {
+		changed := f.changed
+		f.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(time.Duration(timeout) * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		f.mu.Lock()
+	}
+	entries := f.log[min(since, len(f.log)):]
+	f.mu.Unlock()
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	results := make([]map[string]any, 0, len(entries))
+	for i, entry := range entries {
+		result := map[string]any{
+			"seq":     strconv.Itoa(since + i + 1),
+			"changes": []any{map[string]any{"rev": "1-abc"}},
+		}
+		for k, v := range entry {
+			result[k] = v
+		}
+		results = append(results, result)
+	}
+
+	f.mu.Lock()
+	pending := len(f.log) - since - len(entries)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"results":  results,
+		"last_seq": strconv.Itoa(since + len(entries)),
+		"pending":  pending,
+	})
+}
+
+func (f *fakeChanges) requestedSinces() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.sinces)
+}
+
+type testEnv struct {
+	fake   *fakeChanges
+	path   string
+	store  *hubsync.FileStore
+	syncer *hubsync.Syncer
+}
+
+func newTestEnv(t *testing.T, cfg hubsync.Config) *testEnv {
+	t.Helper()
+
+	fake := newFakeChanges(t)
+	source, err := repository.NewCloudantRepository(repository.CloudantConfig{BaseURL: fake.server.URL, DB: "airportdb"})
+	if err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "hubs.json")
+	store, err := hubsync.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+
+	cfg.Source = source
+	cfg.Store = store
+	cfg.Logger = slog.New(slog.DiscardHandler)
+	syncer, err := hubsync.New(cfg)
+	if err != nil {
+		t.Fatalf("create syncer: %v", err)
+	}
+	return &testEnv{fake: fake, path: path, store: store, syncer: syncer}
+}
+
+// storedHubs reads the hubs currently in the snapshot file by ID.
+func (e *testEnv) storedHubs(t *testing.T) map[string]model.Hub {
+	t.Helper()
+	snapshot, err := repository.LoadSnapshot(e.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	hubs := map[string]model.Hub{}
+	for _, hub := range snapshot.Hubs {
+		hubs[hub.ID] = hub
+	}
+	return hubs
+}
+
+var (
+	bud = model.Hub{ID: "bud", Name: "Budapest", Lat: 47.437, Lon: 19.261}
+	vie = model.Hub{ID: "vie", Name: "Vienna", Lat: 48.11, Lon: 16.57}
+	prg = model.Hub{ID: "prg", Name: "Prague", Lat: 50.101, Lon: 14.26}
+)
+
+func TestSyncOnce(t *testing.T) {
+	env := newTestEnv(t, hubsync.Config{BatchSize: 2})
+	env.fake.putHub(bud)
+	env.fake.putHub(vie)
+	env.fake.put("_design/view1", map[string]any{"indexes": map[string]any{}})
+	env.fake.put("broken", map[string]any{"lat": 1.0, "name": "No longitude"})
+
+	stats, err := env.syncer.SyncOnce(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Batches != 2 || stats.Upserted != 2 || stats.Skipped != 1 || stats.Seq != "4" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if got := env.storedHubs(t); len(got) != 2 || got["bud"] != bud || got["vie"] != vie {
+		t.Errorf("unexpected store contents %v", got)
+	}
+
+	renamed := vie
+	renamed.Name = "Vienna International"
+	env.fake.putHub(renamed)
+	env.fake.remove("bud")
+	env.fake.putHub(prg)
+
+	stats, err = env.syncer.SyncOnce(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Upserted != 2 || stats.Deleted != 1 || stats.Seq != "7" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if got := env.storedHubs(t); len(got) != 2 || got["vie"] != renamed || got["prg"] != prg {
+		t.Errorf("unexpected store contents %v", got)
+	}
+	if sinces := env.fake.requestedSinces(); sinces[len(sinces)-2] != "4" {
+		t.Errorf("expected the second sync to resume from the checkpoint, got requests since %q", sinces)
+	}
+
+	reopened, err := hubsync.OpenFileStore(env.path)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	if seq, _ := reopened.Checkpoint(context.Background()); seq != "7" || reopened.Len() != 2 {
+		t.Errorf("expected the checkpoint and hubs to persist, got seq %q and %d hubs", seq, reopened.Len())
+	}
+}
+
+func TestSyncOnce_LaterChangeInBatchWins(t *testing.T) {
+	env := newTestEnv(t, hubsync.Config{})
+	env.fake.putHub(bud)
+	env.fake.remove("bud")
+	env.fake.putHub(vie)
+
+	if _, err := env.syncer.SyncOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := env.storedHubs(t); len(got) != 1 || got["vie"] != vie {
+		t.Errorf("unexpected store contents %v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	env := newTestEnv(t, hubsync.Config{PollTimeout: 5 * time.Second, RetryDelay: time.Millisecond})
+	env.fake.putHub(bud)
+	env.fake.failNext(http.StatusServiceUnavailable, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := env.syncer.Run(ctx)
+		done <- err
+	}()
+
+	waitFor(t, func() bool { _, ok := env.storedHubs(t)["bud"]; return ok })
+	env.fake.putHub(vie)
+	waitFor(t, func() bool { _, ok := env.storedHubs(t)["vie"]; return ok })
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected Run to stop cleanly, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop after cancellation")
+	}
+}
+
+func TestRun_PermanentFailure(t *testing.T) {
+	env := newTestEnv(t, hubsync.Config{RetryDelay: time.Millisecond})
+	env.fake.failNext(http.StatusUnauthorized, 1)
+
+	if _, err := env.syncer.Run(context.Background()); err == nil {
+		t.Fatal("expected an error for a rejected request")
+	}
+}
+
+// waitFor polls cond until it holds or a few seconds pass.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met in time")
+}
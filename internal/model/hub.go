@@ -1,13 +1,46 @@
 package model
 
+import "time"
+
 type Hub struct {
 	ID   string  `json:"id"`
 	Lat  float64 `json:"lat"`
 	Lon  float64 `json:"lon"`
 	Name string  `json:"name"`
+	// Type is the kind of hub, such as large_airport or heliport, if the
+	// backend records it.
+	Type string `json:"type,omitempty"`
+	// Country is the country code of the hub, such as HU, if the backend
+	// records it.
+	Country string `json:"country,omitempty"`
+	// Routes is the number of scheduled routes served, if known.
+	Routes int `json:"routes,omitempty"`
+	// PassengerClass ranks the hub by passenger numbers as large, medium,
+	// small or nonhub, if known.
+	PassengerClass string `json:"passenger_class,omitempty"`
+	// Source names the repository the hub came from when several are
+	// combined with repository.MultiRepository. It is empty otherwise.
+	Source string `json:"source,omitempty"`
 }
 
 type HubWithDistance struct {
 	Hub
 	DistanceKm float64 `json:"distance_km"`
+	// TravelTime is the estimated time to reach the hub. It is only set
+	// when the Finder has a travel estimator.
+	TravelTime time.Duration `json:"travel_time_ns,omitempty"`
+	// Score is the hub's score from the Finder's Scorer, if it has one, and
+	// ScoreFactors the terms it is made of.
+	Score        float64       `json:"score,omitempty"`
+	ScoreFactors []ScoreFactor `json:"score_factors,omitempty"`
+}
+
+// ScoreFactor is one term of a hub's score.
+type ScoreFactor struct {
+	Name string `json:"name"`
+	// Value rates the hub on this factor alone, from 0 (worst) to 1 (best).
+	Value float64 `json:"value"`
+	// Weight is the share of the score the factor makes up. The weights of
+	// a score add up to 1, so the score is the sum of Value times Weight.
+	Weight float64 `json:"weight"`
 }
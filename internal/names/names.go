@@ -0,0 +1,84 @@
+// Package names compares hub names that may be spelled differently in
+// different data sets.
+package names
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize lower-cases name, strips accents and punctuation and collapses
+// whitespace, so that "Zürich-Flughafen" and "zurich flughafen" compare
+// equal.
+func Normalize(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks left over from decomposing accented letters.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Similarity scores how alike two names are, from 0 for nothing in common
+// to 1 for names that are equal once normalized. It is the larger of the
+// edit-distance similarity of the normalized names and the share of words
+// they have in common, so reordered words still score high.
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == b {
+		return 1
+	}
+	if a == "" || b == "" {
+		return 0
+	}
+	return max(editSimilarity(a, b), wordOverlap(a, b))
+}
+
+// editSimilarity is one minus the Levenshtein distance between a and b
+// divided by the length of the longer one, counted in runes.
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// wordOverlap is the Jaccard index of the word sets of a and b.
+func wordOverlap(a, b string) float64 {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	slices.Sort(wa)
+	wa = slices.Compact(wa)
+	slices.Sort(wb)
+	wb = slices.Compact(wb)
+
+	common := 0
+	for _, w := range wa {
+		if _, found := slices.BinarySearch(wb, w); found {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wa)+len(wb)-common)
+}
@@ -0,0 +1,51 @@
+package names
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Zürich-Flughafen", "zurich flughafen"},
+		{"  São  Paulo/Guarulhos ", "sao paulo guarulhos"},
+		{"O'Hare Int'l (ORD)", "o hare int l ord"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"Budapest Airport", "budapest  airport", 1},
+		{"Budapest Airport", "Airport Budapest", 1},
+		{"Heathrow", "Heathrow Airport", 0.5},
+		{"Heathrow", "Heathrw", 0.875},
+		{"Heathrow", "", 0},
+		{"abc", "xyz", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+			if got := Similarity(tt.b, tt.a); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("reversed: got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
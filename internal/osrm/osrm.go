@@ -0,0 +1,208 @@
+// Package osrm estimates travel times with the table service of an
+// OSRM-compatible routing engine.
+package osrm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// Compile-time check that the estimator can be used by a Finder.
+var _ finder.TravelEstimator = (*Estimator)(nil)
+
+const (
+	defaultProfile   = "driving"
+	defaultBatchSize = 100
+	defaultTimeout   = 10 * time.Second
+)
+
+// Estimator asks a routing engine for the travel time to every hub.
+type Estimator struct {
+	baseURL   string
+	profile   string
+	client    *http.Client
+	fallback  finder.TravelEstimator
+	batchSize int
+	logger    *slog.Logger
+}
+
+type Config struct {
+	// BaseURL is the address of the routing engine, such as
+	// http://localhost:5000.
+	BaseURL string
+	// Profile is the routing profile the engine was built with, such as
+	// driving or foot. Defaults to driving.
+	Profile string
+	// Client sends the requests. Defaults to a client with a 10 second
+	// timeout.
+	Client *http.Client
+	// Fallback estimates the hubs the engine cannot route to, such as
+	// islands without a ferry. Without it, such hubs are an error.
+	Fallback finder.TravelEstimator
+	// BatchSize caps the number of hubs per request, to stay below the
+	// engine's table size limit. Defaults to 100.
+	BatchSize int
+	// Logger receives request messages. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+func New(cfg Config) (*Estimator, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("osrm: invalid base URL %q", cfg.BaseURL)
+	}
+	if strings.ContainsAny(cfg.Profile, "/?#") {
+		return nil, fmt.Errorf("osrm: invalid profile %q", cfg.Profile)
+	}
+
+	e := &Estimator{
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		profile:   cfg.Profile,
+		client:    cfg.Client,
+		fallback:  cfg.Fallback,
+		batchSize: cfg.BatchSize,
+		logger:    cfg.Logger,
+	}
+	if e.profile == "" {
+		e.profile = defaultProfile
+	}
+	if e.client == nil {
+		e.client = &http.Client{Timeout: defaultTimeout}
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = defaultBatchSize
+	}
+	if e.logger == nil {
+		e.logger = slog.Default()
+	}
+	e.logger = e.logger.With("component", "osrm", "profile", e.profile)
+	return e, nil
+}
+
+// EstimateTravel implements finder.TravelEstimator. Hubs are sent in
+// batches of Config.BatchSize, each as one table request from origin.
+func (e *Estimator) EstimateTravel(ctx context.Context, origin geo.Point, hubs []model.HubWithDistance) ([]time.Duration, error) {
+	durations := make([]time.Duration, len(hubs))
+	var unroutable []int
+	for start := 0; start < len(hubs); start += e.batchSize {
+		batch := hubs[start:min(start+e.batchSize, len(hubs))]
+		seconds, err := e.table(ctx, origin, batch)
+		if err != nil {
+			return nil, err
+		}
+		for i, s := range seconds {
+			if s == nil {
+				unroutable = append(unroutable, start+i)
+				continue
+			}
+			durations[start+i] = time.Duration(math.Round(*s * float64(time.Second)))
+		}
+	}
+	if len(unroutable) == 0 {
+		return durations, nil
+	}
+
+	if e.fallback == nil {
+		return nil, fmt.Errorf("osrm: no route to hub %s", hubs[unroutable[0]].ID)
+	}
+	e.logger.DebugContext(ctx, "estimating unroutable hubs with fallback", "hubs", len(unroutable))
+	rest := make([]model.HubWithDistance, len(unroutable))
+	for i, index := range unroutable {
+		rest[i] = hubs[index]
+	}
+	estimated, err := e.fallback.EstimateTravel(ctx, origin, rest)
+	if err != nil {
+		return nil, fmt.Errorf("osrm: fallback: %w", err)
+	}
+	if len(estimated) != len(rest) {
+		return nil, fmt.Errorf("osrm: fallback returned %d durations for %d hubs", len(estimated), len(rest))
+	}
+	for i, index := range unroutable {
+		durations[index] = estimated[i]
+	}
+	return durations, nil
+}
+
+// tableResponse is the part of a table service response that is used.
+// Durations holds one row per source; unroutable destinations are null.
+type tableResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Durations [][]*float64 `json:"durations"`
+}
+
+// table returns the travel time in seconds from origin to each of hubs,
+// or nil for hubs without a route.
+func (e *Estimator) table(ctx context.Context, origin geo.Point, hubs []model.HubWithDistance) ([]*float64, error) {
+	start := time.Now()
+
+	coordinates := make([]string, 0, len(hubs)+1)
+	coordinates = append(coordinates, formatCoordinate(origin.Lat, origin.Lon))
+	for _, hub := range hubs {
+		coordinates = append(coordinates, formatCoordinate(hub.Lat, hub.Lon))
+	}
+	destinations := make([]string, len(hubs))
+	for i := range hubs {
+		destinations[i] = strconv.Itoa(i + 1)
+	}
+	query := url.Values{
+		"sources":      {"0"},
+		"destinations": {strings.Join(destinations, ";")},
+		"annotations":  {"duration"},
+	}
+	endpoint := fmt.Sprintf("%s/table/v1/%s/%s?%s", e.baseURL, e.profile, strings.Join(coordinates, ";"), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("osrm: build request: %w", err)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("osrm: table request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var table tableResponse
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("osrm: read response: %w", err)
+	}
+	if err := json.Unmarshal(body, &table); err != nil {
+		return nil, fmt.Errorf("osrm: table request: %s: invalid response: %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || table.Code != "Ok" {
+		return nil, fmt.Errorf("osrm: table request: %s: %s %s", resp.Status, table.Code, table.Message)
+	}
+	if len(table.Durations) != 1 || len(table.Durations[0]) != len(hubs) {
+		return nil, fmt.Errorf("osrm: table request: got durations for %d sources and %d destinations, want 1 and %d",
+			len(table.Durations), rowLength(table.Durations), len(hubs))
+	}
+
+	e.logger.DebugContext(ctx, "table request finished", "hubs", len(hubs), "duration", time.Since(start))
+	return table.Durations[0], nil
+}
+
+// formatCoordinate writes a point in the lon,lat order the engine expects.
+func formatCoordinate(lat, lon float64) string {
+	return strconv.FormatFloat(lon, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+}
+
+func rowLength(rows [][]*float64) int {
+	if len(rows) == 0 {
+		return 0
+	}
+	return len(rows[0])
+}
@@ -0,0 +1,133 @@
+package osrm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/finder"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// fakeEngine answers table requests with a duration of 60 seconds per
+// degree of longitude from the origin, and null for destinations with a
+// negative latitude.
+type fakeEngine struct {
+	server   *httptest.Server
+	requests []string
+}
+
+func newFakeEngine(t *testing.T) *fakeEngine {
+	f := &fakeEngine{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.table))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeEngine) table(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.URL.Path)
+	coordinates, ok := strings.CutPrefix(r.URL.Path, "/table/v1/driving/")
+	if !ok || r.URL.Query().Get("sources") != "0" || r.URL.Query().Get("annotations") != "duration" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"code": "InvalidQuery", "message": "unexpected request " + r.URL.String()})
+		return
+	}
+
+	var points [][2]float64
+	for _, pair := range strings.Split(coordinates, ";") {
+		lon, lat, _ := strings.Cut(pair, ",")
+		lonValue, _ := strconv.ParseFloat(lon, 64)
+		latValue, _ := strconv.ParseFloat(lat, 64)
+		points = append(points, [2]float64{lonValue, latValue})
+	}
+	var row []*float64
+	for _, index := range strings.Split(r.URL.Query().Get("destinations"), ";") {
+		i, _ := strconv.Atoi(index)
+		if points[i][1] < 0 {
+			row = append(row, nil)
+			continue
+		}
+		row = append(row, new((points[i][0]-points[0][0])*60))
+	}
+	json.NewEncoder(w).Encode(map[string]any{"code": "Ok", "durations": [][]*float64{row}})
+}
+
+func hubsAt(lons ...float64) []model.HubWithDistance {
+	hubs := make([]model.HubWithDistance, len(lons))
+	for i, lon := range lons {
+		hubs[i] = model.HubWithDistance{Hub: model.Hub{ID: strconv.Itoa(i), Lat: 1, Lon: lon}, DistanceKm: lon * 100}
+	}
+	return hubs
+}
+
+func TestEstimateTravel(t *testing.T) {
+	engine := newFakeEngine(t)
+	e, err := New(Config{BaseURL: engine.server.URL + "/", BatchSize: 2})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	got, err := e.EstimateTravel(context.Background(), geo.Point{Lat: 1, Lon: 0}, hubsAt(1, 2.5, 10))
+	if err != nil {
+		t.Fatalf("EstimateTravel returned error: %v", err)
+	}
+	want := []time.Duration{time.Minute, 150 * time.Second, 10 * time.Minute}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	wantRequests := []string{"/table/v1/driving/0,1;1,1;2.5,1", "/table/v1/driving/0,1;10,1"}
+	if !slices.Equal(engine.requests, wantRequests) {
+		t.Errorf("got requests %v, want %v", engine.requests, wantRequests)
+	}
+}
+
+func TestEstimateTravel_Unroutable(t *testing.T) {
+	engine := newFakeEngine(t)
+	hubs := hubsAt(1, 2)
+	hubs[1].Lat = -1
+
+	e, err := New(Config{BaseURL: engine.server.URL})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if _, err := e.EstimateTravel(context.Background(), geo.Point{Lat: 1, Lon: 0}, hubs); err == nil || !strings.Contains(err.Error(), "no route to hub 1") {
+		t.Errorf("got error %v, want no route to hub 1", err)
+	}
+
+	fallback := finder.Profile{Name: "test", SpeedKmh: 100, DetourFactor: 1}
+	e, err = New(Config{BaseURL: engine.server.URL, Fallback: fallback})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	got, err := e.EstimateTravel(context.Background(), geo.Point{Lat: 1, Lon: 0}, hubs)
+	if err != nil {
+		t.Fatalf("EstimateTravel returned error: %v", err)
+	}
+	if want := []time.Duration{time.Minute, 2 * time.Hour}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEstimateTravel_Errors(t *testing.T) {
+	engine := newFakeEngine(t)
+	e, err := New(Config{BaseURL: engine.server.URL, Profile: "foot"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if _, err := e.EstimateTravel(context.Background(), geo.Point{}, hubsAt(1)); err == nil || !strings.Contains(err.Error(), "InvalidQuery") {
+		t.Errorf("got error %v, want the engine's error code", err)
+	}
+
+	for _, cfg := range []Config{{}, {BaseURL: "localhost:5000"}, {BaseURL: engine.server.URL, Profile: "car/x"}} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) returned no error", cfg)
+		}
+	}
+}
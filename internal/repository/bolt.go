@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Compile-time check that BoltRepository implements IDGetter.
+var _ IDGetter = (*BoltRepository)(nil)
+
+var (
+	// boltGeoBucket maps a spatial key followed by the hub ID to the hub.
+	boltGeoBucket = []byte("geo")
+	// boltIDBucket maps a hub ID to the spatial key of its entry in boltGeoBucket.
+	boltIDBucket = []byte("ids")
+	// boltMetaBucket holds the sync checkpoint.
+	boltMetaBucket = []byte("meta")
+	boltSeqKey     = []byte("seq")
+)
+
+const (
+	// spatialKeyBits is the number of bits per axis in a spatial key.
+	spatialKeyBits = 32
+	// maxScanCells caps the number of index ranges scanned for one box.
+	maxScanCells = 64
+)
+
+// BoltRepository stores hubs in a bbolt database file. Hubs are indexed by a
+// 64-bit geohash, so a bounds query reads only the index ranges of the cells
+// covering the box, and opening the store does not load any hubs.
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+// OpenBoltRepository opens or creates the database at path. Only one process
+// can have the file open at a time.
+func OpenBoltRepository(path string) (*BoltRepository, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open bolt store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltGeoBucket, boltIDBucket, boltMetaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize bolt store %s: %w", path, err)
+	}
+	return &BoltRepository{db: db}, nil
+}
+
+// Close releases the database file.
+func (r *BoltRepository) Close() error {
+	return r.db.Close()
+}
+
+func (r *BoltRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	if minLat > maxLat {
+		return []model.Hub{}, nil
+	}
+	boxes := [][4]float64{{minLat, maxLat, minLon, maxLon}}
+	if minLon > maxLon {
+		boxes = [][4]float64{{minLat, maxLat, minLon, 180}, {minLat, maxLat, -180, maxLon}}
+	}
+
+	hubs := make([]model.Hub, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltGeoBucket).Cursor()
+		for _, box := range boxes {
+			for _, rng := range coveringRanges(box[0], box[1], box[2], box[3]) {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				for k, v := cursor.Seek(spatialKeyBytes(rng[0])); k != nil && binary.BigEndian.Uint64(k) <= rng[1]; k, v = cursor.Next() {
+					var hub model.Hub
+					if err := json.Unmarshal(v, &hub); err != nil {
+						return fmt.Errorf("decode hub %q: %w", k[8:], err)
+					}
+					if hub.Lat >= box[0] && hub.Lat <= box[1] && hub.Lon >= box[2] && hub.Lon <= box[3] {
+						hubs = append(hubs, hub)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hubs, nil
+}
+
+func (r *BoltRepository) GetHub(_ context.Context, id string) (model.Hub, error) {
+	var hub model.Hub
+	err := r.db.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(boltIDBucket).Get([]byte(id))
+		if key == nil {
+			return fmt.Errorf("hub %q: %w", id, ErrNotFound)
+		}
+		return json.Unmarshal(tx.Bucket(boltGeoBucket).Get(geoEntryKey(key, id)), &hub)
+	})
+	return hub, err
+}
+
+// Upsert inserts hubs or replaces the hubs with the same IDs. Coordinates
+// are validated with the geo rules and nothing is written if any is invalid.
+func (r *BoltRepository) Upsert(_ context.Context, hubs []model.Hub) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return putHubs(tx, hubs)
+	})
+}
+
+// Delete removes the hubs with the given IDs. Unknown IDs are ignored.
+func (r *BoltRepository) Delete(_ context.Context, ids []string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return deleteHubs(tx, ids)
+	})
+}
+
+// Checkpoint returns the changes feed sequence recorded by Apply.
+func (r *BoltRepository) Checkpoint(context.Context) (string, error) {
+	var seq string
+	err := r.db.View(func(tx *bolt.Tx) error {
+		seq = string(tx.Bucket(boltMetaBucket).Get(boltSeqKey))
+		return nil
+	})
+	return seq, err
+}
+
+// Apply deletes and upserts hubs and records seq as the checkpoint in a
+// single transaction, so it can serve as the target of hubsync.
+func (r *BoltRepository) Apply(_ context.Context, upserts []model.Hub, deletes []string, seq string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if err := deleteHubs(tx, deletes); err != nil {
+			return err
+		}
+		if err := putHubs(tx, upserts); err != nil {
+			return err
+		}
+		return tx.Bucket(boltMetaBucket).Put(boltSeqKey, []byte(seq))
+	})
+}
+
+// Len returns the number of hubs in the store.
+func (r *BoltRepository) Len() int {
+	n := 0
+	_ = r.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(boltIDBucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+func putHubs(tx *bolt.Tx, hubs []model.Hub) error {
+	geoBucket, idBucket := tx.Bucket(boltGeoBucket), tx.Bucket(boltIDBucket)
+	for _, hub := range hubs {
+		if err := ValidateHub(hub); err != nil {
+			return fmt.Errorf("hub %q: %w", hub.ID, err)
+		}
+		if err := deleteHubs(tx, []string{hub.ID}); err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(hub)
+		if err != nil {
+			return fmt.Errorf("encode hub %q: %w", hub.ID, err)
+		}
+		key := spatialKeyBytes(spatialKey(hub.Lat, hub.Lon))
+		if err := geoBucket.Put(geoEntryKey(key, hub.ID), value); err != nil {
+			return err
+		}
+		if err := idBucket.Put([]byte(hub.ID), key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteHubs(tx *bolt.Tx, ids []string) error {
+	geoBucket, idBucket := tx.Bucket(boltGeoBucket), tx.Bucket(boltIDBucket)
+	for _, id := range ids {
+		key := idBucket.Get([]byte(id))
+		if key == nil {
+			continue
+		}
+		if err := geoBucket.Delete(geoEntryKey(key, id)); err != nil {
+			return err
+		}
+		if err := idBucket.Delete([]byte(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// geoEntryKey appends the hub ID to a spatial key, so hubs sharing a
+// position get distinct entries.
+func geoEntryKey(spatial []byte, id string) []byte {
+	return append(bytes.Clone(spatial), id...)
+}
+
+// cellIndex returns the index of the cell containing value when the range
+// [min, min+span] is split into 2^bits cells. The upper edge belongs to the
+// last cell.
+func cellIndex(value, min, span float64, bits int) uint64 {
+	cells := uint64(1) << bits
+	index := uint64((value - min) / span * float64(cells))
+	return index - index/cells // clamps cells to cells-1
+}
+
+// spatialKey interleaves the longitude and latitude cell indices bit by bit,
+// longitude first. The result orders points like their geohashes, so every
+// geohash cell is one contiguous key range.
+func spatialKey(lat, lon float64) uint64 {
+	x := cellIndex(lon, -180, 360, spatialKeyBits)
+	y := cellIndex(lat, -90, 180, spatialKeyBits)
+	return interleave(x, y)
+}
+
+func interleave(x, y uint64) uint64 {
+	var key uint64
+	for bit := spatialKeyBits - 1; bit >= 0; bit-- {
+		key = key<<1 | (x>>bit)&1
+		key = key<<1 | (y>>bit)&1
+	}
+	return key
+}
+
+func spatialKeyBytes(key uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, key)
+}
+
+// coveringRanges returns the inclusive key ranges of the geohash cells that
+// cover the box, using the finest level at which at most maxScanCells cells
+// are needed.
+func coveringRanges(minLat, maxLat, minLon, maxLon float64) [][2]uint64 {
+	level := spatialKeyBits
+	var x0, x1, y0, y1 uint64
+	for ; level >= 0; level-- {
+		x0, x1 = cellIndex(minLon, -180, 360, level), cellIndex(maxLon, -180, 360, level)
+		y0, y1 = cellIndex(minLat, -90, 180, level), cellIndex(maxLat, -90, 180, level)
+		// Check each side first so the product cannot overflow.
+		if x1-x0 < maxScanCells && y1-y0 < maxScanCells && (x1-x0+1)*(y1-y0+1) <= maxScanCells {
+			break
+		}
+	}
+
+	shift := 2 * (spatialKeyBits - level)
+	ranges := make([][2]uint64, 0, (x1-x0+1)*(y1-y0+1))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			prefix := interleave(x<<(spatialKeyBits-level), y<<(spatialKeyBits-level)) >> shift
+			start := prefix << shift
+			ranges = append(ranges, [2]uint64{start, start | (uint64(1)<<shift - 1)})
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,143 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+)
+
+func openTestBoltRepository(t *testing.T, path string) *repository.BoltRepository {
+	t.Helper()
+	repo, err := repository.OpenBoltRepository(path)
+	if err != nil {
+		t.Fatalf("open bolt repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestBoltRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		repo := openTestBoltRepository(t, filepath.Join(t.TempDir(), "hubs.db"))
+		if err := repo.Upsert(context.Background(), hubs); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		return repo
+	})
+}
+
+func boundIDs(t *testing.T, repo repository.Repository, minLat, maxLat, minLon, maxLon float64) []string {
+	t.Helper()
+	hubs, err := repo.GetByBounds(context.Background(), minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := make([]string, 0, len(hubs))
+	for _, h := range hubs {
+		ids = append(ids, h.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestBoltRepository_UpsertAndDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestBoltRepository(t, filepath.Join(t.TempDir(), "hubs.db"))
+	if err := repo.Upsert(ctx, repositorytest.Fixture()); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	// Moving a hub must drop it from its old index position.
+	if err := repo.Upsert(ctx, []model.Hub{{ID: "bud", Name: "Budapest", Lat: 20.5, Lon: 30.5}}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if got := boundIDs(t, repo, 47, 48, 19, 20); len(got) != 0 {
+		t.Errorf("old position = %v, want empty", got)
+	}
+	if got, want := boundIDs(t, repo, 20, 21, 30, 31), []string{"bud", "corner-ne", "corner-sw"}; !slices.Equal(got, want) {
+		t.Errorf("new position = %v, want %v", got, want)
+	}
+
+	if err := repo.Delete(ctx, []string{"corner-sw", "missing"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got, want := boundIDs(t, repo, 20, 21, 30, 31), []string{"bud", "corner-ne"}; !slices.Equal(got, want) {
+		t.Errorf("after delete = %v, want %v", got, want)
+	}
+	if _, err := repo.GetHub(ctx, "corner-sw"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if got, want := repo.Len(), len(repositorytest.Fixture())-1; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+}
+
+func TestBoltRepository_RejectsInvalidHubs(t *testing.T) {
+	tests := []struct {
+		name string
+		hub  model.Hub
+		want error
+	}{
+		{name: "latitude", hub: model.Hub{ID: "a", Lat: 91}, want: geo.ErrInvalidLatitude},
+		{name: "longitude", hub: model.Hub{ID: "a", Lon: -181}, want: geo.ErrInvalidLongitude},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := openTestBoltRepository(t, filepath.Join(t.TempDir(), "hubs.db"))
+			valid := model.Hub{ID: "valid", Lat: 1, Lon: 1}
+			if err := repo.Upsert(context.Background(), []model.Hub{valid, tt.hub}); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if repo.Len() != 0 {
+				t.Errorf("expected a failed upsert to write nothing, got %d hubs", repo.Len())
+			}
+		})
+	}
+}
+
+func TestBoltRepository_PersistsAcrossReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "hubs.db")
+
+	repo, err := repository.OpenBoltRepository(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	upserts := []model.Hub{{ID: "bud", Name: "Budapest", Lat: 47.437, Lon: 19.261}, {ID: "lhr", Name: "Heathrow", Lat: 51.47, Lon: -0.454}}
+	if err := repo.Apply(ctx, upserts, nil, "1-abc"); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if err := repo.Apply(ctx, nil, []string{"lhr"}, "2-def"); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	repo = openTestBoltRepository(t, path)
+	seq, err := repo.Checkpoint(ctx)
+	if err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	if seq != "2-def" {
+		t.Errorf("Checkpoint() = %q, want %q", seq, "2-def")
+	}
+	if got, want := boundIDs(t, repo, -90, 90, -180, 180), []string{"bud"}; !slices.Equal(got, want) {
+		t.Errorf("hubs = %v, want %v", got, want)
+	}
+	hub, err := repo.GetHub(ctx, "bud")
+	if err != nil {
+		t.Fatalf("get hub: %v", err)
+	}
+	if hub != upserts[0] {
+		t.Errorf("got %+v, want %+v", hub, upserts[0])
+	}
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+)
+
+// Compile-time checks that CachedRepository implements the optional
+// repository interfaces.
+var (
+	_ DetailedRepository = (*CachedRepository)(nil)
+	_ IDGetter           = (*CachedRepository)(nil)
+)
+
+const (
+	defaultCacheTTL        = 5 * time.Minute
+	defaultCacheMaxEntries = 1000
+)
+
+// CachedRepository remembers the results of bounds queries for a while, so
+// that repeated searches of the same area do not reach the backend. Lookups
+// are counted as hits or misses in the cache metrics.
+//
+// Only bounds queries are cached. GetHub is passed through, and the other
+// capabilities of the wrapped repository, such as RadiusSearcher, are not
+// offered, so a Finder searches it by bounding box.
+type CachedRepository struct {
+	inner      Repository
+	ttl        time.Duration
+	maxEntries int
+	metrics    *telemetry.Metrics
+	now        func() time.Time
+
+	mu sync.Mutex
+	// entries indexes the elements of order, which runs from the most to
+	// the least recently used.
+	entries map[bounds]*list.Element
+	order   *list.List
+}
+
+type bounds struct {
+	minLat, maxLat, minLon, maxLon float64
+}
+
+type cacheEntry struct {
+	key     bounds
+	result  Result
+	expires time.Time
+}
+
+type CacheConfig struct {
+	Repository Repository
+	// TTL is how long a result is reused. Defaults to 5 minutes.
+	TTL time.Duration
+	// MaxEntries caps the number of cached results; the least recently
+	// used are dropped first. Defaults to 1000.
+	MaxEntries int
+	// Metrics counts cache hits and misses. Nil disables metrics.
+	Metrics *telemetry.Metrics
+	// Now returns the current time for expiring results. Defaults to
+	// time.Now.
+	Now func() time.Time
+}
+
+func NewCachedRepository(cfg CacheConfig) (*CachedRepository, error) {
+	if cfg.Repository == nil {
+		return nil, fmt.Errorf("cached repository: repository is required")
+	}
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("cached repository: TTL cannot be negative")
+	}
+	if cfg.MaxEntries < 0 {
+		return nil, fmt.Errorf("cached repository: max entries cannot be negative")
+	}
+
+	r := &CachedRepository{
+		inner:      cfg.Repository,
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		metrics:    cfg.Metrics,
+		now:        cfg.Now,
+		entries:    make(map[bounds]*list.Element),
+		order:      list.New(),
+	}
+	if r.ttl == 0 {
+		r.ttl = defaultCacheTTL
+	}
+	if r.maxEntries == 0 {
+		r.maxEntries = defaultCacheMaxEntries
+	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+	return r, nil
+}
+
+func (r *CachedRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// GetByBoundsDetailed answers from the cache if the same bounds were
+// queried less than the TTL ago, and otherwise from the wrapped repository.
+// Errors and stale results, such as those of a FallbackRepository answering
+// from its snapshot, are not cached.
+func (r *CachedRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (Result, error) {
+	key := bounds{minLat, maxLat, minLon, maxLon}
+	if result, ok := r.lookup(key); ok {
+		r.metrics.ObserveCache("bounds", true)
+		return result, nil
+	}
+	r.metrics.ObserveCache("bounds", false)
+
+	var (
+		result Result
+		err    error
+	)
+	if detailed, ok := r.inner.(DetailedRepository); ok {
+		result, err = detailed.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	} else {
+		result.Hubs, err = r.inner.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	}
+	if err != nil || result.Stale {
+		return result, err
+	}
+	r.store(key, result)
+	return copyResult(result), nil
+}
+
+// GetHub looks the hub up in the wrapped repository without caching it.
+func (r *CachedRepository) GetHub(ctx context.Context, id string) (model.Hub, error) {
+	if getter, ok := r.inner.(IDGetter); ok {
+		return getter.GetHub(ctx, id)
+	}
+	return scanForHub(ctx, r.inner, id)
+}
+
+func (r *CachedRepository) lookup(key bounds) (Result, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		return Result{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !r.now().Before(entry.expires) {
+		r.order.Remove(element)
+		delete(r.entries, key)
+		return Result{}, false
+	}
+	r.order.MoveToFront(element)
+	return copyResult(entry.result), true
+}
+
+func (r *CachedRepository) store(key bounds, result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := &cacheEntry{key: key, result: copyResult(result), expires: r.now().Add(r.ttl)}
+	if element, ok := r.entries[key]; ok {
+		element.Value = entry
+		r.order.MoveToFront(element)
+		return
+	}
+	r.entries[key] = r.order.PushFront(entry)
+	for r.order.Len() > r.maxEntries {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// copyResult copies the slices of result, so that callers cannot change
+// the cached copy.
+func copyResult(result Result) Result {
+	result.Hubs = slices.Clone(result.Hubs)
+	result.Warnings = slices.Clone(result.Warnings)
+	result.SourceWarnings = slices.Clone(result.SourceWarnings)
+	return result
+}
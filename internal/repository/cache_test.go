@@ -0,0 +1,152 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// countingRepository counts the bounds queries that reach it.
+type countingRepository struct {
+	stubRepository
+	calls int
+}
+
+func (r *countingRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	r.calls++
+	return r.stubRepository.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+}
+
+func TestCachedRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		repo, err := repository.NewCachedRepository(repository.CacheConfig{Repository: repository.NewMemoryRepository(hubs)})
+		if err != nil {
+			t.Fatalf("create cached repository: %v", err)
+		}
+		return repo
+	})
+}
+
+func TestCachedRepository(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingRepository{stubRepository: stubRepository{hubs: liveHubs}}
+	now := snapshotTime
+	reg := prometheus.NewRegistry()
+	metrics, err := telemetry.NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo, err := repository.NewCachedRepository(repository.CacheConfig{
+		Repository: inner,
+		TTL:        time.Minute,
+		MaxEntries: 2,
+		Metrics:    metrics,
+		Now:        func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("create cached repository: %v", err)
+	}
+
+	query := func(minLat float64) []model.Hub {
+		t.Helper()
+		hubs, err := repo.GetByBounds(ctx, minLat, 90, -180, 180)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return hubs
+	}
+
+	hubs := query(0)
+	hubs[0].Name = "changed by the caller"
+	if got := query(0); got[0].Name != liveHubs[0].Name || inner.calls != 1 {
+		t.Errorf("got %v after %d backend calls, want the cached %v after 1", got, inner.calls, liveHubs)
+	}
+
+	query(10)
+	query(20)
+	query(0)
+	if inner.calls != 4 {
+		t.Errorf("got %d backend calls, want 4 after the oldest entry was dropped", inner.calls)
+	}
+
+	now = now.Add(time.Minute)
+	query(0)
+	if inner.calls != 5 {
+		t.Errorf("got %d backend calls, want 5 after the entry expired", inner.calls)
+	}
+
+	if got := cacheLookups(t, reg, "hit"); got != 1 {
+		t.Errorf("got %g cache hits, want 1", got)
+	}
+	if got := cacheLookups(t, reg, "miss"); got != 5 {
+		t.Errorf("got %g cache misses, want 5", got)
+	}
+}
+
+func TestCachedRepository_SkipsErrorsAndStaleResults(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingRepository{stubRepository: stubRepository{err: &repository.BackendError{Op: "post search", Retryable: true, Err: errors.New("down")}}}
+	fallback := newTestFallbackRepository(t, inner, 0)
+	repo, err := repository.NewCachedRepository(repository.CacheConfig{Repository: fallback})
+	if err != nil {
+		t.Fatalf("create cached repository: %v", err)
+	}
+
+	for range 2 {
+		result, err := repo.GetByBoundsDetailed(ctx, -90, 90, -180, 180)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Stale {
+			t.Errorf("got a fresh result, want the stale snapshot")
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("got %d backend calls, want 2 as stale results are not cached", inner.calls)
+	}
+
+	failing, err := repository.NewCachedRepository(repository.CacheConfig{Repository: inner})
+	if err != nil {
+		t.Fatalf("create cached repository: %v", err)
+	}
+	for range 2 {
+		if _, err := failing.GetByBounds(ctx, -90, 90, -180, 180); err == nil {
+			t.Error("expected error")
+		}
+	}
+	if inner.calls != 4 {
+		t.Errorf("got %d backend calls, want 4 as errors are not cached", inner.calls)
+	}
+}
+
+// cacheLookups returns the number of bounds cache lookups with the given
+// result recorded in reg.
+func cacheLookups(t *testing.T, reg *prometheus.Registry, result string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "hubfinder_cache_lookups_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["cache"] == "bounds" && labels["result"] == result {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// The helpers below ask a repository for a query natively when it implements
+// the matching optional interface and otherwise answer it with a bounds
+// query, so that repositories wrapping others can offer every capability.
+
+// getByBoundsDetailed runs a bounds query, using the detailed variant when
+// repo supports it.
+func getByBoundsDetailed(ctx context.Context, repo Repository, minLat, maxLat, minLon, maxLon float64) (Result, error) {
+	if detailed, ok := repo.(DetailedRepository); ok {
+		return detailed.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	}
+	hubs, err := repo.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	return Result{Hubs: hubs}, err
+}
+
+// getByRadius returns the hubs within radiusKm of a point.
+func getByRadius(ctx context.Context, repo Repository, lat, lon, radiusKm float64) (Result, error) {
+	if searcher, ok := repo.(RadiusSearcher); ok {
+		return searcher.GetByRadius(ctx, lat, lon, radiusKm)
+	}
+	minLat, maxLat, minLon, maxLon, err := geo.CalculateBoundingBox(lat, lon, radiusKm)
+	if err != nil {
+		return Result{}, err
+	}
+	result, err := getByBoundsDetailed(ctx, repo, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Hubs = withinRadius(result.Hubs, lat, lon, radiusKm)
+	return result, nil
+}
+
+// withinRadius removes the hubs farther than radiusKm from a point.
+func withinRadius(hubs []model.Hub, lat, lon, radiusKm float64) []model.Hub {
+	return slices.DeleteFunc(hubs, func(hub model.Hub) bool {
+		distanceKm, err := geo.HaversineDistance(lat, lon, hub.Lat, hub.Lon)
+		return err != nil || distanceKm > radiusKm
+	})
+}
+
+// getNearest returns up to count hubs within maxRadiusKm of a point,
+// closest first.
+func getNearest(ctx context.Context, repo Repository, lat, lon float64, count int, maxRadiusKm float64) (Result, error) {
+	if searcher, ok := repo.(NearestSearcher); ok {
+		return searcher.GetNearest(ctx, lat, lon, count, maxRadiusKm)
+	}
+	result, err := getByRadius(ctx, repo, lat, lon, maxRadiusKm)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Hubs = nearestFirst(result.Hubs, lat, lon, count)
+	return result, nil
+}
+
+// nearestFirst sorts hubs by their distance from a point, breaking ties on
+// the ID, and keeps the first count.
+func nearestFirst(hubs []model.Hub, lat, lon float64, count int) []model.Hub {
+	distances := make(map[string]float64, len(hubs))
+	for _, hub := range hubs {
+		distances[hub.ID], _ = geo.HaversineDistance(lat, lon, hub.Lat, hub.Lon)
+	}
+	sort.SliceStable(hubs, func(i, j int) bool {
+		if di, dj := distances[hubs[i].ID], distances[hubs[j].ID]; di != dj {
+			return di < dj
+		}
+		return hubs[i].ID < hubs[j].ID
+	})
+	if count >= 0 && len(hubs) > count {
+		hubs = hubs[:count]
+	}
+	return hubs
+}
+
+// searchText returns the hubs whose name contains every term of
+// TextTerms(text).
+func searchText(ctx context.Context, repo Repository, text string) (Result, error) {
+	if searcher, ok := repo.(TextSearcher); ok {
+		return searcher.SearchText(ctx, text)
+	}
+	terms := TextTerms(text)
+	if len(terms) == 0 {
+		return Result{Hubs: []model.Hub{}}, nil
+	}
+	result, err := getByBoundsDetailed(ctx, repo, -90, 90, -180, 180)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Hubs = slices.DeleteFunc(result.Hubs, func(hub model.Hub) bool {
+		names := TextTerms(hub.Name)
+		for _, term := range terms {
+			if !slices.Contains(names, term) {
+				return true
+			}
+		}
+		return false
+	})
+	return result, nil
+}
+
+// countByBounds returns the number of hubs a bounds query would return.
+func countByBounds(ctx context.Context, repo Repository, minLat, maxLat, minLon, maxLon float64) (int, error) {
+	if counter, ok := repo.(Counter); ok {
+		return counter.CountByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	}
+	hubs, err := repo.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return 0, err
+	}
+	return len(hubs), nil
+}
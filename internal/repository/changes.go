@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// Change is one entry of a database changes feed.
+type Change struct {
+	// Seq is the update sequence of the change. It is opaque and can only
+	// be passed back as the since argument of a later ChangesSource call.
+	Seq     string
+	ID      string
+	Deleted bool
+	// Hub is the current content of the document. It is only set when the
+	// document was not deleted and could be decoded; otherwise Warning
+	// explains why it is not a valid hub.
+	Hub     model.Hub
+	Warning *RowWarning
+}
+
+// ChangesPage is one batch read from a changes feed.
+type ChangesPage struct {
+	Changes []Change
+	// LastSeq is the sequence to resume from after this page.
+	LastSeq string
+	// Pending is the number of changes left after this page.
+	Pending int64
+}
+
+// ChangesOptions controls a ChangesSource call.
+type ChangesOptions struct {
+	// Limit caps the number of changes returned. Zero means no limit.
+	Limit int
+	// Wait keeps the request open until a change arrives or Wait elapses.
+	// Zero returns immediately, even when there are no changes.
+	Wait time.Duration
+	// KeepOutOfRange decodes documents whose coordinates are outside ±90
+	// latitude or ±180 longitude into hubs instead of reporting them as
+	// malformed, so that they can be audited.
+	KeepOutOfRange bool
+}
+
+// ChangesSource is implemented by backends that can report the changes made
+// to their hubs since a given sequence.
+type ChangesSource interface {
+	// Changes returns the changes after since. An empty since starts from
+	// the beginning of the feed.
+	Changes(ctx context.Context, since string, opts ChangesOptions) (ChangesPage, error)
+}
+
+// ReadAllChanges reads the whole changes feed of source in pages of
+// opts.Limit changes and returns the current hubs in feed order, leaving out
+// deleted documents. Documents that are not valid hubs are returned as
+// warnings instead.
+func ReadAllChanges(ctx context.Context, source ChangesSource, opts ChangesOptions) ([]model.Hub, []RowWarning, error) {
+	opts.Wait = 0
+	var (
+		since    string
+		order    []string
+		seen     = make(map[string]bool)
+		hubs     = make(map[string]model.Hub)
+		warnings = make(map[string]RowWarning)
+	)
+	for {
+		page, err := source.Changes(ctx, since, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, change := range page.Changes {
+			// A document changed while paging shows up again; only its
+			// latest state counts.
+			if !seen[change.ID] {
+				seen[change.ID] = true
+				order = append(order, change.ID)
+			}
+			delete(hubs, change.ID)
+			delete(warnings, change.ID)
+			switch {
+			case change.Deleted:
+			case change.Warning != nil:
+				warnings[change.ID] = *change.Warning
+			default:
+				hubs[change.ID] = change.Hub
+			}
+		}
+		since = page.LastSeq
+		if page.Pending == 0 || len(page.Changes) == 0 {
+			break
+		}
+	}
+
+	all := make([]model.Hub, 0, len(hubs))
+	var skipped []RowWarning
+	for _, id := range order {
+		if hub, ok := hubs[id]; ok {
+			all = append(all, hub)
+		} else if warning, ok := warnings[id]; ok {
+			skipped = append(skipped, warning)
+		}
+	}
+	return all, skipped, nil
+}
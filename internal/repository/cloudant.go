@@ -3,22 +3,47 @@ package repository
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/IBM/cloudant-go-sdk/cloudantv1"
 	"github.com/IBM/go-sdk-core/v5/core"
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const pageSize = 200
 
-// Compile-time check that CloudantRepository implements Repository.
-var _ Repository = (*CloudantRepository)(nil)
+// Compile-time checks that CloudantRepository implements the optional
+// repository interfaces.
+var (
+	_ DetailedRepository = (*CloudantRepository)(nil)
+	_ IDGetter           = (*CloudantRepository)(nil)
+	_ ChangesSource      = (*CloudantRepository)(nil)
+)
 
+// CloudantRepository answers bounds queries with a Cloudant search index.
 type CloudantRepository struct {
+	*cloudantClient
+}
+
+// cloudantClient holds the connection and settings shared by the Cloudant
+// repositories, and implements the operations that do not depend on the
+// index they query: document lookups, the changes feed and writes.
+type cloudantClient struct {
 	service *cloudantv1.CloudantV1
 	db      string
 	ddoc    string
 	index   string
+	logger  *slog.Logger
+	policy  RowPolicy
+	decoder rowDecoder
+	tracer  trace.Tracer
+	metrics *telemetry.Metrics
 }
 
 type CloudantConfig struct {
@@ -26,12 +51,41 @@ type CloudantConfig struct {
 	DB      string
 	Ddoc    string
 	Index   string
+	// Authenticator signs every request. Defaults to no authentication,
+	// which is enough for public databases but not for writes.
+	Authenticator core.Authenticator
+	// Logger receives debug traces of every backend call. Defaults to slog.Default().
+	Logger *slog.Logger
+	// RowPolicy decides what happens to rows that cannot be converted into
+	// hubs. The zero value skips them silently.
+	RowPolicy RowPolicy
+	// CoerceStrings accepts lat and lon values encoded as strings.
+	CoerceStrings bool
+	// TracerProvider creates the spans for backend calls. Defaults to the
+	// global OpenTelemetry tracer provider.
+	TracerProvider trace.TracerProvider
+	// Metrics records backend latency, pages and errors. Nil disables metrics.
+	Metrics *telemetry.Metrics
 }
 
 func NewCloudantRepository(cfg CloudantConfig) (*CloudantRepository, error) {
-	authenticator, err := core.NewNoAuthAuthenticator()
+	client, err := newCloudantClient(cfg, "cloudant")
 	if err != nil {
-		return nil, fmt.Errorf("create no-auth authenticator: %w", err)
+		return nil, err
+	}
+	return &CloudantRepository{cloudantClient: client}, nil
+}
+
+// newCloudantClient connects to the database in cfg. backend names the
+// repository in log messages.
+func newCloudantClient(cfg CloudantConfig, backend string) (*cloudantClient, error) {
+	authenticator := cfg.Authenticator
+	if authenticator == nil {
+		noAuth, err := core.NewNoAuthAuthenticator()
+		if err != nil {
+			return nil, fmt.Errorf("create no-auth authenticator: %w", err)
+		}
+		authenticator = noAuth
 	}
 
 	service, err := cloudantv1.NewCloudantV1(&cloudantv1.CloudantV1Options{
@@ -42,11 +96,21 @@ func NewCloudantRepository(cfg CloudantConfig) (*CloudantRepository, error) {
 		return nil, fmt.Errorf("create cloudant client: %w", err)
 	}
 
-	return &CloudantRepository{
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &cloudantClient{
 		service: service,
 		db:      cfg.DB,
 		ddoc:    cfg.Ddoc,
 		index:   cfg.Index,
+		logger:  logger.With("component", "repository", "backend", backend),
+		policy:  cfg.RowPolicy,
+		decoder: rowDecoder{coerceStrings: cfg.CoerceStrings},
+		tracer:  telemetry.Tracer(cfg.TracerProvider),
+		metrics: cfg.Metrics,
 	}, nil
 }
 
@@ -60,16 +124,70 @@ func buildSearchQuery(minLat, maxLat, minLon, maxLon float64) string {
 }
 
 func (r *CloudantRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
-	query := buildSearchQuery(minLat, maxLat, minLon, maxLon)
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// GetByBoundsDetailed retrieves all hubs within the specified geographic
+// bounds, applying the configured RowPolicy to malformed rows.
+func (r *CloudantRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (Result, error) {
+	return r.search(ctx, searchRequest{
+		span:  "CloudantRepository.GetByBounds",
+		query: buildSearchQuery(minLat, maxLat, minLon, maxLon),
+		attrs: telemetry.BoundsAttributes(minLat, maxLat, minLon, maxLon),
+	})
+}
 
-	allHubs := make([]model.Hub, 0, pageSize)
+// searchRequest describes a query against the search index.
+type searchRequest struct {
+	// span names the span the query is traced in.
+	span  string
+	query string
+	sort  []string
+	// limit stops reading pages once that many hubs have been collected.
+	// Zero reads every page.
+	limit int
+	attrs []attribute.KeyValue
+}
+
+// search runs req, following bookmarks until the results run out or
+// req.limit hubs have been collected, and applies the configured RowPolicy
+// to malformed rows.
+func (r *CloudantRepository) search(ctx context.Context, req searchRequest) (_ Result, err error) {
+	start := time.Now()
+
+	ctx, span := r.tracer.Start(ctx, req.span, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(req.attrs...)
+	span.SetAttributes(
+		attribute.String("db.system", "cloudant"),
+		attribute.String("db.namespace", r.db),
+		attribute.String("hubfinder.query", req.query),
+	)
+	pages := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("hubfinder.pages", pages))
+		r.metrics.AddBackendPages("cloudant", pages)
+		r.metrics.ObserveQuery("backend_search", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+	r.logger.DebugContext(ctx, "search started", "db", r.db, "ddoc", r.ddoc, "index", r.index, "query", req.query, "sort", req.sort)
+
+	rows := rowCollector{decoder: r.decoder, policy: r.policy, hubs: make([]model.Hub, 0, pageSize)}
 
+	limit := pageSize
+	if req.limit > 0 {
+		limit = min(req.limit, pageSize)
+	}
 	options := &cloudantv1.PostSearchOptions{
 		Db:    new(r.db),
 		Ddoc:  new(r.ddoc),
 		Index: new(r.index),
-		Query: new(query),
-		Limit: core.Int64Ptr(pageSize),
+		Query: new(req.query),
+		Sort:  req.sort,
+		Limit: core.Int64Ptr(int64(limit)),
 	}
 
 	var currentBookmark *string
@@ -77,32 +195,36 @@ func (r *CloudantRepository) GetByBounds(ctx context.Context, minLat, maxLat, mi
 	for {
 		options.Bookmark = currentBookmark
 
-		result, _, err := r.service.PostSearchWithContext(ctx, options)
+		pageStart := time.Now()
+		result, response, err := r.service.PostSearchWithContext(ctx, options)
 		if err != nil {
-			return nil, fmt.Errorf("post search: %w", err)
+			r.logger.DebugContext(ctx, "search page failed", "page", pages+1, "bookmark", derefString(currentBookmark), "error", err)
+			return Result{}, newBackendError(ctx, "post search", statusCode(response), err)
 		}
+		pages++
+		r.logger.DebugContext(ctx, "search page fetched",
+			"page", pages,
+			"bookmark", derefString(currentBookmark),
+			"next_bookmark", derefString(result.Bookmark),
+			"rows", len(result.Rows),
+			"duration", time.Since(pageStart),
+		)
 
-		if result.Rows != nil {
-			for _, row := range result.Rows {
-				if row.ID == nil || row.Fields == nil {
-					continue
-				}
-
-				lat, latOk := row.Fields["lat"].(float64)
-				lon, lonOk := row.Fields["lon"].(float64)
-				name, nameOk := row.Fields["name"].(string)
-
-				if latOk && lonOk && nameOk {
-					allHubs = append(allHubs, model.Hub{
-						ID:   *row.ID,
-						Lat:  lat,
-						Lon:  lon,
-						Name: name,
-					})
-				}
+		for _, row := range result.Rows {
+			warning, err := rows.add(row.ID, row.Fields)
+			if err != nil {
+				return Result{}, err
+			}
+			if warning != nil {
+				r.logger.DebugContext(ctx, "skipped malformed row", "id", warning.ID, "reason", warning.Reason)
 			}
 		}
 
+		if req.limit > 0 && len(rows.hubs) >= req.limit {
+			rows.hubs = rows.hubs[:req.limit]
+			break
+		}
+
 		if result.Bookmark == nil || *result.Bookmark == "" {
 			break
 		}
@@ -114,5 +236,151 @@ func (r *CloudantRepository) GetByBounds(ctx context.Context, minLat, maxLat, mi
 		currentBookmark = result.Bookmark
 	}
 
-	return allHubs, nil
+	span.SetAttributes(
+		attribute.Int("hubfinder.result_count", len(rows.hubs)),
+		attribute.Int("hubfinder.skipped_rows", rows.skipped),
+	)
+	r.logger.DebugContext(ctx, "search finished",
+		"query", req.query,
+		"pages", pages,
+		"hubs", len(rows.hubs),
+		"skipped", rows.skipped,
+		"duration", time.Since(start),
+	)
+
+	return rows.result(), nil
+}
+
+// GetHub fetches the document with the given ID and converts it into a hub.
+// A document that exists but is not a valid hub is reported as a
+// *MalformedRowError.
+func (r *cloudantClient) GetHub(ctx context.Context, id string) (_ model.Hub, err error) {
+	start := time.Now()
+
+	ctx, span := r.tracer.Start(ctx, "CloudantRepository.GetHub", trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("db.system", "cloudant"),
+		attribute.String("db.namespace", r.db),
+		attribute.String("hubfinder.hub_id", id),
+	)
+	defer func() {
+		r.metrics.ObserveQuery("backend_get", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	doc, response, err := r.service.GetDocumentWithContext(ctx, &cloudantv1.GetDocumentOptions{
+		Db:    new(r.db),
+		DocID: new(id),
+	})
+	if err != nil {
+		if statusCode(response) == http.StatusNotFound {
+			return model.Hub{}, fmt.Errorf("get document %q: %w", id, ErrNotFound)
+		}
+		return model.Hub{}, newBackendError(ctx, fmt.Sprintf("get document %q", id), statusCode(response), err)
+	}
+	r.logger.DebugContext(ctx, "document fetched", "id", id, "duration", time.Since(start))
+
+	hub, warning, ok := r.decoder.decode(doc.ID, doc.GetProperties())
+	if !ok {
+		return model.Hub{}, &MalformedRowError{RowWarning: warning}
+	}
+	return hub, nil
+}
+
+// Changes reads the database _changes feed after since, decoding each
+// changed document into a hub. Design documents are left out.
+func (r *cloudantClient) Changes(ctx context.Context, since string, opts ChangesOptions) (_ ChangesPage, err error) {
+	start := time.Now()
+
+	ctx, span := r.tracer.Start(ctx, "CloudantRepository.Changes", trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("db.system", "cloudant"),
+		attribute.String("db.namespace", r.db),
+		attribute.String("hubfinder.since", since),
+	)
+	defer func() {
+		r.metrics.ObserveQuery("backend_changes", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	options := &cloudantv1.PostChangesOptions{
+		Db:          new(r.db),
+		IncludeDocs: core.BoolPtr(true),
+	}
+	if since != "" {
+		options.Since = new(since)
+	}
+	if opts.Limit > 0 {
+		options.Limit = core.Int64Ptr(int64(opts.Limit))
+	}
+	if opts.Wait > 0 {
+		options.Feed = new("longpoll")
+		options.Timeout = core.Int64Ptr(opts.Wait.Milliseconds())
+	}
+
+	result, response, err := r.service.PostChangesWithContext(ctx, options)
+	if err != nil {
+		return ChangesPage{}, newBackendError(ctx, "post changes", statusCode(response), err)
+	}
+
+	page := ChangesPage{
+		Changes: make([]Change, 0, len(result.Results)),
+		LastSeq: derefString(result.LastSeq),
+	}
+	if result.Pending != nil {
+		page.Pending = *result.Pending
+	}
+	decoder := r.decoder
+	decoder.keepOutOfRange = opts.KeepOutOfRange
+	for _, item := range result.Results {
+		id := derefString(item.ID)
+		if strings.HasPrefix(id, "_design/") {
+			continue
+		}
+		change := Change{
+			Seq:     derefString(item.Seq),
+			ID:      id,
+			Deleted: item.Deleted != nil && *item.Deleted,
+		}
+		if !change.Deleted {
+			var fields map[string]any
+			if item.Doc != nil {
+				fields = item.Doc.GetProperties()
+			}
+			if hub, warning, ok := decoder.decode(item.ID, fields); ok {
+				change.Hub = hub
+			} else {
+				change.Warning = &warning
+			}
+		}
+		page.Changes = append(page.Changes, change)
+	}
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.result_count", len(page.Changes)),
+		attribute.Int64("hubfinder.pending", page.Pending),
+	)
+	r.logger.DebugContext(ctx, "changes fetched",
+		"since", since,
+		"last_seq", page.LastSeq,
+		"changes", len(page.Changes),
+		"pending", page.Pending,
+		"duration", time.Since(start),
+	)
+	return page, nil
+}
+
+// statusCode returns the HTTP status of response, or zero if there was none.
+func statusCode(response *core.DetailedResponse) int {
+	if response == nil {
+		return 0
+	}
+	return response.StatusCode
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Compile-time checks that CloudantRepository implements the search index
+// capabilities.
+var (
+	_ NearestSearcher = (*CloudantRepository)(nil)
+	_ TextSearcher    = (*CloudantRepository)(nil)
+	_ Counter         = (*CloudantRepository)(nil)
+)
+
+// GetNearest asks the search index for the hubs inside the bounding box of
+// the maxRadiusKm circle sorted by distance, and stops reading once count
+// hubs have been collected. Hubs in the corners of the box, which are
+// always the farthest ones, are then dropped if they lie beyond the radius.
+func (r *CloudantRepository) GetNearest(ctx context.Context, lat, lon float64, count int, maxRadiusKm float64) (Result, error) {
+	minLat, maxLat, minLon, maxLon, err := geo.CalculateBoundingBox(lat, lon, maxRadiusKm)
+	if err != nil {
+		return Result{}, err
+	}
+	result, err := r.search(ctx, searchRequest{
+		span:  "CloudantRepository.GetNearest",
+		query: buildSearchQuery(minLat, maxLat, minLon, maxLon),
+		sort:  []string{fmt.Sprintf("<distance,lon,lat,%f,%f,km>", lon, lat)},
+		limit: count,
+		attrs: []attribute.KeyValue{
+			attribute.Float64("hubfinder.lat", lat),
+			attribute.Float64("hubfinder.lon", lon),
+			attribute.Float64("hubfinder.radius_km", maxRadiusKm),
+			attribute.Int("hubfinder.limit", count),
+		},
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	result.Hubs = withinRadius(result.Hubs, lat, lon, maxRadiusKm)
+	return result, nil
+}
+
+// SearchText asks the search index for the hubs whose name contains every
+// term of text. The index must hold name as analyzed text, as the one
+// created by Provision does.
+func (r *CloudantRepository) SearchText(ctx context.Context, text string) (Result, error) {
+	query, ok := buildTextQuery(text)
+	if !ok {
+		return Result{Hubs: []model.Hub{}}, nil
+	}
+	return r.search(ctx, searchRequest{
+		span:  "CloudantRepository.SearchText",
+		query: query,
+	})
+}
+
+// buildTextQuery constructs a Lucene query requiring every term of text in
+// the name field. It reports false if text has no terms.
+func buildTextQuery(text string) (string, bool) {
+	terms := TextTerms(text)
+	if len(terms) == 0 {
+		return "", false
+	}
+	return "name:(" + strings.Join(terms, " AND ") + ")", true
+}
+
+// CountByBounds counts the hubs of a bounds query. The total_rows of the
+// search response would also count rows that GetByBounds skips as
+// malformed, so the rows are read and decoded under the repository's
+// RowPolicy like GetByBounds, and only their number is returned.
+func (r *CloudantRepository) CountByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (int, error) {
+	result, err := r.search(ctx, searchRequest{
+		span:  "CloudantRepository.CountByBounds",
+		query: buildSearchQuery(minLat, maxLat, minLon, maxLon),
+		attrs: telemetry.BoundsAttributes(minLat, maxLat, minLon, maxLon),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(result.Hubs), nil
+}
@@ -0,0 +1,140 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+)
+
+func TestCloudantRepository_GetNearest(t *testing.T) {
+	fake := newFakeCloudant(t, hubDocs(repositorytest.Fixture()))
+	repo := newTestCloudantRepository(t, fake, nil)
+
+	result, err := repo.GetNearest(context.Background(), 40.7, -74.0, 3, 20000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, hub := range result.Hubs {
+		got = append(got, hub.ID)
+	}
+	if want := []string{"nyc", "ewr", "jfk"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCloudantRepository_GetNearestStopsEarly(t *testing.T) {
+	hubs := make([]model.Hub, 0, 450)
+	for i := range 450 {
+		hubs = append(hubs, model.Hub{ID: fmt.Sprintf("hub-%d", i), Name: "Hub", Lat: 1, Lon: 1 + float64(i)*0.001})
+	}
+	fake := newFakeCloudant(t, hubDocs(hubs))
+	repo := newTestCloudantRepository(t, fake, nil)
+
+	result, err := repo.GetNearest(context.Background(), 1, 1, 5, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 5 || result.Hubs[0].ID != "hub-0" || result.Hubs[4].ID != "hub-4" {
+		t.Errorf("expected hub-0 to hub-4, got %v", result.Hubs)
+	}
+	if len(fake.searchQueries) != 1 {
+		t.Errorf("expected a single page, got %d", len(fake.searchQueries))
+	}
+}
+
+func TestCloudantRepository_GetNearestWithinRadius(t *testing.T) {
+	// corner lies inside the bounding box of the 100 km circle around
+	// (0, 0) but about 127 km away.
+	hubs := []model.Hub{
+		{ID: "near", Name: "Near", Lat: 0.5, Lon: 0},
+		{ID: "corner", Name: "Corner", Lat: 0.8, Lon: 0.8},
+	}
+	fake := newFakeCloudant(t, hubDocs(hubs))
+	repo := newTestCloudantRepository(t, fake, nil)
+
+	result, err := repo.GetNearest(context.Background(), 0, 0, 5, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 1 || result.Hubs[0].ID != "near" {
+		t.Errorf("got %v, want only the hub within the radius", result.Hubs)
+	}
+}
+
+func TestCloudantRepository_SearchText(t *testing.T) {
+	fake := newFakeCloudant(t, hubDocs(repositorytest.Fixture()))
+	repo := newTestCloudantRepository(t, fake, nil)
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"airport", []string{"ewr", "jfk"}},
+		{"JFK airport", []string{"jfk"}},
+		{"twin", []string{"same-name-1", "same-name-2"}},
+		{"nowhere", nil},
+		{"--", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result, err := repo.SearchText(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hubIDs(result.Hubs); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if want := "name:(jfk AND airport)"; !slices.Contains(fake.searchQueries, want) {
+		t.Errorf("expected query %q among %v", want, fake.searchQueries)
+	}
+}
+
+func TestCloudantRepository_CountByBounds(t *testing.T) {
+	fixture := repositorytest.Fixture()
+	repo := newTestCloudantRepository(t, newFakeCloudant(t, hubDocs(fixture)), nil)
+
+	for _, box := range [][4]float64{{40, 41, -75, -73}, {-90, 90, -180, 180}, {-20, 20, 170, -170}} {
+		got, err := repo.CountByBounds(context.Background(), box[0], box[1], box[2], box[3])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := len(repositorytest.FilterByBounds(fixture, box[0], box[1], box[2], box[3])); got != want {
+			t.Errorf("bounds %v: got %d, want %d", box, got, want)
+		}
+	}
+}
+
+func TestCloudantRepository_CountByBoundsSkipsMalformedRows(t *testing.T) {
+	fixture := repositorytest.Fixture()
+	docs := append(hubDocs(fixture), map[string]any{"_id": "nameless", "lat": 40.5, "lon": -74.0})
+
+	for _, policy := range []repository.RowPolicy{repository.RowPolicySkip, repository.RowPolicyWarn} {
+		repo := newTestCloudantRepositoryWithConfig(t, newFakeCloudant(t, docs), repository.CloudantConfig{RowPolicy: policy})
+		got, err := repo.CountByBounds(context.Background(), 40, 41, -75, -73)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", policy, err)
+		}
+		hubs, err := repo.GetByBounds(context.Background(), 40, 41, -75, -73)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", policy, err)
+		}
+		if got != len(hubs) {
+			t.Errorf("%s: got count %d, want %d as returned by GetByBounds", policy, got, len(hubs))
+		}
+	}
+
+	repo := newTestCloudantRepositoryWithConfig(t, newFakeCloudant(t, docs), repository.CloudantConfig{RowPolicy: repository.RowPolicyFail})
+	var malformed *repository.MalformedRowError
+	if _, err := repo.CountByBounds(context.Background(), 40, 41, -75, -73); !errors.As(err, &malformed) {
+		t.Errorf("got %v, want a *MalformedRowError", err)
+	}
+}
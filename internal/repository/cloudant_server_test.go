@@ -0,0 +1,306 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestCloudantRepository(t *testing.T, fake *fakeCloudant, logger *slog.Logger) *repository.CloudantRepository {
+	t.Helper()
+	return newTestCloudantRepositoryWithConfig(t, fake, repository.CloudantConfig{Logger: logger})
+}
+
+func newTestCloudantRepositoryWithConfig(t *testing.T, fake *fakeCloudant, cfg repository.CloudantConfig) *repository.CloudantRepository {
+	t.Helper()
+
+	cfg.BaseURL = fake.server.URL
+	cfg.DB = "airportdb"
+	cfg.Ddoc = "view1"
+	cfg.Index = "geo"
+	repo, err := repository.NewCloudantRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+	return repo
+}
+
+func TestCloudantRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		return newTestCloudantRepository(t, newFakeCloudant(t, hubDocs(hubs)), nil)
+	})
+}
+
+func TestCloudantRepository_LogsPages(t *testing.T) {
+	hubs := make([]model.Hub, 0, 450)
+	for i := range 450 {
+		hubs = append(hubs, model.Hub{ID: fmt.Sprintf("hub-%d", i), Name: "Hub", Lat: 1, Lon: 1})
+	}
+	docs := append(hubDocs(hubs), map[string]any{"_id": "broken", "lat": 1.0, "lon": 1.0, "name": 42.0})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := newTestCloudantRepository(t, newFakeCloudant(t, docs), logger)
+
+	got, err := repo.GetByBounds(context.Background(), 0, 2, 0, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 450 {
+		t.Fatalf("expected 450 hubs, got %d", len(got))
+	}
+
+	var pages []map[string]any
+	var finished map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %v", err)
+		}
+		switch entry["msg"] {
+		case "search page fetched":
+			pages = append(pages, entry)
+		case "search finished":
+			finished = entry
+		}
+	}
+
+	// 451 rows at 200 per page, plus the final empty page that repeats the bookmark.
+	if len(pages) != 4 {
+		t.Fatalf("expected 4 page log entries, got %d", len(pages))
+	}
+	if pages[0]["bookmark"] != "" || pages[1]["bookmark"] != "200" {
+		t.Errorf("unexpected bookmarks: %v, %v", pages[0]["bookmark"], pages[1]["bookmark"])
+	}
+	if finished == nil {
+		t.Fatal("missing search finished log entry")
+	}
+	if finished["query"] != "lat:[0.000000 TO 2.000000] AND lon:[0.000000 TO 2.000000]" {
+		t.Errorf("unexpected query: %v", finished["query"])
+	}
+	if finished["hubs"] != 450.0 || finished["skipped"] != 1.0 || finished["pages"] != 4.0 {
+		t.Errorf("unexpected counts: %v", finished)
+	}
+}
+
+func malformedDocs() []map[string]any {
+	return []map[string]any{
+		{"_id": "good", "lat": 47.43, "lon": 19.26, "name": "Budapest"},
+		{"_id": "string-coords", "lat": "47.5", "lon": "19.1", "name": "Stringy"},
+		{"_id": "numeric-name", "lat": 47.1, "lon": 19.2, "name": 42.0},
+		{"_id": "no-name", "lat": 47.2, "lon": 19.3},
+	}
+}
+
+func TestCloudantRepository_RowPolicies(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          repository.CloudantConfig
+		wantIDs      []string
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name:    "skip",
+			cfg:     repository.CloudantConfig{RowPolicy: repository.RowPolicySkip},
+			wantIDs: []string{"good"},
+		},
+		{
+			name:         "warn",
+			cfg:          repository.CloudantConfig{RowPolicy: repository.RowPolicyWarn},
+			wantIDs:      []string{"good"},
+			wantWarnings: []string{"string-coords", "numeric-name", "no-name"},
+		},
+		{
+			name:         "warn with coercion",
+			cfg:          repository.CloudantConfig{RowPolicy: repository.RowPolicyWarn, CoerceStrings: true},
+			wantIDs:      []string{"good", "string-coords"},
+			wantWarnings: []string{"numeric-name", "no-name"},
+		},
+		{
+			name:    "fail",
+			cfg:     repository.CloudantConfig{RowPolicy: repository.RowPolicyFail},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestCloudantRepositoryWithConfig(t, newFakeCloudant(t, malformedDocs()), tt.cfg)
+
+			result, err := repo.GetByBoundsDetailed(context.Background(), 40, 50, 10, 20)
+			if tt.wantErr {
+				var rowErr *repository.MalformedRowError
+				if !errors.As(err, &rowErr) {
+					t.Fatalf("expected *MalformedRowError, got %v", err)
+				}
+				if rowErr.ID != "string-coords" {
+					t.Errorf("expected error for string-coords, got %q", rowErr.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, h := range result.Hubs {
+				ids = append(ids, h.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("hubs = %v, want %v", ids, tt.wantIDs)
+			}
+
+			var warned []string
+			for _, w := range result.Warnings {
+				warned = append(warned, w.ID)
+			}
+			if !slices.Equal(warned, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", warned, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestCloudantRepository_RecordsSpanAndMetrics(t *testing.T) {
+	hubs := make([]model.Hub, 0, 250)
+	for i := range 250 {
+		hubs = append(hubs, model.Hub{ID: fmt.Sprintf("hub-%d", i), Name: "Hub", Lat: 1, Lon: 1})
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	reg := prometheus.NewRegistry()
+	metrics, err := telemetry.NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("create metrics: %v", err)
+	}
+
+	repo := newTestCloudantRepositoryWithConfig(t, newFakeCloudant(t, hubDocs(hubs)), repository.CloudantConfig{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Metrics:        metrics,
+	})
+
+	if _, err := repo.GetByBounds(context.Background(), 0, 2, 0, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["hubfinder.pages"].AsInt64() != 3 {
+		t.Errorf("expected 3 pages, got %v", attrs["hubfinder.pages"])
+	}
+	if attrs["hubfinder.result_count"].AsInt64() != 250 {
+		t.Errorf("expected 250 results, got %v", attrs["hubfinder.result_count"])
+	}
+	if attrs["hubfinder.bbox.max_lat"].AsFloat64() != 2 {
+		t.Errorf("expected max_lat 2, got %v", attrs["hubfinder.bbox.max_lat"])
+	}
+
+	expected := `
+# HELP hubfinder_backend_pages_fetched_total Number of result pages fetched from the backend.
+# TYPE hubfinder_backend_pages_fetched_total counter
+hubfinder_backend_pages_fetched_total{backend="cloudant"} 3
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "hubfinder_backend_pages_fetched_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloudantRepository_GetHub(t *testing.T) {
+	docs := append(hubDocs([]model.Hub{{ID: "BUD", Name: "Budapest", Lat: 47.43, Lon: 19.26}}),
+		map[string]any{"_id": "broken", "lat": 47.0, "name": "No longitude"})
+	repo := newTestCloudantRepository(t, newFakeCloudant(t, docs), nil)
+
+	got, err := repo.GetHub(context.Background(), "BUD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (model.Hub{ID: "BUD", Name: "Budapest", Lat: 47.43, Lon: 19.26}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := repo.GetHub(context.Background(), "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	var malformed *repository.MalformedRowError
+	if _, err := repo.GetHub(context.Background(), "broken"); !errors.As(err, &malformed) {
+		t.Errorf("expected *MalformedRowError, got %v", err)
+	}
+}
+
+func TestCloudantRepository_BackendErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		stopServer    bool
+		wantStatus    int
+		wantRetryable bool
+	}{
+		{name: "service unavailable", status: 503, wantStatus: 503, wantRetryable: true},
+		{name: "rate limited", status: 429, wantStatus: 429, wantRetryable: true},
+		{name: "bad request", status: 400, wantStatus: 400, wantRetryable: false},
+		{name: "unauthorized", status: 401, wantStatus: 401, wantRetryable: false},
+		{name: "unreachable", stopServer: true, wantStatus: 0, wantRetryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCloudant(t, nil)
+			fake.searchStatus = tt.status
+			repo := newTestCloudantRepository(t, fake, nil)
+			if tt.stopServer {
+				fake.server.Close()
+			}
+
+			_, err := repo.GetByBounds(context.Background(), 0, 1, 0, 1)
+			var backendErr *repository.BackendError
+			if !errors.As(err, &backendErr) {
+				t.Fatalf("expected *BackendError, got %v", err)
+			}
+			if backendErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", backendErr.StatusCode, tt.wantStatus)
+			}
+			if backendErr.Retryable != tt.wantRetryable {
+				t.Errorf("Retryable = %v, want %v", backendErr.Retryable, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestCloudantRepository_CanceledContext(t *testing.T) {
+	repo := newTestCloudantRepository(t, newFakeCloudant(t, nil), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetByBounds(ctx, 0, 1, 0, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	var backendErr *repository.BackendError
+	if errors.As(err, &backendErr) {
+		t.Errorf("expected cancellation not to be reported as a backend failure, got %v", err)
+	}
+}
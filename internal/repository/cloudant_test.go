@@ -67,3 +67,26 @@ func TestBuildSearchQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildTextQuery(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+		ok       bool
+	}{
+		{"Budapest", "name:(budapest)", true},
+		{"Budapest-Keleti station", "name:(budapest AND keleti AND station)", true},
+		{"  O'Hare (ORD) ", "name:(o AND hare AND ord)", true},
+		{"AND OR NOT", "name:(and AND or AND not)", true},
+		{" -*- ", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := buildTextQuery(tt.text)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("got %q, %v, want %q, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
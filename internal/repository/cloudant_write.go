@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IBM/cloudant-go-sdk/cloudantv1"
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Compile-time check that CloudantRepository implements WritableRepository.
+var _ WritableRepository = (*CloudantRepository)(nil)
+
+// Revision reads the current revision of a hub document from its ETag.
+func (r *cloudantClient) Revision(ctx context.Context, id string) (_ string, err error) {
+	ctx, finish := r.startWrite(ctx, "CloudantRepository.Revision", "backend_revision", id)
+	defer func() { finish(err) }()
+
+	response, err := r.service.HeadDocumentWithContext(ctx, &cloudantv1.HeadDocumentOptions{
+		Db:    new(r.db),
+		DocID: new(id),
+	})
+	if err != nil {
+		return "", writeError(ctx, fmt.Sprintf("head document %q", id), response, err)
+	}
+	return strings.Trim(response.GetHeaders().Get("ETag"), `"`), nil
+}
+
+// Put writes hub as a document. Fields of an existing document other than
+// lat, lon and name are kept, and so are its optional hub fields unless hub
+// sets them.
+func (r *cloudantClient) Put(ctx context.Context, hub model.Hub, rev string) (_ string, err error) {
+	if err := ValidateHub(hub); err != nil {
+		return "", err
+	}
+
+	ctx, finish := r.startWrite(ctx, "CloudantRepository.Put", "backend_put", hub.ID)
+	defer func() { finish(err) }()
+
+	var current *cloudantv1.Document
+	if rev != "" {
+		var response *core.DetailedResponse
+		current, response, err = r.service.GetDocumentWithContext(ctx, &cloudantv1.GetDocumentOptions{
+			Db:    new(r.db),
+			DocID: new(hub.ID),
+		})
+		if err != nil {
+			return "", writeError(ctx, fmt.Sprintf("get document %q", hub.ID), response, err)
+		}
+		if derefString(current.Rev) != rev {
+			return "", fmt.Errorf("hub %q is at revision %s, not %s: %w", hub.ID, derefString(current.Rev), rev, ErrConflict)
+		}
+	}
+
+	result, response, err := r.service.PutDocumentWithContext(ctx, &cloudantv1.PutDocumentOptions{
+		Db:       new(r.db),
+		DocID:    new(hub.ID),
+		Document: hubDocument(hub, rev, current),
+	})
+	if err != nil {
+		return "", writeError(ctx, fmt.Sprintf("put document %q", hub.ID), response, err)
+	}
+	r.logger.DebugContext(ctx, "document written", "id", hub.ID, "rev", derefString(result.Rev))
+	return derefString(result.Rev), nil
+}
+
+func (r *cloudantClient) Delete(ctx context.Context, id, rev string) (err error) {
+	ctx, finish := r.startWrite(ctx, "CloudantRepository.Delete", "backend_delete", id)
+	defer func() { finish(err) }()
+
+	_, response, err := r.service.DeleteDocumentWithContext(ctx, &cloudantv1.DeleteDocumentOptions{
+		Db:    new(r.db),
+		DocID: new(id),
+		Rev:   new(rev),
+	})
+	if err != nil {
+		return writeError(ctx, fmt.Sprintf("delete document %q", id), response, err)
+	}
+	r.logger.DebugContext(ctx, "document deleted", "id", id, "rev", rev)
+	return nil
+}
+
+// BulkUpsert reads the current documents with one _all_docs request and
+// writes the valid hubs with one _bulk_docs request. Invalid hubs are
+// reported in their result and hubs whose document already matches are
+// reported as unchanged; neither is sent.
+func (r *cloudantClient) BulkUpsert(ctx context.Context, hubs []model.Hub) (_ []WriteResult, err error) {
+	ctx, finish := r.startWrite(ctx, "CloudantRepository.BulkUpsert", "backend_bulk_upsert", "")
+	defer func() { finish(err) }()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("hubfinder.docs", len(hubs)))
+
+	results := make([]WriteResult, len(hubs))
+	var valid []int
+	var ids []string
+	for i, hub := range hubs {
+		results[i].ID = hub.ID
+		if err := ValidateHub(hub); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+		ids = append(ids, hub.ID)
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	existing, response, err := r.service.PostAllDocsWithContext(ctx, &cloudantv1.PostAllDocsOptions{
+		Db:          new(r.db),
+		Keys:        ids,
+		IncludeDocs: core.BoolPtr(true),
+	})
+	if err != nil {
+		return nil, newBackendError(ctx, "post all docs", statusCode(response), err)
+	}
+	current := make(map[string]*cloudantv1.Document, len(existing.Rows))
+	for _, row := range existing.Rows {
+		if row.Doc != nil {
+			current[derefString(row.Key)] = row.Doc
+		}
+	}
+
+	docs := make([]cloudantv1.Document, 0, len(valid))
+	var sent []int
+	for _, i := range valid {
+		doc := current[hubs[i].ID]
+		var rev string
+		if doc != nil {
+			rev = derefString(doc.Rev)
+			if hub, _, ok := r.decoder.decode(doc.ID, doc.GetProperties()); ok && sameHub(hub, hubs[i]) {
+				results[i].Rev = rev
+				results[i].Unchanged = true
+				continue
+			}
+		}
+		docs = append(docs, *hubDocument(hubs[i], rev, doc))
+		sent = append(sent, i)
+	}
+	if len(docs) == 0 {
+		return results, nil
+	}
+
+	written, response, err := r.service.PostBulkDocsWithContext(ctx, &cloudantv1.PostBulkDocsOptions{
+		Db:       new(r.db),
+		BulkDocs: &cloudantv1.BulkDocs{Docs: docs},
+	})
+	if err != nil {
+		return nil, newBackendError(ctx, "post bulk docs", statusCode(response), err)
+	}
+	if len(written) != len(docs) {
+		return nil, newBackendError(ctx, "post bulk docs", statusCode(response),
+			fmt.Errorf("got %d results for %d documents", len(written), len(docs)))
+	}
+
+	failed := 0
+	for j, i := range sent {
+		switch w := written[j]; {
+		case derefString(w.Error) == "conflict":
+			results[i].Err = fmt.Errorf("hub %q: %w", hubs[i].ID, ErrConflict)
+		case w.Error != nil:
+			results[i].Err = fmt.Errorf("hub %q: %s: %s", hubs[i].ID, *w.Error, derefString(w.Reason))
+		default:
+			results[i].Rev = derefString(w.Rev)
+			continue
+		}
+		failed++
+	}
+	r.logger.DebugContext(ctx, "documents written",
+		"docs", len(hubs),
+		"sent", len(docs),
+		"failed", failed+len(hubs)-len(valid),
+	)
+	return results, nil
+}
+
+// startWrite starts the span for a document operation and returns a
+// function that records its outcome.
+func (r *cloudantClient) startWrite(ctx context.Context, name, op, id string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := r.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("db.system", "cloudant"),
+		attribute.String("db.namespace", r.db),
+	)
+	if id != "" {
+		span.SetAttributes(attribute.String("hubfinder.hub_id", id))
+	}
+	return ctx, func(err error) {
+		r.metrics.ObserveQuery(op, time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}
+}
+
+// hubDocument builds the document for hub at revision rev, keeping the
+// fields of current other than the hub fields.
+func hubDocument(hub model.Hub, rev string, current *cloudantv1.Document) *cloudantv1.Document {
+	doc := &cloudantv1.Document{ID: new(hub.ID)}
+	if rev != "" {
+		doc.Rev = new(rev)
+	}
+	if current != nil {
+		for k, v := range current.GetProperties() {
+			doc.SetProperty(k, v)
+		}
+	}
+	doc.SetProperty("lat", hub.Lat)
+	doc.SetProperty("lon", hub.Lon)
+	doc.SetProperty("name", hub.Name)
+	if hub.Type != "" {
+		doc.SetProperty("type", hub.Type)
+	}
+	if hub.Country != "" {
+		doc.SetProperty("country", hub.Country)
+	}
+	if hub.Routes != 0 {
+		doc.SetProperty("routes", hub.Routes)
+	}
+	if hub.PassengerClass != "" {
+		doc.SetProperty("passenger_class", hub.PassengerClass)
+	}
+	return doc
+}
+
+// sameHub reports whether writing hub over stored would change nothing. The
+// optional fields hub leaves empty keep their stored values.
+func sameHub(stored, hub model.Hub) bool {
+	if hub.Type == "" {
+		stored.Type = ""
+	}
+	if hub.Country == "" {
+		stored.Country = ""
+	}
+	if hub.Routes == 0 {
+		stored.Routes = 0
+	}
+	if hub.PassengerClass == "" {
+		stored.PassengerClass = ""
+	}
+	return stored == hub
+}
+
+// writeError converts a failed document request into ErrNotFound or
+// ErrConflict where the status allows, and a backend error otherwise.
+func writeError(ctx context.Context, op string, response *core.DetailedResponse, err error) error {
+	switch statusCode(response) {
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", op, ErrNotFound)
+	case http.StatusConflict:
+		return fmt.Errorf("%s: %w", op, ErrConflict)
+	}
+	return newBackendError(ctx, op, statusCode(response), err)
+}
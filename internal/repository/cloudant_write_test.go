@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+func TestCloudantRepository_Put(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeCloudant(t, []map[string]any{
+		{"_id": "BUD", "lat": 47.43, "lon": 19.26, "name": "Budapest", "city": "Budapest"},
+	})
+	repo := newTestCloudantRepository(t, fake, nil)
+
+	rev, err := repo.Put(ctx, model.Hub{ID: "LHR", Name: "Heathrow", Lat: 51.47, Lon: -0.454}, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rev != "1-fake" {
+		t.Errorf("rev = %q, want %q", rev, "1-fake")
+	}
+	if _, err := repo.Put(ctx, model.Hub{ID: "BUD", Name: "Budapest", Lat: 1, Lon: 1}, ""); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("creating an existing hub: expected ErrConflict, got %v", err)
+	}
+
+	updated := model.Hub{ID: "BUD", Name: "Budapest Ferenc Liszt", Lat: 47.437, Lon: 19.261}
+	if rev, err = repo.Put(ctx, updated, "1-fake"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if rev != "2-fake" {
+		t.Errorf("rev = %q, want %q", rev, "2-fake")
+	}
+	if got, err := repo.GetHub(ctx, "BUD"); err != nil || got != updated {
+		t.Errorf("GetHub() = %+v, %v, want %+v", got, err, updated)
+	}
+	if city := fake.find("BUD")["city"]; city != "Budapest" {
+		t.Errorf("expected other fields to be kept, got city %v", city)
+	}
+
+	if _, err := repo.Put(ctx, updated, "1-fake"); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("stale revision: expected ErrConflict, got %v", err)
+	}
+	if _, err := repo.Put(ctx, model.Hub{ID: "missing", Lat: 1, Lon: 1}, "1-fake"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("updating a missing hub: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCloudantRepository_PutValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		hub  model.Hub
+		want error
+	}{
+		{name: "missing ID", hub: model.Hub{Lat: 1, Lon: 1}, want: repository.ErrMissingID},
+		{name: "latitude", hub: model.Hub{ID: "a", Lat: -91}, want: geo.ErrInvalidLatitude},
+		{name: "longitude", hub: model.Hub{ID: "a", Lon: 200}, want: geo.ErrInvalidLongitude},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCloudant(t, nil)
+			repo := newTestCloudantRepository(t, fake, nil)
+
+			if _, err := repo.Put(context.Background(), tt.hub, ""); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if len(fake.docs) != 0 {
+				t.Errorf("expected nothing to be written, got %v", fake.docs)
+			}
+		})
+	}
+}
+
+func TestCloudantRepository_RevisionAndDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestCloudantRepository(t, newFakeCloudant(t, hubDocs([]model.Hub{{ID: "BUD", Lat: 47.43, Lon: 19.26}})), nil)
+
+	rev, err := repo.Revision(ctx, "BUD")
+	if err != nil {
+		t.Fatalf("revision: %v", err)
+	}
+	if rev != "1-fake" {
+		t.Errorf("Revision() = %q, want %q", rev, "1-fake")
+	}
+	if _, err := repo.Revision(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, "BUD", "0-stale"); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("stale revision: expected ErrConflict, got %v", err)
+	}
+	if err := repo.Delete(ctx, "BUD", rev); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Delete(ctx, "BUD", rev); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("deleting twice: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCloudantRepository_BulkUpsert(t *testing.T) {
+	fake := newFakeCloudant(t, []map[string]any{
+		{"_id": "BUD", "_rev": "3-fake", "lat": 47.43, "lon": 19.26, "name": "Budapest", "city": "Budapest"},
+		{"_id": "LIS", "_rev": "2-fake", "lat": 38.774, "lon": -9.134, "name": "Lisbon"},
+	})
+	repo := newTestCloudantRepository(t, fake, nil)
+
+	results, err := repo.BulkUpsert(context.Background(), []model.Hub{
+		{ID: "BUD", Name: "Budapest", Lat: 47.437, Lon: 19.261},
+		{ID: "bad", Name: "Bad", Lat: 100, Lon: 0},
+		{ID: "LHR", Name: "Heathrow", Lat: 51.47, Lon: -0.454},
+		{ID: "LHR", Name: "Heathrow again", Lat: 51.47, Lon: -0.454},
+		{ID: "LIS", Name: "Lisbon", Lat: 38.774, Lon: -9.134},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		id        string
+		rev       string
+		unchanged bool
+		err       error
+	}{
+		{id: "BUD", rev: "4-fake"},
+		{id: "bad", err: geo.ErrInvalidLatitude},
+		{id: "LHR", rev: "1-fake"},
+		{id: "LHR", err: repository.ErrConflict},
+		{id: "LIS", rev: "2-fake", unchanged: true},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.ID != w.id || got.Rev != w.rev || got.Unchanged != w.unchanged || !errors.Is(got.Err, w.err) || (w.err == nil) != (got.Err == nil) {
+			t.Errorf("result %d = %+v, want ID %s, Rev %q, Err %v", i, got, w.id, w.rev, w.err)
+		}
+	}
+	if doc := fake.find("BUD"); doc["city"] != "Budapest" || doc["lat"] != 47.437 {
+		t.Errorf("unexpected BUD document %v", doc)
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is returned when a requested hub does not exist.
+	ErrNotFound = errors.New("hub not found")
+	// ErrConflict is returned when a write is based on a revision that is
+	// no longer current, or creates a hub that already exists.
+	ErrConflict = errors.New("hub was changed concurrently")
+	// ErrMissingID is returned when a hub without an ID is written.
+	ErrMissingID = errors.New("hub ID is required")
+)
+
+// BackendError reports a failed call to the storage backend.
+type BackendError struct {
+	// Op names the backend operation, such as "post search".
+	Op string
+	// StatusCode is the HTTP status of the response, or zero if no response
+	// was received.
+	StatusCode int
+	// Retryable reports whether repeating the call may succeed, for example
+	// after a network failure, a 429 or a 5xx response.
+	Retryable bool
+	Err       error
+}
+
+func (e *BackendError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: status %d: %v", e.Op, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorType labels the error in metrics and traces.
+func (e *BackendError) ErrorType() string {
+	if e.Retryable {
+		return "backend_unavailable"
+	}
+	return "backend"
+}
+
+// newBackendError wraps err from the backend operation op. Failures caused
+// by ctx ending are returned as the context error instead, so they are not
+// mistaken for backend outages.
+func newBackendError(ctx context.Context, op string, statusCode int, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s: %w", op, ctxErr)
+	}
+	return &BackendError{
+		Op:         op,
+		StatusCode: statusCode,
+		Retryable:  isRetryableStatus(statusCode),
+		Err:        err,
+	}
+}
+
+// isRetryableStatus reports whether a call that failed with statusCode may
+// succeed when repeated. Zero means the request never got a response.
+func isRetryableStatus(statusCode int) bool {
+	switch {
+	case statusCode == 0,
+		statusCode == http.StatusRequestTimeout,
+		statusCode == http.StatusTooManyRequests:
+		return true
+	case statusCode >= 500:
+		return statusCode != http.StatusNotImplemented
+	default:
+		return false
+	}
+}
@@ -0,0 +1,642 @@
+package repository_test
+
+import (
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+var searchRangePattern = regexp.MustCompile(`(lat|lon):\[(\S+) TO (\S+)\]`)
+
+// fakeCloudant is an in-process stand-in for the Cloudant HTTP API. It serves
+// just enough of the search and document endpoints for CloudantRepository to
+// run against. Every document carries a _rev, which writes check and bump.
+type fakeCloudant struct {
+	t      *testing.T
+	mu     sync.Mutex
+	docs   []map[string]any
+	server *httptest.Server
+	// searchStatus, if set, makes every search fail with that HTTP status,
+	// and searchQueries holds the query of every search.
+	searchStatus  int
+	searchQueries []string
+	// dbMissing makes the database not exist until it is created.
+	dbMissing bool
+	ddocs     map[string]map[string]any
+	// indexes holds the Mango indexes in the format of GET _index, and
+	// findIndexes the use_index of every _find request.
+	indexes     []map[string]any
+	findIndexes [][]string
+	// geoQueries holds the query parameters of every _geo request.
+	geoQueries []url.Values
+}
+
+func newFakeCloudant(t *testing.T, docs []map[string]any) *fakeCloudant {
+	t.Helper()
+
+	f := &fakeCloudant{t: t, ddocs: map[string]map[string]any{}}
+	for _, doc := range docs {
+		if _, ok := doc["_rev"]; !ok {
+			doc["_rev"] = "1-fake"
+		}
+		f.docs = append(f.docs, doc)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{db}/_design/{ddoc}/_search/{index}", f.handleSearch)
+	mux.HandleFunc("GET /{db}/_design/{ddoc}/_geo/{index}", f.handleGeo)
+	mux.HandleFunc("POST /{db}/_find", f.handleFind)
+	mux.HandleFunc("GET /{db}/_index", f.handleGetIndexes)
+	mux.HandleFunc("POST /{db}/_index", f.handlePostIndex)
+	mux.HandleFunc("DELETE /{db}/_index/_design/{ddoc}/{type}/{name}", f.handleDeleteIndex)
+	mux.HandleFunc("POST /{db}/_all_docs", f.handleAllDocs)
+	mux.HandleFunc("POST /{db}/_bulk_docs", f.handleBulkDocs)
+	mux.HandleFunc("PUT /{db}", f.handlePutDatabase)
+	mux.HandleFunc("GET /{db}/_design/{ddoc}", f.handleGetDesignDocument)
+	mux.HandleFunc("PUT /{db}/_design/{ddoc}", f.handlePutDesignDocument)
+	mux.HandleFunc("GET /{db}/{docid}", f.handleGetDocument)
+	mux.HandleFunc("PUT /{db}/{docid}", f.handlePutDocument)
+	mux.HandleFunc("DELETE /{db}/{docid}", f.handleDeleteDocument)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func hubDocs(hubs []model.Hub) []map[string]any {
+	docs := make([]map[string]any, 0, len(hubs))
+	for _, h := range hubs {
+		docs = append(docs, map[string]any{
+			"_id":  h.ID,
+			"lat":  h.Lat,
+			"lon":  h.Lon,
+			"name": h.Name,
+		})
+	}
+	return docs
+}
+
+func (f *fakeCloudant) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if f.searchStatus != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.searchStatus)
+		f.writeJSON(w, map[string]any{"error": "failed", "reason": http.StatusText(f.searchStatus)})
+		return
+	}
+
+	var req struct {
+		Query    string   `json:"query"`
+		Limit    int      `json:"limit"`
+		Bookmark string   `json:"bookmark"`
+		Sort     []string `json:"sort"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.searchQueries = append(f.searchQueries, req.Query)
+	matches := f.match(req.Query)
+	if len(req.Sort) > 0 {
+		var lat, lon float64
+		if _, err := fmt.Sscanf(req.Sort[0], "<distance,lon,lat,%g,%g,km>", &lon, &lat); err != nil {
+			http.Error(w, "unsupported sort", http.StatusBadRequest)
+			return
+		}
+		distance := func(doc map[string]any) float64 {
+			d, _ := geo.HaversineDistance(lat, lon, doc["lat"].(float64), doc["lon"].(float64))
+			return d
+		}
+		slices.SortStableFunc(matches, func(a, b map[string]any) int { return cmp.Compare(distance(a), distance(b)) })
+	}
+
+	offset := 0
+	if req.Bookmark != "" {
+		var err error
+		if offset, err = strconv.Atoi(req.Bookmark); err != nil {
+			http.Error(w, "bad bookmark", http.StatusBadRequest)
+			return
+		}
+	}
+	end := min(offset+req.Limit, len(matches))
+	offset = min(offset, end)
+
+	rows := make([]map[string]any, 0, end-offset)
+	for _, doc := range matches[offset:end] {
+		fields := make(map[string]any, len(doc))
+		for k, v := range doc {
+			if k != "_id" && k != "_rev" {
+				fields[k] = v
+			}
+		}
+		rows = append(rows, map[string]any{
+			"id":     doc["_id"],
+			"order":  []any{1.0, 0},
+			"fields": fields,
+		})
+	}
+
+	f.writeJSON(w, map[string]any{
+		"total_rows": len(matches),
+		"bookmark":   strconv.Itoa(end),
+		"rows":       rows,
+	})
+}
+
+func (f *fakeCloudant) handleGetDocument(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	doc := f.find(r.PathValue("docid"))
+	if doc == nil {
+		f.writeError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", doc["_rev"]))
+	f.writeJSON(w, doc)
+}
+
+func (f *fakeCloudant) handlePutDocument(w http.ResponseWriter, r *http.Request) {
+	var doc map[string]any
+	if err := decodeBody(r, &doc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doc["_id"] = r.PathValue("docid")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rev, ok := f.put(doc)
+	if !ok {
+		f.writeError(w, http.StatusConflict, "conflict")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	f.writeJSON(w, map[string]any{"ok": true, "id": doc["_id"], "rev": rev})
+}
+
+func (f *fakeCloudant) handleDeleteDocument(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := r.PathValue("docid")
+	doc := f.find(id)
+	switch {
+	case doc == nil:
+		f.writeError(w, http.StatusNotFound, "not_found")
+	case doc["_rev"] != r.URL.Query().Get("rev"):
+		f.writeError(w, http.StatusConflict, "conflict")
+	default:
+		f.docs = slices.DeleteFunc(f.docs, func(d map[string]any) bool { return d["_id"] == id })
+		f.writeJSON(w, map[string]any{"ok": true, "id": id, "rev": "deleted"})
+	}
+}
+
+func (f *fakeCloudant) handleAllDocs(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Keys        []string `json:"keys"`
+		IncludeDocs bool     `json:"include_docs"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rows := make([]map[string]any, 0, len(req.Keys))
+	for _, key := range req.Keys {
+		doc := f.find(key)
+		if doc == nil {
+			rows = append(rows, map[string]any{"key": key, "error": "not_found"})
+			continue
+		}
+		row := map[string]any{"key": key, "id": key, "value": map[string]any{"rev": doc["_rev"]}}
+		if req.IncludeDocs {
+			row["doc"] = doc
+		}
+		rows = append(rows, row)
+	}
+	f.writeJSON(w, map[string]any{"total_rows": len(f.docs), "rows": rows})
+}
+
+func (f *fakeCloudant) handleBulkDocs(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Docs []map[string]any `json:"docs"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	results := make([]map[string]any, 0, len(req.Docs))
+	for _, doc := range req.Docs {
+		if rev, ok := f.put(doc); ok {
+			results = append(results, map[string]any{"ok": true, "id": doc["_id"], "rev": rev})
+		} else {
+			results = append(results, map[string]any{"id": doc["_id"], "error": "conflict", "reason": "Document update conflict."})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	f.writeJSON(w, results)
+}
+
+func (f *fakeCloudant) handlePutDatabase(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.dbMissing {
+		f.writeError(w, http.StatusPreconditionFailed, "file_exists")
+		return
+	}
+	f.dbMissing = false
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	f.writeJSON(w, map[string]any{"ok": true})
+}
+
+func (f *fakeCloudant) handleGetDesignDocument(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ddoc, ok := f.ddocs[r.PathValue("ddoc")]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	f.writeJSON(w, ddoc)
+}
+
+func (f *fakeCloudant) handlePutDesignDocument(w http.ResponseWriter, r *http.Request) {
+	var ddoc map[string]any
+	if err := decodeBody(r, &ddoc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := r.PathValue("ddoc")
+	var generation int
+	if current, ok := f.ddocs[name]; ok {
+		if ddoc["_rev"] != current["_rev"] {
+			f.writeError(w, http.StatusConflict, "conflict")
+			return
+		}
+		fmt.Sscanf(current["_rev"].(string), "%d-", &generation)
+	}
+	rev := fmt.Sprintf("%d-fake", generation+1)
+	ddoc["_id"], ddoc["_rev"] = "_design/"+name, rev
+	f.ddocs[name] = ddoc
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	f.writeJSON(w, map[string]any{"ok": true, "id": ddoc["_id"], "rev": rev})
+}
+
+func (f *fakeCloudant) handleFind(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Selector map[string]any `json:"selector"`
+		Fields   []string       `json:"fields"`
+		Limit    int            `json:"limit"`
+		Bookmark string         `json:"bookmark"`
+		UseIndex []string       `json:"use_index"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.findIndexes = append(f.findIndexes, req.UseIndex)
+
+	var matches []map[string]any
+	for _, doc := range f.docs {
+		if matchSelector(doc, req.Selector) {
+			matches = append(matches, doc)
+		}
+	}
+	offset := 0
+	if req.Bookmark != "" {
+		offset, _ = strconv.Atoi(req.Bookmark)
+	}
+	end := min(offset+req.Limit, len(matches))
+	offset = min(offset, end)
+
+	docs := make([]map[string]any, 0, end-offset)
+	for _, doc := range matches[offset:end] {
+		projected := make(map[string]any, len(req.Fields))
+		for _, field := range req.Fields {
+			if v, ok := doc[field]; ok {
+				projected[field] = v
+			}
+		}
+		docs = append(docs, projected)
+	}
+	f.writeJSON(w, map[string]any{"docs": docs, "bookmark": strconv.Itoa(end)})
+}
+
+// matchSelector evaluates the subset of Mango used by MangoRepository:
+// $gte and $lte conditions on numeric fields, combined with $or.
+func matchSelector(doc map[string]any, selector map[string]any) bool {
+	for key, cond := range selector {
+		if key == "$or" {
+			matched := false
+			for _, alternative := range cond.([]any) {
+				if matchSelector(doc, alternative.(map[string]any)) {
+					matched = true
+				}
+			}
+			if !matched {
+				return false
+			}
+			continue
+		}
+		value, ok := doc[key].(float64)
+		if !ok {
+			return false
+		}
+		for op, operand := range cond.(map[string]any) {
+			switch op {
+			case "$gte":
+				ok = value >= operand.(float64)
+			case "$lte":
+				ok = value <= operand.(float64)
+			default:
+				panic("unsupported operator " + op)
+			}
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (f *fakeCloudant) handleGeo(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	match, err := geoMatcher(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.geoQueries = append(f.geoQueries, query)
+
+	var matches []map[string]any
+	for _, doc := range f.docs {
+		// Like the geo index function, only numeric coordinates are indexed.
+		lat, latOk := doc["lat"].(float64)
+		lon, lonOk := doc["lon"].(float64)
+		if latOk && lonOk && match(lat, lon) {
+			matches = append(matches, doc)
+		}
+	}
+	offset, _ := strconv.Atoi(query.Get("bookmark"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	end := min(offset+limit, len(matches))
+	offset = min(offset, end)
+
+	rows := make([]map[string]any, 0, end-offset)
+	for _, doc := range matches[offset:end] {
+		rows = append(rows, map[string]any{"id": doc["_id"], "rev": doc["_rev"], "doc": doc})
+	}
+	f.writeJSON(w, map[string]any{"bookmark": strconv.Itoa(end), "rows": rows})
+}
+
+// geoMatcher returns the test for the geometry of a _geo query: a bbox, a
+// radius around lat and lon, or a WKT polygon in g.
+func geoMatcher(query url.Values) (func(lat, lon float64) bool, error) {
+	switch {
+	case query.Has("bbox"):
+		var minLon, minLat, maxLon, maxLat float64
+		if _, err := fmt.Sscanf(query.Get("bbox"), "%g,%g,%g,%g", &minLon, &minLat, &maxLon, &maxLat); err != nil {
+			return nil, fmt.Errorf("bad bbox: %v", err)
+		}
+		return func(lat, lon float64) bool {
+			return lat >= minLat && lat <= maxLat && lon >= minLon && lon <= maxLon
+		}, nil
+	case query.Has("radius"):
+		lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+		lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+		radius, errRadius := strconv.ParseFloat(query.Get("radius"), 64)
+		if errLat != nil || errLon != nil || errRadius != nil {
+			return nil, fmt.Errorf("bad radius query")
+		}
+		return func(hubLat, hubLon float64) bool {
+			d, _ := geo.HaversineDistance(lat, lon, hubLat, hubLon)
+			return d*1000 <= radius
+		}, nil
+	case query.Has("g"):
+		wkt := query.Get("g")
+		if !strings.HasPrefix(wkt, "POLYGON ((") || !strings.HasSuffix(wkt, "))") {
+			return nil, fmt.Errorf("unsupported geometry %q", wkt)
+		}
+		var polygon []geo.Point
+		for _, vertex := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(wkt, "POLYGON (("), "))"), ", ") {
+			var p geo.Point
+			if _, err := fmt.Sscanf(vertex, "%g %g", &p.Lon, &p.Lat); err != nil {
+				return nil, fmt.Errorf("bad vertex %q: %v", vertex, err)
+			}
+			polygon = append(polygon, p)
+		}
+		return func(lat, lon float64) bool { return geo.PointInPolygon(lat, lon, polygon) }, nil
+	default:
+		return nil, fmt.Errorf("no geometry in query")
+	}
+}
+
+func (f *fakeCloudant) handleGetIndexes(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writeJSON(w, map[string]any{"total_rows": len(f.indexes), "indexes": append([]map[string]any{}, f.indexes...)})
+}
+
+func (f *fakeCloudant) handlePostIndex(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Ddoc  string         `json:"ddoc"`
+		Name  string         `json:"name"`
+		Type  string         `json:"type"`
+		Index map[string]any `json:"index"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := "_design/" + req.Ddoc
+	for _, index := range f.indexes {
+		if index["ddoc"] == id && index["name"] == req.Name {
+			f.writeJSON(w, map[string]any{"id": id, "name": req.Name, "result": "exists"})
+			return
+		}
+	}
+	f.indexes = append(f.indexes, map[string]any{"ddoc": id, "name": req.Name, "type": req.Type, "def": req.Index})
+	f.writeJSON(w, map[string]any{"id": id, "name": req.Name, "result": "created"})
+}
+
+func (f *fakeCloudant) handleDeleteIndex(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := "_design/" + r.PathValue("ddoc")
+	before := len(f.indexes)
+	f.indexes = slices.DeleteFunc(f.indexes, func(index map[string]any) bool {
+		return index["ddoc"] == id && index["name"] == r.PathValue("name")
+	})
+	if len(f.indexes) == before {
+		f.writeError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	f.writeJSON(w, map[string]any{"ok": true})
+}
+
+// find returns the document with the given ID, or nil. The caller must
+// hold f.mu.
+func (f *fakeCloudant) find(id string) map[string]any {
+	for _, doc := range f.docs {
+		if doc["_id"] == id {
+			return doc
+		}
+	}
+	return nil
+}
+
+// put stores doc if its _rev matches the stored revision, or if it has none
+// and the document does not exist yet. The caller must hold f.mu.
+func (f *fakeCloudant) put(doc map[string]any) (string, bool) {
+	current := f.find(doc["_id"].(string))
+	var generation int
+	if current != nil {
+		if doc["_rev"] != current["_rev"] {
+			return "", false
+		}
+		fmt.Sscanf(current["_rev"].(string), "%d-", &generation)
+	} else if doc["_rev"] != nil {
+		return "", false
+	}
+
+	rev := fmt.Sprintf("%d-fake", generation+1)
+	doc["_rev"] = rev
+	if current != nil {
+		clear(current)
+		maps.Copy(current, doc)
+	} else {
+		f.docs = append(f.docs, doc)
+	}
+	return rev, true
+}
+
+func (f *fakeCloudant) writeError(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	f.writeJSON(w, map[string]any{"error": reason, "reason": reason})
+}
+
+// match evaluates the two query shapes produced by buildSearchQuery, a single
+// lat range combined with one or more alternative lon ranges, and the name
+// queries of text searches, which require every term in the name.
+func (f *fakeCloudant) match(query string) []map[string]any {
+	if terms, ok := strings.CutPrefix(query, "name:("); ok {
+		var matches []map[string]any
+		for _, doc := range f.docs {
+			name, _ := doc["name"].(string)
+			words := repository.TextTerms(name)
+			if !slices.ContainsFunc(strings.Split(strings.TrimSuffix(terms, ")"), " AND "), func(term string) bool {
+				return !slices.Contains(words, term)
+			}) {
+				matches = append(matches, doc)
+			}
+		}
+		return matches
+	}
+
+	var latRange [2]float64
+	var lonRanges [][2]float64
+	for _, m := range searchRangePattern.FindAllStringSubmatch(query, -1) {
+		lo, errLo := strconv.ParseFloat(m[2], 64)
+		hi, errHi := strconv.ParseFloat(m[3], 64)
+		if errLo != nil || errHi != nil {
+			f.t.Errorf("unparseable range in query %q", query)
+			return nil
+		}
+		if m[1] == "lat" {
+			latRange = [2]float64{lo, hi}
+		} else {
+			lonRanges = append(lonRanges, [2]float64{lo, hi})
+		}
+	}
+	if !strings.HasPrefix(query, "lat:") || len(lonRanges) == 0 {
+		f.t.Errorf("unexpected query %q", query)
+		return nil
+	}
+
+	var matches []map[string]any
+	for _, doc := range f.docs {
+		lat, latOk := doc["lat"].(float64)
+		lon, lonOk := doc["lon"].(float64)
+		if !latOk || !lonOk {
+			// Rows the fake cannot range-check are always returned so that
+			// the repository's handling of malformed rows can be exercised.
+			matches = append(matches, doc)
+			continue
+		}
+		if lat < latRange[0] || lat > latRange[1] {
+			continue
+		}
+		for _, lr := range lonRanges {
+			if lon >= lr[0] && lon <= lr[1] {
+				matches = append(matches, doc)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// decodeBody reads a JSON request body, undoing the gzip compression the SDK
+// applies to outgoing requests.
+func decodeBody(r *http.Request, v any) error {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	return json.NewDecoder(body).Decode(v)
+}
+
+func (f *fakeCloudant) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		f.t.Errorf("encode response: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// Compile-time checks that FallbackRepository implements the optional
+// repository interfaces.
+var (
+	_ DetailedRepository = (*FallbackRepository)(nil)
+	_ IDGetter           = (*FallbackRepository)(nil)
+	_ RadiusSearcher     = (*FallbackRepository)(nil)
+	_ NearestSearcher    = (*FallbackRepository)(nil)
+	_ TextSearcher       = (*FallbackRepository)(nil)
+	_ Counter            = (*FallbackRepository)(nil)
+)
+
+// FallbackRepository answers from a primary repository and falls back to a
+// local snapshot when the primary is unreachable or times out. Results served
+// from the snapshot are marked as stale.
+//
+// Radius, nearest, text and count queries are passed to the primary when it
+// implements RadiusSearcher, NearestSearcher, TextSearcher or Counter, and
+// are otherwise answered with a bounds query, as they are from the snapshot.
+type FallbackRepository struct {
+	primary  Repository
+	snapshot *MemoryRepository
+	created  time.Time
+	timeout  time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+type FallbackConfig struct {
+	Primary  Repository
+	Snapshot *Snapshot
+	// PrimaryTimeout bounds each call to the primary repository, so a
+	// backend that hangs is treated like one that is down. Zero leaves calls
+	// bounded only by the caller's context.
+	PrimaryTimeout time.Duration
+	// Logger records when the snapshot is used. Defaults to slog.Default().
+	Logger *slog.Logger
+	// Now returns the current time for computing the snapshot age. Defaults
+	// to time.Now.
+	Now func() time.Time
+}
+
+func NewFallbackRepository(cfg FallbackConfig) (*FallbackRepository, error) {
+	if cfg.Primary == nil {
+		return nil, fmt.Errorf("fallback repository: primary repository is required")
+	}
+	if cfg.Snapshot == nil {
+		return nil, fmt.Errorf("fallback repository: snapshot is required")
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return &FallbackRepository{
+		primary:  cfg.Primary,
+		snapshot: NewMemoryRepository(cfg.Snapshot.Hubs),
+		created:  cfg.Snapshot.CreatedAt,
+		timeout:  cfg.PrimaryTimeout,
+		logger:   logger.With("component", "repository", "backend", "fallback"),
+		now:      now,
+	}, nil
+}
+
+func (r *FallbackRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// GetByBoundsDetailed queries the primary repository and, if it cannot be
+// reached, the snapshot. Errors that retrying would not fix, such as a
+// rejected request or a malformed row, are returned without falling back.
+func (r *FallbackRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (Result, error) {
+	return r.query(ctx, func(ctx context.Context, repo Repository) (Result, error) {
+		return getByBoundsDetailed(ctx, repo, minLat, maxLat, minLon, maxLon)
+	})
+}
+
+// GetByRadius returns the hubs within radiusKm of the given point from the
+// primary repository or, if it cannot be reached, the snapshot.
+func (r *FallbackRepository) GetByRadius(ctx context.Context, lat, lon, radiusKm float64) (Result, error) {
+	return r.query(ctx, func(ctx context.Context, repo Repository) (Result, error) {
+		return getByRadius(ctx, repo, lat, lon, radiusKm)
+	})
+}
+
+// GetNearest returns up to count hubs within maxRadiusKm of the given point,
+// closest first, from the primary repository or, if it cannot be reached,
+// the snapshot.
+func (r *FallbackRepository) GetNearest(ctx context.Context, lat, lon float64, count int, maxRadiusKm float64) (Result, error) {
+	return r.query(ctx, func(ctx context.Context, repo Repository) (Result, error) {
+		return getNearest(ctx, repo, lat, lon, count, maxRadiusKm)
+	})
+}
+
+// SearchText returns the hubs whose name contains every term of text from
+// the primary repository or, if it cannot be reached, the snapshot.
+func (r *FallbackRepository) SearchText(ctx context.Context, text string) (Result, error) {
+	return r.query(ctx, func(ctx context.Context, repo Repository) (Result, error) {
+		return searchText(ctx, repo, text)
+	})
+}
+
+// CountByBounds counts the hubs within the bounds in the primary repository
+// or, if it cannot be reached, the snapshot. As a count cannot be marked
+// stale, only the log shows that the snapshot was used.
+func (r *FallbackRepository) CountByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (int, error) {
+	var count int
+	_, err := r.query(ctx, func(ctx context.Context, repo Repository) (Result, error) {
+		var err error
+		count, err = countByBounds(ctx, repo, minLat, maxLat, minLon, maxLon)
+		return Result{}, err
+	})
+	return count, err
+}
+
+// query runs fn against the primary repository and, if the primary cannot
+// be reached, against the snapshot, marking the result as stale.
+func (r *FallbackRepository) query(ctx context.Context, fn func(context.Context, Repository) (Result, error)) (Result, error) {
+	primaryCtx, cancel := r.primaryContext(ctx)
+	defer cancel()
+
+	result, err := fn(primaryCtx, r.primary)
+	if err == nil || !r.shouldFallBack(ctx, err) {
+		return result, err
+	}
+
+	age := r.age()
+	r.logger.WarnContext(ctx, "primary repository unavailable, answering from snapshot",
+		"error", err, "snapshot_age", age)
+	result, err = fn(ctx, r.snapshot)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Stale = true
+	result.SnapshotAge = age
+	return result, nil
+}
+
+// GetHub looks the hub up in the primary repository and, if it cannot be
+// reached, in the snapshot.
+func (r *FallbackRepository) GetHub(ctx context.Context, id string) (model.Hub, error) {
+	primaryCtx, cancel := r.primaryContext(ctx)
+	defer cancel()
+
+	var (
+		hub model.Hub
+		err error
+	)
+	if getter, ok := r.primary.(IDGetter); ok {
+		hub, err = getter.GetHub(primaryCtx, id)
+	} else {
+		hub, err = scanForHub(primaryCtx, r.primary, id)
+	}
+	if err == nil || !r.shouldFallBack(ctx, err) {
+		return hub, err
+	}
+
+	r.logger.WarnContext(ctx, "primary repository unavailable, answering from snapshot",
+		"error", err, "snapshot_age", r.age(), "id", id)
+	return r.snapshot.GetHub(ctx, id)
+}
+
+// SnapshotAge returns how old the fallback snapshot is.
+func (r *FallbackRepository) SnapshotAge() time.Duration {
+	return r.age()
+}
+
+func (r *FallbackRepository) primaryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+// shouldFallBack reports whether err means the primary could not be
+// reached. Timeouts count only while the caller's own context is still live.
+func (r *FallbackRepository) shouldFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var backendErr *BackendError
+	switch {
+	case errors.As(err, &backendErr):
+		return backendErr.Retryable
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *FallbackRepository) age() time.Duration {
+	return max(r.now().Sub(r.created), 0)
+}
+
+// scanForHub finds a hub by ID in a repository that cannot look hubs up
+// directly.
+func scanForHub(ctx context.Context, repo Repository, id string) (model.Hub, error) {
+	hubs, err := repo.GetByBounds(ctx, -90, 90, -180, 180)
+	if err != nil {
+		return model.Hub{}, err
+	}
+	for _, hub := range hubs {
+		if hub.ID == id {
+			return hub, nil
+		}
+	}
+	return model.Hub{}, fmt.Errorf("hub %q: %w", id, ErrNotFound)
+}
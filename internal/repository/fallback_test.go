@@ -0,0 +1,282 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+type stubRepository struct {
+	hubs []model.Hub
+	err  error
+	// delay makes every call wait that long, or until its context is done.
+	delay time.Duration
+}
+
+func (r *stubRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	if r.delay > 0 {
+		select {
+		case <-time.After(r.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return repository.NewMemoryRepository(r.hubs).GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+}
+
+// capableRepository implements the optional search interfaces by returning
+// every hub, and records which of them were called.
+type capableRepository struct {
+	stubRepository
+	calls []string
+}
+
+func (r *capableRepository) answer(method string) (repository.Result, error) {
+	r.calls = append(r.calls, method)
+	if r.err != nil {
+		return repository.Result{}, r.err
+	}
+	return repository.Result{Hubs: slices.Clone(r.hubs)}, nil
+}
+
+func (r *capableRepository) GetByRadius(context.Context, float64, float64, float64) (repository.Result, error) {
+	return r.answer("GetByRadius")
+}
+
+func (r *capableRepository) GetNearest(context.Context, float64, float64, int, float64) (repository.Result, error) {
+	return r.answer("GetNearest")
+}
+
+func (r *capableRepository) SearchText(context.Context, string) (repository.Result, error) {
+	return r.answer("SearchText")
+}
+
+func (r *capableRepository) CountByBounds(context.Context, float64, float64, float64, float64) (int, error) {
+	result, err := r.answer("CountByBounds")
+	return len(result.Hubs), err
+}
+
+var (
+	liveHubs     = []model.Hub{{ID: "bud", Name: "Budapest (live)", Lat: 47.437, Lon: 19.261}}
+	snapshotHubs = []model.Hub{{ID: "bud", Name: "Budapest (snapshot)", Lat: 47.437, Lon: 19.261}}
+	snapshotTime = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func newTestFallbackRepository(t *testing.T, primary repository.Repository, timeout time.Duration) *repository.FallbackRepository {
+	t.Helper()
+	repo, err := repository.NewFallbackRepository(repository.FallbackConfig{
+		Primary:        primary,
+		Snapshot:       &repository.Snapshot{CreatedAt: snapshotTime, Hubs: snapshotHubs},
+		PrimaryTimeout: timeout,
+		Now:            func() time.Time { return snapshotTime.Add(3 * time.Hour) },
+	})
+	if err != nil {
+		t.Fatalf("create fallback repository: %v", err)
+	}
+	return repo
+}
+
+func TestFallbackRepository(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   *stubRepository
+		timeout   time.Duration
+		wantName  string
+		wantStale bool
+		wantErr   bool
+	}{
+		{
+			name:     "primary available",
+			primary:  &stubRepository{hubs: liveHubs},
+			wantName: "Budapest (live)",
+		},
+		{
+			name:      "primary unreachable",
+			primary:   &stubRepository{err: &repository.BackendError{Op: "post search", Retryable: true, Err: errors.New("connection refused")}},
+			wantName:  "Budapest (snapshot)",
+			wantStale: true,
+		},
+		{
+			name:      "primary times out",
+			primary:   &stubRepository{hubs: liveHubs, delay: time.Second},
+			timeout:   20 * time.Millisecond,
+			wantName:  "Budapest (snapshot)",
+			wantStale: true,
+		},
+		{
+			name:    "primary rejects request",
+			primary: &stubRepository{err: &repository.BackendError{Op: "post search", StatusCode: 401, Err: errors.New("unauthorized")}},
+			wantErr: true,
+		},
+		{
+			name:    "malformed row",
+			primary: &stubRepository{err: &repository.MalformedRowError{RowWarning: repository.RowWarning{ID: "x", Reason: "missing lat"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestFallbackRepository(t, tt.primary, tt.timeout)
+
+			result, err := repo.GetByBoundsDetailed(context.Background(), 40, 50, 10, 20)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result.Hubs) != 1 || result.Hubs[0].Name != tt.wantName {
+				t.Errorf("got %v, want %s", result.Hubs, tt.wantName)
+			}
+			if result.Stale != tt.wantStale {
+				t.Errorf("Stale = %v, want %v", result.Stale, tt.wantStale)
+			}
+			if tt.wantStale && result.SnapshotAge != 3*time.Hour {
+				t.Errorf("SnapshotAge = %v, want 3h", result.SnapshotAge)
+			}
+		})
+	}
+}
+
+func TestFallbackRepository_Capabilities(t *testing.T) {
+	ctx := context.Background()
+	unreachable := &repository.BackendError{Op: "post search", Retryable: true, Err: errors.New("connection refused")}
+
+	// query runs each capability of repo and returns the names of the hubs
+	// found, with a "(stale)" mark, and the count.
+	query := func(t *testing.T, repo *repository.FallbackRepository) ([]string, int) {
+		t.Helper()
+		var names []string
+		for _, search := range []func() (repository.Result, error){
+			func() (repository.Result, error) { return repo.GetByRadius(ctx, 47.5, 19, 50) },
+			func() (repository.Result, error) { return repo.GetNearest(ctx, 47.5, 19, 1, 50) },
+			func() (repository.Result, error) { return repo.SearchText(ctx, "budapest") },
+		} {
+			result, err := search()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, hub := range result.Hubs {
+				if result.Stale {
+					hub.Name += " (stale)"
+				}
+				names = append(names, hub.Name)
+			}
+		}
+		count, err := repo.CountByBounds(ctx, 40, 50, 10, 20)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return names, count
+	}
+
+	t.Run("primary implements the interfaces", func(t *testing.T) {
+		primary := &capableRepository{stubRepository: stubRepository{hubs: liveHubs}}
+		names, count := query(t, newTestFallbackRepository(t, primary, 0))
+
+		want := []string{"GetByRadius", "GetNearest", "SearchText", "CountByBounds"}
+		if !slices.Equal(primary.calls, want) {
+			t.Errorf("got calls %v, want %v", primary.calls, want)
+		}
+		if want := slices.Repeat([]string{liveHubs[0].Name}, 3); !slices.Equal(names, want) || count != 1 {
+			t.Errorf("got %v and count %d, want %v and 1", names, count, want)
+		}
+	})
+
+	t.Run("primary only answers bounds queries", func(t *testing.T) {
+		names, count := query(t, newTestFallbackRepository(t, &stubRepository{hubs: liveHubs}, 0))
+		if want := slices.Repeat([]string{liveHubs[0].Name}, 3); !slices.Equal(names, want) || count != 1 {
+			t.Errorf("got %v and count %d, want %v and 1", names, count, want)
+		}
+	})
+
+	t.Run("primary unreachable", func(t *testing.T) {
+		primary := &capableRepository{stubRepository: stubRepository{err: unreachable}}
+		names, count := query(t, newTestFallbackRepository(t, primary, 0))
+		if want := slices.Repeat([]string{snapshotHubs[0].Name + " (stale)"}, 3); !slices.Equal(names, want) || count != 1 {
+			t.Errorf("got %v and count %d, want %v and 1", names, count, want)
+		}
+	})
+
+	t.Run("no hubs match", func(t *testing.T) {
+		repo := newTestFallbackRepository(t, &stubRepository{hubs: liveHubs}, 0)
+		result, err := repo.GetByRadius(ctx, 47.5, 21, 50)
+		if err != nil || len(result.Hubs) != 0 {
+			t.Errorf("got %v, %v, want no hubs beyond the radius", result.Hubs, err)
+		}
+		if result, err = repo.SearchText(ctx, "vienna"); err != nil || len(result.Hubs) != 0 {
+			t.Errorf("got %v, %v, want no hubs without the name", result.Hubs, err)
+		}
+	})
+}
+
+func TestFallbackRepository_CallerCancellation(t *testing.T) {
+	repo := newTestFallbackRepository(t, &stubRepository{hubs: liveHubs, delay: time.Second}, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := repo.GetByBounds(ctx, 40, 50, 10, 20); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline to be returned, got %v", err)
+	}
+}
+
+func TestFallbackRepository_Cloudant(t *testing.T) {
+	fake := newFakeCloudant(t, hubDocs(liveHubs))
+	repo := newTestFallbackRepository(t, newTestCloudantRepository(t, fake, nil), 0)
+
+	result, err := repo.GetByBoundsDetailed(context.Background(), 40, 50, 10, 20)
+	if err != nil || result.Stale {
+		t.Fatalf("expected a live result, got %+v, %v", result, err)
+	}
+
+	fake.searchStatus = 503
+	result, err = repo.GetByBoundsDetailed(context.Background(), 40, 50, 10, 20)
+	if err != nil || !result.Stale {
+		t.Fatalf("expected a stale result, got %+v, %v", result, err)
+	}
+
+	fake.server.Close()
+	hub, err := repo.GetHub(context.Background(), "bud")
+	if err != nil || hub.Name != "Budapest (snapshot)" {
+		t.Errorf("expected the snapshot hub, got %+v, %v", hub, err)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hubs.json")
+	want := &repository.Snapshot{CreatedAt: snapshotTime, Hubs: snapshotHubs}
+
+	if err := repository.WriteSnapshot(path, want); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	got, err := repository.LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"hubs": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.LoadSnapshot(path); err == nil {
+		t.Error("expected an error for a snapshot without created_at")
+	}
+}
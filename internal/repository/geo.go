@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/cloudant-go-sdk/cloudantv1"
+	"github.com/IBM/cloudant-go-sdk/common"
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultGeoDdoc and DefaultGeoIndex name the geospatial index used by
+	// GeoRepository unless configured otherwise.
+	DefaultGeoDdoc  = "hubfinder-geo"
+	DefaultGeoIndex = "location"
+)
+
+// GeoIndexFunction is the geospatial index function GeoRepository queries.
+// Hubs are stored with plain lat and lon fields, so the function builds the
+// GeoJSON point the index needs from them.
+const GeoIndexFunction = `function (doc) {
+  if (typeof doc.lat === "number" && typeof doc.lon === "number") {
+    st_index({"type": "Point", "coordinates": [doc.lon, doc.lat]});
+  }
+}`
+
+// Compile-time checks that GeoRepository implements the optional
+// repository interfaces.
+var (
+	_ DetailedRepository = (*GeoRepository)(nil)
+	_ RadiusSearcher     = (*GeoRepository)(nil)
+	_ PolygonSearcher    = (*GeoRepository)(nil)
+	_ IDGetter           = (*GeoRepository)(nil)
+	_ ChangesSource      = (*GeoRepository)(nil)
+	_ WritableRepository = (*GeoRepository)(nil)
+)
+
+// GeoRepository answers bounds, radius and polygon queries with a Cloudant
+// geospatial (_geo) index, so radius and polygon searches need no bounding
+// box over-fetch. Document lookups, the changes feed and writes work as in
+// CloudantRepository.
+type GeoRepository struct {
+	*cloudantClient
+}
+
+// NewGeoRepository creates a repository for the database in cfg. Ddoc and
+// Index name the geospatial index and default to DefaultGeoDdoc and
+// DefaultGeoIndex. CoerceStrings has no effect on queries, as the index
+// only holds hubs with numeric coordinates.
+func NewGeoRepository(cfg CloudantConfig) (*GeoRepository, error) {
+	if cfg.Ddoc == "" {
+		cfg.Ddoc = DefaultGeoDdoc
+	}
+	if cfg.Index == "" {
+		cfg.Index = DefaultGeoIndex
+	}
+	client, err := newCloudantClient(cfg, "geo")
+	if err != nil {
+		return nil, err
+	}
+	return &GeoRepository{cloudantClient: client}, nil
+}
+
+func (r *GeoRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// GetByBoundsDetailed retrieves all hubs within the specified geographic
+// bounds with bbox queries. A box wrapping the antimeridian is split into
+// one query on each side of it.
+func (r *GeoRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (Result, error) {
+	if minLon <= maxLon {
+		return r.query(ctx, "bbox", geoParams{"bbox": formatBBox(minLat, maxLat, minLon, maxLon)},
+			telemetry.BoundsAttributes(minLat, maxLat, minLon, maxLon)...)
+	}
+	east, err := r.query(ctx, "bbox", geoParams{"bbox": formatBBox(minLat, maxLat, minLon, 180)},
+		telemetry.BoundsAttributes(minLat, maxLat, minLon, 180)...)
+	if err != nil {
+		return Result{}, err
+	}
+	west, err := r.query(ctx, "bbox", geoParams{"bbox": formatBBox(minLat, maxLat, -180, maxLon)},
+		telemetry.BoundsAttributes(minLat, maxLat, -180, maxLon)...)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Hubs:     append(east.Hubs, west.Hubs...),
+		Warnings: append(east.Warnings, west.Warnings...),
+	}, nil
+}
+
+// GetByRadius retrieves the hubs within radiusKm of the given point with a
+// single radius query.
+func (r *GeoRepository) GetByRadius(ctx context.Context, lat, lon, radiusKm float64) (Result, error) {
+	if err := geo.ValidateCoordinates(lat, lon); err != nil {
+		return Result{}, err
+	}
+	return r.query(ctx, "radius", geoParams{
+		"lat":    formatCoordinate(lat),
+		"lon":    formatCoordinate(lon),
+		"radius": strconv.FormatFloat(radiusKm*1000, 'f', -1, 64),
+	},
+		attribute.Float64("hubfinder.lat", lat),
+		attribute.Float64("hubfinder.lon", lon),
+		attribute.Float64("hubfinder.radius_km", radiusKm),
+	)
+}
+
+// GetByPolygon retrieves the hubs inside polygon or on its boundary with a
+// single polygon query.
+func (r *GeoRepository) GetByPolygon(ctx context.Context, polygon []geo.Point) (Result, error) {
+	if err := geo.ValidatePolygon(polygon); err != nil {
+		return Result{}, err
+	}
+	return r.query(ctx, "polygon", geoParams{"g": polygonWKT(polygon), "relation": "intersects"},
+		attribute.Int("hubfinder.vertex_count", len(polygon)),
+	)
+}
+
+// geoParams are the query parameters of a _geo request that select the
+// geometry to match.
+type geoParams map[string]string
+
+// geoResponse is the body of a _geo response in the default view format.
+type geoResponse struct {
+	Bookmark string `json:"bookmark"`
+	Rows     []struct {
+		ID  string         `json:"id"`
+		Doc map[string]any `json:"doc"`
+	} `json:"rows"`
+}
+
+// query runs a _geo query, following bookmarks until a page comes back
+// short, and applies the configured RowPolicy to malformed documents.
+func (r *GeoRepository) query(ctx context.Context, shape string, params geoParams, attrs ...attribute.KeyValue) (_ Result, err error) {
+	start := time.Now()
+
+	ctx, span := r.tracer.Start(ctx, "GeoRepository.Query", trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(attrs...)
+	span.SetAttributes(
+		attribute.String("db.system", "cloudant"),
+		attribute.String("db.namespace", r.db),
+		attribute.String("hubfinder.geo_shape", shape),
+	)
+	pages := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("hubfinder.pages", pages))
+		r.metrics.AddBackendPages("geo", pages)
+		r.metrics.ObserveQuery("backend_geo", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+	r.logger.DebugContext(ctx, "geo query started", "db", r.db, "ddoc", r.ddoc, "index", r.index, "shape", shape, "params", params)
+
+	rows := rowCollector{decoder: r.decoder, policy: r.policy, hubs: make([]model.Hub, 0, pageSize)}
+	bookmark := ""
+	for {
+		pageStart := time.Now()
+		result, response, err := r.getGeo(ctx, params, bookmark)
+		if err != nil {
+			return Result{}, newBackendError(ctx, "get geo", statusCode(response), err)
+		}
+		pages++
+		r.logger.DebugContext(ctx, "geo page fetched",
+			"page", pages,
+			"bookmark", bookmark,
+			"rows", len(result.Rows),
+			"duration", time.Since(pageStart),
+		)
+
+		for _, row := range result.Rows {
+			warning, err := rows.add(&row.ID, row.Doc)
+			if err != nil {
+				return Result{}, err
+			}
+			if warning != nil {
+				r.logger.DebugContext(ctx, "skipped malformed row", "id", warning.ID, "reason", warning.Reason)
+			}
+		}
+
+		if len(result.Rows) < pageSize || result.Bookmark == "" {
+			break
+		}
+		bookmark = result.Bookmark
+	}
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.result_count", len(rows.hubs)),
+		attribute.Int("hubfinder.skipped_rows", rows.skipped),
+	)
+	r.logger.DebugContext(ctx, "geo query finished",
+		"shape", shape,
+		"pages", pages,
+		"hubs", len(rows.hubs),
+		"skipped", rows.skipped,
+		"duration", time.Since(start),
+	)
+	return rows.result(), nil
+}
+
+// getGeo fetches one page of a _geo query. The Cloudant SDK has no
+// operation for geospatial queries, so the request is built by hand and
+// sent through the SDK's service to keep its authentication and retries.
+func (r *GeoRepository) getGeo(ctx context.Context, params geoParams, bookmark string) (*geoResponse, *core.DetailedResponse, error) {
+	builder := core.NewRequestBuilder(core.GET).WithContext(ctx)
+	_, err := builder.ResolveRequestURL(r.service.GetServiceURL(), `/{db}/_design/{ddoc}/_geo/{index}`, map[string]string{
+		"db":    r.db,
+		"ddoc":  r.ddoc,
+		"index": r.index,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, value := range common.GetSdkHeaders("cloudant", "V1", "GetGeo") {
+		builder.AddHeader(name, value)
+	}
+	builder.AddHeader("Accept", "application/json")
+	for name, value := range params {
+		builder.AddQuery(name, value)
+	}
+	builder.AddQuery("include_docs", "true")
+	builder.AddQuery("limit", strconv.Itoa(pageSize))
+	if bookmark != "" {
+		builder.AddQuery("bookmark", bookmark)
+	}
+	request, err := builder.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &geoResponse{}
+	response, err := r.service.Service.Request(request, &result)
+	if err != nil {
+		return nil, response, err
+	}
+	return result, response, nil
+}
+
+// formatBBox formats bounds as the bbox parameter of a _geo query, which
+// lists longitude before latitude.
+func formatBBox(minLat, maxLat, minLon, maxLon float64) string {
+	return strings.Join([]string{
+		formatCoordinate(minLon),
+		formatCoordinate(minLat),
+		formatCoordinate(maxLon),
+		formatCoordinate(maxLat),
+	}, ",")
+}
+
+// polygonWKT formats polygon as a closed WKT polygon with longitude before
+// latitude.
+func polygonWKT(polygon []geo.Point) string {
+	ring := polygon
+	if first, last := polygon[0], polygon[len(polygon)-1]; first != last {
+		ring = append(ring[:len(ring):len(ring)], first)
+	}
+	vertices := make([]string, len(ring))
+	for i, p := range ring {
+		vertices[i] = formatCoordinate(p.Lon) + " " + formatCoordinate(p.Lat)
+	}
+	return "POLYGON ((" + strings.Join(vertices, ", ") + "))"
+}
+
+func formatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Provision creates the database and the design document with the
+// geospatial index the repository queries, leaving whatever already matches
+// alone. Other indexes and views in the design document are kept. If an
+// existing index differs and opts.Replace is not set, the report lists the
+// difference and the error wraps ErrIndexMismatch.
+func (r *GeoRepository) Provision(ctx context.Context, opts ProvisionOptions) (report ProvisionReport, err error) {
+	ctx, finish := r.startWrite(ctx, "GeoRepository.Provision", "backend_provision", "")
+	defer func() { finish(err) }()
+
+	if report.CreatedDatabase, err = r.createDatabase(ctx); err != nil {
+		return report, err
+	}
+
+	ddocID := "_design/" + r.ddoc
+	ddoc, response, err := r.service.GetDesignDocumentWithContext(ctx, &cloudantv1.GetDesignDocumentOptions{
+		Db:   new(r.db),
+		Ddoc: new(r.ddoc),
+	})
+	switch {
+	case statusCode(response) == http.StatusNotFound:
+		ddoc = &cloudantv1.DesignDocument{}
+	case err != nil:
+		return report, newBackendError(ctx, "get design document", statusCode(response), err)
+	}
+
+	// The SDK does not model st_indexes, so they are kept as an additional
+	// property of the design document.
+	indexes, _ := ddoc.GetProperty("st_indexes").(map[string]any)
+	if indexes == nil {
+		indexes = map[string]any{}
+	}
+
+	name := ddocID + "/" + r.index
+	existing, exists := indexes[r.index].(map[string]any)
+	function, _ := existing["index"].(string)
+	switch {
+	case !exists:
+		report.Created = append(report.Created, name)
+	case normalizeFunction(function) == normalizeFunction(GeoIndexFunction):
+		return report, nil
+	case opts.Replace:
+		report.Replaced = append(report.Replaced, name)
+	default:
+		report.Mismatches = append(report.Mismatches, name+": index function differs")
+		return report, fmt.Errorf("%s: %w", name, ErrIndexMismatch)
+	}
+
+	indexes[r.index] = map[string]any{"index": GeoIndexFunction}
+	ddoc.SetProperty("st_indexes", indexes)
+	ddoc.Conflicts, ddoc.DeletedConflicts, ddoc.RevsInfo, ddoc.Revisions, ddoc.LocalSeq = nil, nil, nil, nil, nil
+	_, response, err = r.service.PutDesignDocumentWithContext(ctx, &cloudantv1.PutDesignDocumentOptions{
+		Db:             new(r.db),
+		Ddoc:           new(r.ddoc),
+		DesignDocument: ddoc,
+	})
+	if err != nil {
+		return report, writeError(ctx, "put design document", response, err)
+	}
+	r.logger.InfoContext(ctx, "geo index written", "index", name)
+	return report, nil
+}
@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+)
+
+func newTestGeoRepository(t *testing.T, fake *fakeCloudant, cfg repository.CloudantConfig) *repository.GeoRepository {
+	t.Helper()
+
+	cfg.BaseURL = fake.server.URL
+	cfg.DB = "airportdb"
+	repo, err := repository.NewGeoRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+	return repo
+}
+
+func TestGeoRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		return newTestGeoRepository(t, newFakeCloudant(t, hubDocs(hubs)), repository.CloudantConfig{})
+	})
+}
+
+func TestGeoRepository_NativeQueries(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeCloudant(t, hubDocs(repositorytest.Fixture()))
+	repo := newTestGeoRepository(t, fake, repository.CloudantConfig{})
+
+	// JFK and Newark are within 30 km of New York; the bounding box of the
+	// circle would be the same, but the corners hold no hubs here.
+	result, err := repo.GetByRadius(ctx, 40.713, -74.006, 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := hubIDs(result.Hubs); !slices.Equal(got, []string{"ewr", "jfk", "nyc"}) {
+		t.Errorf("radius: got %v", got)
+	}
+	if q := fake.geoQueries[0]; q.Get("radius") != "30000" || q.Get("lat") != "40.713" || q.Get("lon") != "-74.006" {
+		t.Errorf("radius: unexpected query %v", q)
+	}
+
+	triangle := []geo.Point{{Lat: 19, Lon: 29}, {Lat: 22, Lon: 29}, {Lat: 19, Lon: 32}}
+	result, err = repo.GetByPolygon(ctx, triangle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := hubIDs(result.Hubs); !slices.Equal(got, []string{"corner-sw"}) {
+		t.Errorf("polygon: got %v", got)
+	}
+	if want := "POLYGON ((29 19, 29 22, 32 19, 29 19))"; fake.geoQueries[1].Get("g") != want {
+		t.Errorf("polygon: got g=%q, want %q", fake.geoQueries[1].Get("g"), want)
+	}
+
+	if _, err := repo.GetByRadius(ctx, 91, 0, 10); !errors.Is(err, geo.ErrInvalidLatitude) {
+		t.Errorf("expected ErrInvalidLatitude, got %v", err)
+	}
+	if _, err := repo.GetByPolygon(ctx, triangle[:2]); !errors.Is(err, geo.ErrInvalidPolygon) {
+		t.Errorf("expected ErrInvalidPolygon, got %v", err)
+	}
+}
+
+func TestGeoRepository_Pages(t *testing.T) {
+	hubs := make([]model.Hub, 0, 450)
+	for i := range 450 {
+		hubs = append(hubs, model.Hub{ID: fmt.Sprintf("hub-%d", i), Name: "Hub", Lat: 1, Lon: 1})
+	}
+	fake := newFakeCloudant(t, hubDocs(hubs))
+	repo := newTestGeoRepository(t, fake, repository.CloudantConfig{})
+
+	result, err := repo.GetByRadius(context.Background(), 1, 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 450 {
+		t.Errorf("got %d hubs, want 450", len(result.Hubs))
+	}
+	if len(fake.geoQueries) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(fake.geoQueries))
+	}
+}
+
+func TestGeoRepository_RowPolicy(t *testing.T) {
+	docs := []map[string]any{
+		{"_id": "good", "lat": 47.43, "lon": 19.26, "name": "Budapest"},
+		{"_id": "no-name", "lat": 47.2, "lon": 19.3},
+	}
+
+	repo := newTestGeoRepository(t, newFakeCloudant(t, docs), repository.CloudantConfig{RowPolicy: repository.RowPolicyWarn})
+	result, err := repo.GetByRadius(context.Background(), 47.3, 19.3, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 1 || len(result.Warnings) != 1 || result.Warnings[0].ID != "no-name" {
+		t.Errorf("got hubs %v and warnings %v", result.Hubs, result.Warnings)
+	}
+
+	repo = newTestGeoRepository(t, newFakeCloudant(t, docs), repository.CloudantConfig{RowPolicy: repository.RowPolicyFail})
+	var malformed *repository.MalformedRowError
+	if _, err := repo.GetByBounds(context.Background(), 40, 50, 10, 20); !errors.As(err, &malformed) {
+		t.Errorf("expected *MalformedRowError, got %v", err)
+	}
+}
+
+func TestGeoRepository_Provision(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeCloudant(t, nil)
+	fake.dbMissing = true
+	repo := newTestGeoRepository(t, fake, repository.CloudantConfig{})
+
+	report, err := repo.Provision(ctx, repository.ProvisionOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	name := "_design/" + repository.DefaultGeoDdoc + "/" + repository.DefaultGeoIndex
+	if !report.CreatedDatabase || !slices.Equal(report.Created, []string{name}) {
+		t.Errorf("first run: unexpected report %+v", report)
+	}
+
+	if report, err = repo.Provision(ctx, repository.ProvisionOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.CreatedDatabase || len(report.Created) != 0 || len(report.Replaced) != 0 {
+		t.Errorf("second run: expected no changes, got %+v", report)
+	}
+
+	stIndexes := fake.ddocs[repository.DefaultGeoDdoc]["st_indexes"].(map[string]any)
+	stIndexes[repository.DefaultGeoIndex] = map[string]any{"index": "function (doc) {}"}
+	stIndexes["other"] = map[string]any{"index": "function (doc) {}"}
+	report, err = repo.Provision(ctx, repository.ProvisionOptions{})
+	if !errors.Is(err, repository.ErrIndexMismatch) || len(report.Mismatches) != 1 {
+		t.Fatalf("expected a mismatch, got %+v, %v", report, err)
+	}
+
+	if report, err = repo.Provision(ctx, repository.ProvisionOptions{Replace: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(report.Replaced, []string{name}) {
+		t.Errorf("replace: unexpected report %+v", report)
+	}
+	stIndexes = fake.ddocs[repository.DefaultGeoDdoc]["st_indexes"].(map[string]any)
+	if got := stIndexes[repository.DefaultGeoIndex].(map[string]any)["index"]; got != repository.GeoIndexFunction {
+		t.Errorf("replace: got index function %q", got)
+	}
+	if _, ok := stIndexes["other"]; !ok {
+		t.Error("replace: expected other geo indexes to be kept")
+	}
+}
+
+// hubIDs returns the sorted IDs of hubs.
+func hubIDs(hubs []model.Hub) []string {
+	ids := make([]string, len(hubs))
+	for i, hub := range hubs {
+		ids[i] = hub.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
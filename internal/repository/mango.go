@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/IBM/cloudant-go-sdk/cloudantv1"
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultMangoDdoc and DefaultMangoIndex name the JSON index used by
+	// MangoRepository unless configured otherwise.
+	DefaultMangoDdoc  = "hubfinder-mango"
+	DefaultMangoIndex = "lat-lon"
+)
+
+// mangoIndexFields are the fields of the JSON index, in order.
+var mangoIndexFields = []string{"lat", "lon"}
+
+// Compile-time checks that MangoRepository implements the optional
+// repository interfaces.
+var (
+	_ DetailedRepository = (*MangoRepository)(nil)
+	_ IDGetter           = (*MangoRepository)(nil)
+	_ ChangesSource      = (*MangoRepository)(nil)
+	_ WritableRepository = (*MangoRepository)(nil)
+)
+
+// MangoRepository answers bounds queries with Mango _find range selectors
+// backed by a JSON index on lat and lon, for CouchDB deployments without
+// the search service. Document lookups, the changes feed and writes work as
+// in CloudantRepository.
+type MangoRepository struct {
+	*cloudantClient
+}
+
+// NewMangoRepository creates a repository for the database in cfg. Ddoc and
+// Index name the JSON index and default to DefaultMangoDdoc and
+// DefaultMangoIndex. CoerceStrings has no effect on bounds queries, as
+// range selectors only match numbers.
+func NewMangoRepository(cfg CloudantConfig) (*MangoRepository, error) {
+	if cfg.Ddoc == "" {
+		cfg.Ddoc = DefaultMangoDdoc
+	}
+	if cfg.Index == "" {
+		cfg.Index = DefaultMangoIndex
+	}
+	client, err := newCloudantClient(cfg, "mango")
+	if err != nil {
+		return nil, err
+	}
+	return &MangoRepository{cloudantClient: client}, nil
+}
+
+// buildMangoSelector constructs a _find selector for hubs within the given
+// bounds. A box wrapping the antimeridian becomes two longitude ranges
+// combined with $or.
+func buildMangoSelector(minLat, maxLat, minLon, maxLon float64) map[string]any {
+	between := func(lo, hi float64) map[string]any {
+		return map[string]any{"$gte": lo, "$lte": hi}
+	}
+	if minLon > maxLon {
+		return map[string]any{
+			"lat": between(minLat, maxLat),
+			"$or": []any{
+				map[string]any{"lon": between(minLon, 180)},
+				map[string]any{"lon": between(-180, maxLon)},
+			},
+		}
+	}
+	return map[string]any{
+		"lat": between(minLat, maxLat),
+		"lon": between(minLon, maxLon),
+	}
+}
+
+func (r *MangoRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// GetByBoundsDetailed retrieves all hubs within the specified geographic
+// bounds with _find, following bookmarks until a page comes back short, and
+// applies the configured RowPolicy to malformed documents.
+func (r *MangoRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (_ Result, err error) {
+	start := time.Now()
+
+	ctx, span := r.tracer.Start(ctx, "MangoRepository.GetByBounds", trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(telemetry.BoundsAttributes(minLat, maxLat, minLon, maxLon)...)
+	span.SetAttributes(
+		attribute.String("db.system", "couchdb"),
+		attribute.String("db.namespace", r.db),
+	)
+	pages := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("hubfinder.pages", pages))
+		r.metrics.AddBackendPages("mango", pages)
+		r.metrics.ObserveQuery("backend_find", time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}()
+
+	options := &cloudantv1.PostFindOptions{
+		Db:       new(r.db),
+		Selector: buildMangoSelector(minLat, maxLat, minLon, maxLon),
+		Fields:   []string{"_id", "lat", "lon", "name", "type", "country", "routes", "passenger_class"},
+		Limit:    core.Int64Ptr(pageSize),
+		UseIndex: []string{r.ddoc, r.index},
+	}
+	r.logger.DebugContext(ctx, "find started", "db", r.db, "ddoc", r.ddoc, "index", r.index, "selector", options.Selector)
+
+	rows := rowCollector{decoder: r.decoder, policy: r.policy, hubs: make([]model.Hub, 0, pageSize)}
+	for {
+		pageStart := time.Now()
+		result, response, err := r.service.PostFindWithContext(ctx, options)
+		if err != nil {
+			return Result{}, newBackendError(ctx, "post find", statusCode(response), err)
+		}
+		pages++
+		r.logger.DebugContext(ctx, "find page fetched",
+			"page", pages,
+			"bookmark", derefString(options.Bookmark),
+			"docs", len(result.Docs),
+			"duration", time.Since(pageStart),
+		)
+		if result.Warning != nil {
+			r.logger.WarnContext(ctx, "find warning", "warning", *result.Warning)
+		}
+
+		for _, doc := range result.Docs {
+			warning, err := rows.add(doc.ID, doc.GetProperties())
+			if err != nil {
+				return Result{}, err
+			}
+			if warning != nil {
+				r.logger.DebugContext(ctx, "skipped malformed row", "id", warning.ID, "reason", warning.Reason)
+			}
+		}
+
+		if len(result.Docs) < pageSize || derefString(result.Bookmark) == "" {
+			break
+		}
+		options.Bookmark = result.Bookmark
+	}
+
+	span.SetAttributes(
+		attribute.Int("hubfinder.result_count", len(rows.hubs)),
+		attribute.Int("hubfinder.skipped_rows", rows.skipped),
+	)
+	r.logger.DebugContext(ctx, "find finished",
+		"pages", pages,
+		"hubs", len(rows.hubs),
+		"skipped", rows.skipped,
+		"duration", time.Since(start),
+	)
+	return rows.result(), nil
+}
+
+// Provision creates the database and the JSON index on lat and lon that
+// bounds queries use. An existing index with the same name but different
+// fields is reported as a mismatch unless opts.Replace is set, in which case
+// it is deleted and recreated.
+func (r *MangoRepository) Provision(ctx context.Context, opts ProvisionOptions) (report ProvisionReport, err error) {
+	ctx, finish := r.startWrite(ctx, "MangoRepository.Provision", "backend_provision", "")
+	defer func() { finish(err) }()
+
+	if report.CreatedDatabase, err = r.createDatabase(ctx); err != nil {
+		return report, err
+	}
+
+	indexes, response, err := r.service.GetIndexesInformationWithContext(ctx, &cloudantv1.GetIndexesInformationOptions{Db: new(r.db)})
+	if err != nil {
+		return report, newBackendError(ctx, "get indexes", statusCode(response), err)
+	}
+
+	ddocID := "_design/" + r.ddoc
+	name := ddocID + "/" + r.index
+	var existing *cloudantv1.IndexInformation
+	for i, index := range indexes.Indexes {
+		if derefString(index.Ddoc) == ddocID && derefString(index.Name) == r.index {
+			existing = &indexes.Indexes[i]
+		}
+	}
+
+	switch {
+	case existing == nil:
+		report.Created = append(report.Created, name)
+	case sameMangoIndex(existing):
+		return report, nil
+	case !opts.Replace:
+		report.Mismatches = append(report.Mismatches, fmt.Sprintf("%s: %s", name, describeMangoIndex(existing)))
+		return report, fmt.Errorf("%s: %w", name, ErrIndexMismatch)
+	default:
+		_, response, err := r.service.DeleteIndexWithContext(ctx, &cloudantv1.DeleteIndexOptions{
+			Db:    new(r.db),
+			Ddoc:  new(r.ddoc),
+			Type:  new(derefString(existing.Type)),
+			Index: new(r.index),
+		})
+		if err != nil && statusCode(response) != http.StatusNotFound {
+			return report, newBackendError(ctx, "delete index", statusCode(response), err)
+		}
+		report.Replaced = append(report.Replaced, name)
+	}
+
+	fields := make([]cloudantv1.IndexField, len(mangoIndexFields))
+	for i, field := range mangoIndexFields {
+		fields[i].SetProperty(field, new("asc"))
+	}
+	_, response, err = r.service.PostIndexWithContext(ctx, &cloudantv1.PostIndexOptions{
+		Db:    new(r.db),
+		Ddoc:  new(r.ddoc),
+		Name:  new(r.index),
+		Type:  new("json"),
+		Index: &cloudantv1.IndexDefinition{Fields: fields},
+	})
+	if err != nil {
+		return report, newBackendError(ctx, "post index", statusCode(response), err)
+	}
+	r.logger.InfoContext(ctx, "json index written", "index", name)
+	return report, nil
+}
+
+// sameMangoIndex reports whether index is a JSON index on exactly the
+// mangoIndexFields in ascending order.
+func sameMangoIndex(index *cloudantv1.IndexInformation) bool {
+	if derefString(index.Type) != "json" || index.Def == nil || len(index.Def.Fields) != len(mangoIndexFields) {
+		return false
+	}
+	if len(index.Def.PartialFilterSelector) != 0 {
+		return false
+	}
+	for i, field := range index.Def.Fields {
+		properties := field.GetProperties()
+		if len(properties) != 1 || derefString(properties[mangoIndexFields[i]]) != "asc" {
+			return false
+		}
+	}
+	return true
+}
+
+// describeMangoIndex summarizes index for mismatch reports.
+func describeMangoIndex(index *cloudantv1.IndexInformation) string {
+	var fields []string
+	if index.Def != nil {
+		for _, field := range index.Def.Fields {
+			for name, direction := range field.GetProperties() {
+				fields = append(fields, name+" "+derefString(direction))
+			}
+		}
+	}
+	return fmt.Sprintf("is a %s index on %v, want a json index on %v", derefString(index.Type), fields, mangoIndexFields)
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+)
+
+func newTestMangoRepository(t *testing.T, fake *fakeCloudant, cfg repository.CloudantConfig) *repository.MangoRepository {
+	t.Helper()
+
+	cfg.BaseURL = fake.server.URL
+	cfg.DB = "airportdb"
+	repo, err := repository.NewMangoRepository(cfg)
+	if err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+	return repo
+}
+
+func TestMangoRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		return newTestMangoRepository(t, newFakeCloudant(t, hubDocs(hubs)), repository.CloudantConfig{})
+	})
+}
+
+func TestMangoRepository_PagesAndUsesIndex(t *testing.T) {
+	hubs := make([]model.Hub, 0, 450)
+	for i := range 450 {
+		hubs = append(hubs, model.Hub{ID: fmt.Sprintf("hub-%d", i), Name: "Hub", Lat: 1, Lon: 1})
+	}
+	fake := newFakeCloudant(t, hubDocs(hubs))
+	repo := newTestMangoRepository(t, fake, repository.CloudantConfig{})
+
+	got, err := repo.GetByBounds(context.Background(), 0, 2, 0, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 450 {
+		t.Errorf("got %d hubs, want 450", len(got))
+	}
+	if len(fake.findIndexes) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(fake.findIndexes))
+	}
+	if want := []string{repository.DefaultMangoDdoc, repository.DefaultMangoIndex}; !slices.Equal(fake.findIndexes[0], want) {
+		t.Errorf("use_index = %v, want %v", fake.findIndexes[0], want)
+	}
+}
+
+func TestMangoRepository_RowPolicy(t *testing.T) {
+	docs := []map[string]any{
+		{"_id": "good", "lat": 47.43, "lon": 19.26, "name": "Budapest"},
+		{"_id": "no-name", "lat": 47.2, "lon": 19.3},
+	}
+
+	repo := newTestMangoRepository(t, newFakeCloudant(t, docs), repository.CloudantConfig{RowPolicy: repository.RowPolicyWarn})
+	result, err := repo.GetByBoundsDetailed(context.Background(), 40, 50, 10, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Hubs) != 1 || len(result.Warnings) != 1 || result.Warnings[0].ID != "no-name" {
+		t.Errorf("got hubs %v and warnings %v", result.Hubs, result.Warnings)
+	}
+
+	repo = newTestMangoRepository(t, newFakeCloudant(t, docs), repository.CloudantConfig{RowPolicy: repository.RowPolicyFail})
+	var malformed *repository.MalformedRowError
+	if _, err := repo.GetByBounds(context.Background(), 40, 50, 10, 20); !errors.As(err, &malformed) {
+		t.Errorf("expected *MalformedRowError, got %v", err)
+	}
+}
+
+func TestMangoRepository_Provision(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeCloudant(t, nil)
+	fake.dbMissing = true
+	repo := newTestMangoRepository(t, fake, repository.CloudantConfig{})
+
+	report, err := repo.Provision(ctx, repository.ProvisionOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	name := "_design/" + repository.DefaultMangoDdoc + "/" + repository.DefaultMangoIndex
+	if !report.CreatedDatabase || !slices.Equal(report.Created, []string{name}) {
+		t.Errorf("first run: unexpected report %+v", report)
+	}
+
+	if report, err = repo.Provision(ctx, repository.ProvisionOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.CreatedDatabase || len(report.Created) != 0 || len(report.Replaced) != 0 {
+		t.Errorf("second run: expected no changes, got %+v", report)
+	}
+
+	fake.indexes[0]["def"] = map[string]any{"fields": []any{map[string]any{"lon": "asc"}}}
+	report, err = repo.Provision(ctx, repository.ProvisionOptions{})
+	if !errors.Is(err, repository.ErrIndexMismatch) || len(report.Mismatches) != 1 {
+		t.Fatalf("expected a mismatch, got %+v, %v", report, err)
+	}
+
+	if report, err = repo.Provision(ctx, repository.ProvisionOptions{Replace: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(report.Replaced, []string{name}) || len(fake.indexes) != 1 {
+		t.Errorf("replace: unexpected report %+v with indexes %v", report, fake.indexes)
+	}
+	if fields := fake.indexes[0]["def"].(map[string]any)["fields"].([]any); len(fields) != 2 {
+		t.Errorf("expected the index on lat and lon, got %v", fields)
+	}
+}
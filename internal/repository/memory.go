@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// Compile-time check that MemoryRepository implements IDGetter.
+var _ IDGetter = (*MemoryRepository)(nil)
+
+// MemoryRepository serves hubs held in memory, such as those of a Snapshot.
+// It is safe for concurrent use because it never modifies its hubs.
+type MemoryRepository struct {
+	hubs []model.Hub
+	byID map[string]int
+}
+
+// NewMemoryRepository returns a repository holding a copy of hubs.
+func NewMemoryRepository(hubs []model.Hub) *MemoryRepository {
+	r := &MemoryRepository{
+		hubs: append([]model.Hub(nil), hubs...),
+		byID: make(map[string]int, len(hubs)),
+	}
+	for i, hub := range r.hubs {
+		if _, exists := r.byID[hub.ID]; !exists {
+			r.byID[hub.ID] = i
+		}
+	}
+	return r
+}
+
+func (r *MemoryRepository) GetByBounds(_ context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	hubs := make([]model.Hub, 0)
+	for _, hub := range r.hubs {
+		if hub.Lat < minLat || hub.Lat > maxLat {
+			continue
+		}
+		if minLon <= maxLon {
+			if hub.Lon < minLon || hub.Lon > maxLon {
+				continue
+			}
+		} else if hub.Lon < minLon && hub.Lon > maxLon {
+			continue
+		}
+		hubs = append(hubs, hub)
+	}
+	return hubs, nil
+}
+
+func (r *MemoryRepository) GetHub(_ context.Context, id string) (model.Hub, error) {
+	i, ok := r.byID[id]
+	if !ok {
+		return model.Hub{}, fmt.Errorf("hub %q: %w", id, ErrNotFound)
+	}
+	return r.hubs[i], nil
+}
+
+// Len returns the number of hubs in the repository.
+func (r *MemoryRepository) Len() int {
+	return len(r.hubs)
+}
@@ -0,0 +1,33 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+)
+
+func TestMemoryRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		return repository.NewMemoryRepository(hubs)
+	})
+}
+
+func TestMemoryRepository_GetHub(t *testing.T) {
+	repo := repository.NewMemoryRepository(repositorytest.Fixture())
+
+	got, err := repo.GetHub(context.Background(), "bud")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "Budapest" {
+		t.Errorf("got %+v, want Budapest", got)
+	}
+
+	if _, err := repo.GetHub(context.Background(), "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/names"
+)
+
+// Compile-time checks that MultiRepository implements the optional
+// repository interfaces.
+var (
+	_ DetailedRepository = (*MultiRepository)(nil)
+	_ IDGetter           = (*MultiRepository)(nil)
+	_ RadiusSearcher     = (*MultiRepository)(nil)
+	_ NearestSearcher    = (*MultiRepository)(nil)
+	_ TextSearcher       = (*MultiRepository)(nil)
+)
+
+// FailurePolicy decides what MultiRepository does when some of its sources
+// fail.
+type FailurePolicy int
+
+const (
+	// FailurePolicyFail fails the whole query if any source fails.
+	FailurePolicyFail FailurePolicy = iota
+	// FailurePolicyPartial returns the hubs of the sources that answered
+	// and reports the others as SourceWarnings. The query still fails if
+	// every source does.
+	FailurePolicyPartial
+)
+
+func (p FailurePolicy) String() string {
+	switch p {
+	case FailurePolicyFail:
+		return "fail"
+	case FailurePolicyPartial:
+		return "partial"
+	default:
+		return fmt.Sprintf("FailurePolicy(%d)", int(p))
+	}
+}
+
+// ParseFailurePolicy parses the textual form of a FailurePolicy ("fail" or
+// "partial").
+func ParseFailurePolicy(s string) (FailurePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "fail":
+		return FailurePolicyFail, nil
+	case "partial":
+		return FailurePolicyPartial, nil
+	default:
+		return 0, fmt.Errorf("invalid failure policy %q: must be fail or partial", s)
+	}
+}
+
+// SourceWarning reports a source that was left out of a result because it
+// failed under FailurePolicyPartial.
+type SourceWarning struct {
+	Source string
+	Err    error
+}
+
+func (w SourceWarning) String() string {
+	return fmt.Sprintf("source %s: %v", w.Source, w.Err)
+}
+
+// Source is one of the repositories combined by MultiRepository.
+type Source struct {
+	// Name identifies the source in Hub.Source, warnings and logs.
+	Name       string
+	Repository Repository
+}
+
+// defaultNameSimilarity is the name similarity above which nearby hubs are
+// treated as duplicates unless configured otherwise.
+const defaultNameSimilarity = 0.8
+
+// MultiRepository combines several repositories into one. Queries go to
+// every source concurrently, each hub is tagged with the name of its
+// source, and duplicates are resolved in favour of the earlier source.
+//
+// Radius, nearest and text queries are passed to each source that
+// implements RadiusSearcher, NearestSearcher or TextSearcher, and answered
+// with a bounds query by the others. MultiRepository does not implement
+// Counter: the sources' counts cannot be added up, as hubs found by several
+// sources are only dropped once they are fetched.
+type MultiRepository struct {
+	sources        []Source
+	policy         FailurePolicy
+	duplicateKm    float64
+	nameSimilarity float64
+	logger         *slog.Logger
+}
+
+type MultiConfig struct {
+	// Sources lists the repositories to combine in priority order: when
+	// two sources return the same hub, the copy from the earlier one wins.
+	Sources []Source
+	// FailurePolicy decides whether a failing source fails the query. The
+	// zero value does.
+	FailurePolicy FailurePolicy
+	// DuplicateRadiusKm makes hubs from different sources duplicates when
+	// they are at most this far apart and their names are similar. Zero
+	// only treats hubs with the same ID as duplicates.
+	DuplicateRadiusKm float64
+	// NameSimilarity is the minimum names.Similarity of two nearby hubs for
+	// them to be duplicates. Defaults to 0.8.
+	NameSimilarity float64
+	// Logger receives source failures and duplicate counts. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+func NewMultiRepository(cfg MultiConfig) (*MultiRepository, error) {
+	if len(cfg.Sources) == 0 {
+		return nil, fmt.Errorf("multi repository: at least one source is required")
+	}
+	seen := make(map[string]bool, len(cfg.Sources))
+	for i, source := range cfg.Sources {
+		switch {
+		case source.Name == "":
+			return nil, fmt.Errorf("multi repository: source %d has no name", i)
+		case source.Repository == nil:
+			return nil, fmt.Errorf("multi repository: source %s has no repository", source.Name)
+		case seen[source.Name]:
+			return nil, fmt.Errorf("multi repository: duplicate source name %s", source.Name)
+		}
+		seen[source.Name] = true
+	}
+	if cfg.DuplicateRadiusKm < 0 {
+		return nil, fmt.Errorf("multi repository: duplicate radius cannot be negative")
+	}
+
+	r := &MultiRepository{
+		sources:        cfg.Sources,
+		policy:         cfg.FailurePolicy,
+		duplicateKm:    cfg.DuplicateRadiusKm,
+		nameSimilarity: cfg.NameSimilarity,
+		logger:         cfg.Logger,
+	}
+	if r.nameSimilarity <= 0 {
+		r.nameSimilarity = defaultNameSimilarity
+	}
+	if r.logger == nil {
+		r.logger = slog.Default()
+	}
+	r.logger = r.logger.With("component", "repository", "backend", "multi")
+	return r, nil
+}
+
+func (r *MultiRepository) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	result, err := r.GetByBoundsDetailed(ctx, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hubs, nil
+}
+
+// GetByBoundsDetailed queries every source concurrently and merges their
+// hubs, warnings and staleness. Failed sources are handled according to the
+// FailurePolicy.
+func (r *MultiRepository) GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (Result, error) {
+	return r.query(ctx, func(repo Repository) (Result, error) {
+		return getByBoundsDetailed(ctx, repo, minLat, maxLat, minLon, maxLon)
+	})
+}
+
+// GetByRadius merges the hubs within radiusKm of the given point from every
+// source, like GetByBoundsDetailed.
+func (r *MultiRepository) GetByRadius(ctx context.Context, lat, lon, radiusKm float64) (Result, error) {
+	return r.query(ctx, func(repo Repository) (Result, error) {
+		return getByRadius(ctx, repo, lat, lon, radiusKm)
+	})
+}
+
+// GetNearest asks every source for its count hubs closest to the given
+// point and returns the closest count of the merged hubs. A hub is missing
+// from the result if it duplicates one that an earlier source found but did
+// not return among its closest count.
+func (r *MultiRepository) GetNearest(ctx context.Context, lat, lon float64, count int, maxRadiusKm float64) (Result, error) {
+	result, err := r.query(ctx, func(repo Repository) (Result, error) {
+		return getNearest(ctx, repo, lat, lon, count, maxRadiusKm)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	result.Hubs = nearestFirst(result.Hubs, lat, lon, count)
+	return result, nil
+}
+
+// SearchText merges the hubs whose name contains every term of text from
+// every source, like GetByBoundsDetailed.
+func (r *MultiRepository) SearchText(ctx context.Context, text string) (Result, error) {
+	return r.query(ctx, func(repo Repository) (Result, error) {
+		return searchText(ctx, repo, text)
+	})
+}
+
+// query runs fn against every source concurrently and merges the results.
+func (r *MultiRepository) query(ctx context.Context, fn func(Repository) (Result, error)) (Result, error) {
+	results := make([]Result, len(r.sources))
+	errs := make([]error, len(r.sources))
+	var wg sync.WaitGroup
+	for i, source := range r.sources {
+		wg.Go(func() {
+			results[i], errs[i] = fn(source.Repository)
+		})
+	}
+	wg.Wait()
+
+	var merged Result
+	answered := make([][]model.Hub, 0, len(r.sources))
+	for i, source := range r.sources {
+		if err := errs[i]; err != nil {
+			if r.policy != FailurePolicyPartial || ctx.Err() != nil {
+				return Result{}, fmt.Errorf("source %s: %w", source.Name, err)
+			}
+			r.logger.WarnContext(ctx, "source failed, returning partial results", "source", source.Name, "error", err)
+			merged.SourceWarnings = append(merged.SourceWarnings, SourceWarning{Source: source.Name, Err: err})
+			continue
+		}
+		result := results[i]
+		for j := range result.Hubs {
+			result.Hubs[j].Source = source.Name
+		}
+		answered = append(answered, result.Hubs)
+		merged.Warnings = append(merged.Warnings, result.Warnings...)
+		merged.SourceWarnings = append(merged.SourceWarnings, result.SourceWarnings...)
+		if result.Stale {
+			merged.Stale = true
+			merged.SnapshotAge = max(merged.SnapshotAge, result.SnapshotAge)
+		}
+	}
+	if len(answered) == 0 {
+		failures := make([]error, len(errs))
+		for i, err := range errs {
+			failures[i] = fmt.Errorf("source %s: %w", r.sources[i].Name, err)
+		}
+		return Result{}, fmt.Errorf("every source failed: %w", errors.Join(failures...))
+	}
+
+	var duplicates int
+	merged.Hubs, duplicates = r.merge(answered)
+	if duplicates > 0 {
+		r.logger.DebugContext(ctx, "duplicates dropped", "duplicates", duplicates, "hubs", len(merged.Hubs))
+	}
+	return merged, nil
+}
+
+// merge combines the hubs of the sources in priority order, dropping hubs
+// whose ID was already seen and, if DuplicateRadiusKm is set, hubs close to
+// a similarly named hub of an earlier source. It returns the kept hubs and
+// the number dropped.
+func (r *MultiRepository) merge(sources [][]model.Hub) ([]model.Hub, int) {
+	var kept []model.Hub
+	ids := make(map[string]bool)
+	dropped := 0
+	for _, hubs := range sources {
+		// Only hubs of earlier sources are candidates, so the index is
+		// rebuilt once per source rather than for every kept hub.
+		earlier := newLatIndex(kept)
+		for _, hub := range hubs {
+			if ids[hub.ID] || r.duplicateKm > 0 && earlier.hasDuplicate(hub, r.duplicateKm, r.nameSimilarity) {
+				dropped++
+				continue
+			}
+			ids[hub.ID] = true
+			kept = append(kept, hub)
+		}
+	}
+	return kept, dropped
+}
+
+// latIndex holds hubs sorted by latitude, so the hubs near a point can be
+// found without comparing against all of them.
+type latIndex []model.Hub
+
+func newLatIndex(hubs []model.Hub) latIndex {
+	index := latIndex(append([]model.Hub(nil), hubs...))
+	sort.Slice(index, func(i, j int) bool { return index[i].Lat < index[j].Lat })
+	return index
+}
+
+// hasDuplicate reports whether the index holds a hub within radiusKm of hub
+// whose name is at least minSimilarity alike.
+func (index latIndex) hasDuplicate(hub model.Hub, radiusKm, minSimilarity float64) bool {
+	// A degree of latitude is never shorter than this, so hubs outside the
+	// window are too far away.
+	window := radiusKm / (geo.EarthRadiusKm * math.Pi / 180)
+	start := sort.Search(len(index), func(i int) bool { return index[i].Lat >= hub.Lat-window })
+	for _, candidate := range index[start:] {
+		if candidate.Lat > hub.Lat+window {
+			break
+		}
+		distanceKm, err := geo.HaversineDistance(hub.Lat, hub.Lon, candidate.Lat, candidate.Lon)
+		if err != nil || distanceKm > radiusKm {
+			continue
+		}
+		if names.Similarity(hub.Name, candidate.Name) >= minSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+// GetHub asks the sources in priority order and returns the first copy of
+// the hub found, tagged with its source. Under FailurePolicyPartial, failed
+// sources are skipped; the error wraps ErrNotFound only if no source failed.
+func (r *MultiRepository) GetHub(ctx context.Context, id string) (model.Hub, error) {
+	var failures []error
+	for _, source := range r.sources {
+		var (
+			hub model.Hub
+			err error
+		)
+		if getter, ok := source.Repository.(IDGetter); ok {
+			hub, err = getter.GetHub(ctx, id)
+		} else {
+			hub, err = scanForHub(ctx, source.Repository, id)
+		}
+		switch {
+		case err == nil:
+			hub.Source = source.Name
+			return hub, nil
+		case errors.Is(err, ErrNotFound):
+			continue
+		case r.policy != FailurePolicyPartial || ctx.Err() != nil:
+			return model.Hub{}, fmt.Errorf("source %s: %w", source.Name, err)
+		default:
+			r.logger.WarnContext(ctx, "source failed, skipping it", "source", source.Name, "error", err)
+			failures = append(failures, fmt.Errorf("source %s: %w", source.Name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return model.Hub{}, fmt.Errorf("hub %q not found in the sources that answered: %w", id, errors.Join(failures...))
+	}
+	return model.Hub{}, fmt.Errorf("hub %q: %w", id, ErrNotFound)
+}
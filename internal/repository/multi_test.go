@@ -0,0 +1,284 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository/repositorytest"
+)
+
+// unsourced hides Hub.Source, which the contract fixture does not set.
+type unsourced struct {
+	repository.Repository
+}
+
+func (r unsourced) GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error) {
+	hubs, err := r.Repository.GetByBounds(ctx, minLat, maxLat, minLon, maxLon)
+	for i := range hubs {
+		hubs[i].Source = ""
+	}
+	return hubs, err
+}
+
+func newTestMultiRepository(t *testing.T, cfg repository.MultiConfig) *repository.MultiRepository {
+	t.Helper()
+	repo, err := repository.NewMultiRepository(cfg)
+	if err != nil {
+		t.Fatalf("create multi repository: %v", err)
+	}
+	return repo
+}
+
+func TestMultiRepository_Contract(t *testing.T) {
+	repositorytest.RunContractTests(t, func(t *testing.T, hubs []model.Hub) repository.Repository {
+		// Two overlapping thirds of the fixture, so that every hub comes
+		// from one source or both.
+		first, second := hubs[:len(hubs)*2/3], hubs[len(hubs)/3:]
+		return unsourced{newTestMultiRepository(t, repository.MultiConfig{
+			Sources: []repository.Source{
+				{Name: "first", Repository: repository.NewMemoryRepository(first)},
+				{Name: "second", Repository: repository.NewMemoryRepository(second)},
+			},
+		})}
+	})
+}
+
+func TestMultiRepository_Duplicates(t *testing.T) {
+	primary := []model.Hub{
+		{ID: "bud", Name: "Budapest Ferenc Liszt International", Lat: 47.4369, Lon: 19.2556},
+		{ID: "zrh", Name: "Zürich Airport", Lat: 47.4647, Lon: 8.5492},
+	}
+	secondary := []model.Hub{
+		{ID: "bud", Name: "Budapest (other copy)", Lat: 47.4369, Lon: 19.2556},
+		{ID: "LSZH", Name: "Zurich Airport", Lat: 47.4581, Lon: 8.5555},
+		{ID: "zrh-hb", Name: "Zürich HB", Lat: 47.3779, Lon: 8.5403},
+		{ID: "vie", Name: "Vienna International", Lat: 48.1103, Lon: 16.5697},
+	}
+
+	tests := []struct {
+		name         string
+		radiusKm     float64
+		reversed     bool
+		wantSourceOf map[string]string
+	}{
+		{
+			name:     "by ID only",
+			radiusKm: 0,
+			wantSourceOf: map[string]string{
+				"bud": "primary", "zrh": "primary", "LSZH": "secondary", "zrh-hb": "secondary", "vie": "secondary",
+			},
+		},
+		{
+			name:     "by proximity and name",
+			radiusKm: 2,
+			wantSourceOf: map[string]string{
+				"bud": "primary", "zrh": "primary", "zrh-hb": "secondary", "vie": "secondary",
+			},
+		},
+		{
+			name:     "priority follows source order",
+			radiusKm: 2,
+			reversed: true,
+			wantSourceOf: map[string]string{
+				"bud": "secondary", "LSZH": "secondary", "zrh-hb": "secondary", "vie": "secondary",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := []repository.Source{
+				{Name: "primary", Repository: repository.NewMemoryRepository(primary)},
+				{Name: "secondary", Repository: repository.NewMemoryRepository(secondary)},
+			}
+			if tt.reversed {
+				slices.Reverse(sources)
+			}
+			repo := newTestMultiRepository(t, repository.MultiConfig{Sources: sources, DuplicateRadiusKm: tt.radiusKm})
+
+			hubs, err := repo.GetByBounds(context.Background(), -90, 90, -180, 180)
+			if err != nil {
+				t.Fatalf("GetByBounds returned error: %v", err)
+			}
+			got := make(map[string]string, len(hubs))
+			for _, hub := range hubs {
+				got[hub.ID] = hub.Source
+			}
+			if len(got) != len(hubs) {
+				t.Errorf("got duplicate IDs in %v", hubs)
+			}
+			for id, want := range tt.wantSourceOf {
+				if got[id] != want {
+					t.Errorf("source of %s: got %q, want %q", id, got[id], want)
+				}
+			}
+			if len(got) != len(tt.wantSourceOf) {
+				t.Errorf("got hubs %v, want %v", hubIDs(hubs), slices.Sorted(maps.Keys(tt.wantSourceOf)))
+			}
+		})
+	}
+}
+
+func TestMultiRepository_FailurePolicy(t *testing.T) {
+	hubs := []model.Hub{{ID: "bud", Name: "Budapest", Lat: 47.437, Lon: 19.261}}
+	failure := errors.New("connection refused")
+
+	tests := []struct {
+		name         string
+		policy       repository.FailurePolicy
+		secondFails  bool
+		firstFails   bool
+		wantErr      bool
+		wantHubs     []string
+		wantWarnings []string
+	}{
+		{name: "all answer", policy: repository.FailurePolicyFail, wantHubs: []string{"bud"}},
+		{name: "fail on any failure", policy: repository.FailurePolicyFail, secondFails: true, wantErr: true},
+		{name: "partial results", policy: repository.FailurePolicyPartial, secondFails: true, wantHubs: []string{"bud"}, wantWarnings: []string{"second"}},
+		{name: "partial with every source failing", policy: repository.FailurePolicyPartial, firstFails: true, secondFails: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := &stubRepository{hubs: hubs}, &stubRepository{hubs: hubs}
+			if tt.firstFails {
+				first.err = failure
+			}
+			if tt.secondFails {
+				second.err = failure
+			}
+			repo := newTestMultiRepository(t, repository.MultiConfig{
+				Sources: []repository.Source{
+					{Name: "first", Repository: first},
+					{Name: "second", Repository: second},
+				},
+				FailurePolicy: tt.policy,
+			})
+
+			result, err := repo.GetByBoundsDetailed(context.Background(), -90, 90, -180, 180)
+			if tt.wantErr {
+				if !errors.Is(err, failure) {
+					t.Errorf("got error %v, want %v", err, failure)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetByBoundsDetailed returned error: %v", err)
+			}
+			if got := hubIDs(result.Hubs); !slices.Equal(got, tt.wantHubs) {
+				t.Errorf("got hubs %v, want %v", got, tt.wantHubs)
+			}
+			var warned []string
+			for _, warning := range result.SourceWarnings {
+				warned = append(warned, warning.Source)
+				if !errors.Is(warning.Err, failure) {
+					t.Errorf("warning for %s: got error %v, want %v", warning.Source, warning.Err, failure)
+				}
+			}
+			if !slices.Equal(warned, tt.wantWarnings) {
+				t.Errorf("got warnings for %v, want %v", warned, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestMultiRepository_GetHub(t *testing.T) {
+	repo := newTestMultiRepository(t, repository.MultiConfig{
+		Sources: []repository.Source{
+			{Name: "first", Repository: repository.NewMemoryRepository([]model.Hub{{ID: "bud", Name: "Budapest", Lat: 47.437, Lon: 19.261}})},
+			{Name: "second", Repository: repository.NewMemoryRepository([]model.Hub{
+				{ID: "bud", Name: "Budapest (second)", Lat: 47.437, Lon: 19.261},
+				{ID: "vie", Name: "Vienna", Lat: 48.110, Lon: 16.570},
+			})},
+		},
+	})
+
+	tests := []struct {
+		id         string
+		wantSource string
+		wantName   string
+	}{
+		{id: "bud", wantSource: "first", wantName: "Budapest"},
+		{id: "vie", wantSource: "second", wantName: "Vienna"},
+	}
+	for _, tt := range tests {
+		hub, err := repo.GetHub(context.Background(), tt.id)
+		if err != nil {
+			t.Fatalf("GetHub(%q) returned error: %v", tt.id, err)
+		}
+		if hub.Source != tt.wantSource || hub.Name != tt.wantName {
+			t.Errorf("GetHub(%q): got %s from %s, want %s from %s", tt.id, hub.Name, hub.Source, tt.wantName, tt.wantSource)
+		}
+	}
+
+	if _, err := repo.GetHub(context.Background(), "nope"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetHub(missing): got error %v, want ErrNotFound", err)
+	}
+}
+
+func TestMultiRepository_Capabilities(t *testing.T) {
+	ctx := context.Background()
+	native := &capableRepository{stubRepository: stubRepository{hubs: []model.Hub{{ID: "bud", Name: "Budapest", Lat: 47.437, Lon: 19.261}}}}
+	repo := newTestMultiRepository(t, repository.MultiConfig{
+		Sources: []repository.Source{
+			{Name: "native", Repository: native},
+			{Name: "bounds", Repository: repository.NewMemoryRepository([]model.Hub{
+				{ID: "bud", Name: "Budapest (bounds)", Lat: 47.437, Lon: 19.261},
+				{ID: "vie", Name: "Vienna", Lat: 48.110, Lon: 16.570},
+				{ID: "jfk", Name: "New York JFK", Lat: 40.640, Lon: -73.779},
+			})},
+		},
+	})
+	if _, ok := any(repo).(repository.Counter); ok {
+		t.Error("MultiRepository implements Counter, want the finder to count merged hubs")
+	}
+
+	// sourced lists the hubs of result as ID@source in their order.
+	sourced := func(result repository.Result, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, hub := range result.Hubs {
+			got = append(got, hub.ID+"@"+hub.Source)
+		}
+		return got
+	}
+
+	if got, want := sourced(repo.GetByRadius(ctx, 47.5, 19, 300)), []string{"bud@native", "vie@bounds"}; !slices.Equal(got, want) {
+		t.Errorf("GetByRadius: got %v, want %v", got, want)
+	}
+	if got, want := sourced(repo.GetNearest(ctx, 47.5, 15, 2, 1000)), []string{"vie@bounds", "bud@native"}; !slices.Equal(got, want) {
+		t.Errorf("GetNearest: got %v, want %v", got, want)
+	}
+	if got, want := sourced(repo.SearchText(ctx, "new york")), []string{"bud@native", "jfk@bounds"}; !slices.Equal(got, want) {
+		t.Errorf("SearchText: got %v, want %v", got, want)
+	}
+	if want := []string{"GetByRadius", "GetNearest", "SearchText"}; !slices.Equal(native.calls, want) {
+		t.Errorf("got native calls %v, want %v", native.calls, want)
+	}
+}
+
+func TestNewMultiRepository_Validation(t *testing.T) {
+	memory := repository.NewMemoryRepository(nil)
+	tests := []struct {
+		name    string
+		sources []repository.Source
+	}{
+		{name: "no sources"},
+		{name: "unnamed source", sources: []repository.Source{{Repository: memory}}},
+		{name: "missing repository", sources: []repository.Source{{Name: "a"}}},
+		{name: "duplicate names", sources: []repository.Source{{Name: "a", Repository: memory}, {Name: "a", Repository: memory}}},
+	}
+	for _, tt := range tests {
+		if _, err := repository.NewMultiRepository(repository.MultiConfig{Sources: tt.sources}); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
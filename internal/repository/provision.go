@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/IBM/cloudant-go-sdk/cloudantv1"
+)
+
+// SearchIndexFunction is the search index function CloudantRepository
+// queries. lat and lon are indexed as numbers for range queries and stored
+// with name so results need no extra document reads; the richer optional
+// fields are indexed when present.
+const SearchIndexFunction = `function (doc) {
+  if (typeof doc.lat === "number" && typeof doc.lon === "number") {
+    index("lat", doc.lat, {"store": true});
+    index("lon", doc.lon, {"store": true});
+  }
+  if (typeof doc.name === "string") {
+    index("name", doc.name, {"store": true});
+  }
+  ["type", "country", "city", "iata", "icao", "passenger_class"].forEach(function (field) {
+    if (typeof doc[field] === "string") {
+      index(field, doc[field], {"store": true});
+    }
+  });
+  if (typeof doc.routes === "number") {
+    index("routes", doc.routes, {"store": true});
+  }
+}`
+
+// searchAnalyzer is the analyzer of the search index.
+const searchAnalyzer = "standard"
+
+// ErrIndexMismatch is returned by Provision when an existing index differs
+// from the one the repository expects.
+var ErrIndexMismatch = errors.New("existing index does not match the expected definition")
+
+// Provisioner is implemented by repositories that can create the database
+// and indexes they query.
+type Provisioner interface {
+	Provision(ctx context.Context, opts ProvisionOptions) (ProvisionReport, error)
+}
+
+// Compile-time checks that the Cloudant repositories implement Provisioner.
+var (
+	_ Provisioner = (*CloudantRepository)(nil)
+	_ Provisioner = (*MangoRepository)(nil)
+	_ Provisioner = (*GeoRepository)(nil)
+)
+
+// ProvisionOptions controls Provision.
+type ProvisionOptions struct {
+	// Replace overwrites existing indexes that differ from the expected
+	// definitions instead of reporting them.
+	Replace bool
+}
+
+// ProvisionReport describes the changes made by Provision.
+type ProvisionReport struct {
+	CreatedDatabase bool
+	// Created and Replaced name the indexes written, as "_design/ddoc/index".
+	Created  []string
+	Replaced []string
+	// Mismatches describes the existing indexes that differ from the
+	// expected definitions and were left alone.
+	Mismatches []string
+}
+
+// Provision creates the database and the design document with the search
+// index the repository queries, leaving whatever already matches alone.
+// Other indexes and views in the design document are kept. If an existing
+// index differs and opts.Replace is not set, the report lists the
+// differences and the error wraps ErrIndexMismatch.
+func (r *CloudantRepository) Provision(ctx context.Context, opts ProvisionOptions) (report ProvisionReport, err error) {
+	ctx, finish := r.startWrite(ctx, "CloudantRepository.Provision", "backend_provision", "")
+	defer func() { finish(err) }()
+
+	if report.CreatedDatabase, err = r.createDatabase(ctx); err != nil {
+		return report, err
+	}
+
+	ddocID := "_design/" + r.ddoc
+	ddoc, response, err := r.service.GetDesignDocumentWithContext(ctx, &cloudantv1.GetDesignDocumentOptions{
+		Db:   new(r.db),
+		Ddoc: new(r.ddoc),
+	})
+	switch {
+	case statusCode(response) == http.StatusNotFound:
+		ddoc = &cloudantv1.DesignDocument{}
+	case err != nil:
+		return report, newBackendError(ctx, "get design document", statusCode(response), err)
+	}
+	if ddoc.Indexes == nil {
+		ddoc.Indexes = map[string]cloudantv1.SearchIndexDefinition{}
+	}
+
+	name := ddocID + "/" + r.index
+	existing, exists := ddoc.Indexes[r.index]
+	switch {
+	case !exists:
+		report.Created = append(report.Created, name)
+	case sameSearchIndex(existing):
+		return report, nil
+	case opts.Replace:
+		report.Replaced = append(report.Replaced, name)
+	default:
+		report.Mismatches = append(report.Mismatches, fmt.Sprintf("%s: %s", name, describeSearchIndex(existing)))
+		return report, fmt.Errorf("%s: %w", name, ErrIndexMismatch)
+	}
+
+	ddoc.Indexes[r.index] = cloudantv1.SearchIndexDefinition{
+		Index:    new(SearchIndexFunction),
+		Analyzer: &cloudantv1.AnalyzerConfiguration{Name: new(searchAnalyzer)},
+	}
+	ddoc.Conflicts, ddoc.DeletedConflicts, ddoc.RevsInfo, ddoc.Revisions, ddoc.LocalSeq = nil, nil, nil, nil, nil
+	_, response, err = r.service.PutDesignDocumentWithContext(ctx, &cloudantv1.PutDesignDocumentOptions{
+		Db:             new(r.db),
+		Ddoc:           new(r.ddoc),
+		DesignDocument: ddoc,
+	})
+	if err != nil {
+		return report, writeError(ctx, "put design document", response, err)
+	}
+	r.logger.InfoContext(ctx, "search index written", "index", name)
+	return report, nil
+}
+
+// createDatabase creates the database unless it exists, reporting whether
+// it did.
+func (r *cloudantClient) createDatabase(ctx context.Context) (bool, error) {
+	_, response, err := r.service.PutDatabaseWithContext(ctx, &cloudantv1.PutDatabaseOptions{Db: new(r.db)})
+	switch {
+	case err == nil:
+		r.logger.InfoContext(ctx, "database created", "db", r.db)
+		return true, nil
+	case statusCode(response) == http.StatusPreconditionFailed:
+		return false, nil
+	default:
+		return false, newBackendError(ctx, "put database", statusCode(response), err)
+	}
+}
+
+// sameSearchIndex reports whether def matches SearchIndexFunction, ignoring
+// differences in whitespace.
+func sameSearchIndex(def cloudantv1.SearchIndexDefinition) bool {
+	return sameAnalyzer(def.Analyzer) && normalizeFunction(derefString(def.Index)) == normalizeFunction(SearchIndexFunction)
+}
+
+// sameAnalyzer reports whether analyzer is the expected one. A missing
+// analyzer means the standard one.
+func sameAnalyzer(analyzer *cloudantv1.AnalyzerConfiguration) bool {
+	if analyzer == nil {
+		return true
+	}
+	return derefString(analyzer.Name) == searchAnalyzer && analyzer.Default == nil && len(analyzer.Fields) == 0
+}
+
+// describeSearchIndex explains how def differs from the expected index.
+func describeSearchIndex(def cloudantv1.SearchIndexDefinition) string {
+	var problems []string
+	if normalizeFunction(derefString(def.Index)) != normalizeFunction(SearchIndexFunction) {
+		problems = append(problems, "index function differs")
+	}
+	if !sameAnalyzer(def.Analyzer) {
+		problems = append(problems, fmt.Sprintf("analyzer is %q, want %q", derefString(def.Analyzer.Name), searchAnalyzer))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// normalizeFunction collapses whitespace so that reformatted functions
+// compare equal.
+func normalizeFunction(f string) string {
+	return strings.Join(strings.Fields(f), " ")
+}
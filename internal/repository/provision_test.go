@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+func TestCloudantRepository_Provision(t *testing.T) {
+	otherIndex := map[string]any{"index": "function (doc) { index(\"x\", doc.x); }"}
+	expected := map[string]any{"index": repository.SearchIndexFunction, "analyzer": map[string]any{"name": "standard"}}
+
+	tests := []struct {
+		name           string
+		dbMissing      bool
+		ddoc           map[string]any
+		replace        bool
+		wantDB         bool
+		wantCreated    []string
+		wantReplaced   []string
+		wantMismatches int
+		wantWrite      bool
+	}{
+		{
+			name:        "empty server",
+			dbMissing:   true,
+			wantDB:      true,
+			wantCreated: []string{"_design/view1/geo"},
+			wantWrite:   true,
+		},
+		{
+			name:        "design document without the index",
+			ddoc:        map[string]any{"_rev": "1-fake", "indexes": map[string]any{"other": otherIndex}},
+			wantCreated: []string{"_design/view1/geo"},
+			wantWrite:   true,
+		},
+		{
+			name: "matching index reformatted",
+			ddoc: map[string]any{"_rev": "1-fake", "indexes": map[string]any{
+				"geo": map[string]any{"index": "  " + repository.SearchIndexFunction + "\n"},
+			}},
+		},
+		{
+			name:           "different index",
+			ddoc:           map[string]any{"_rev": "1-fake", "indexes": map[string]any{"geo": otherIndex}},
+			wantMismatches: 1,
+		},
+		{
+			name:         "different index replaced",
+			ddoc:         map[string]any{"_rev": "1-fake", "indexes": map[string]any{"geo": otherIndex}},
+			replace:      true,
+			wantReplaced: []string{"_design/view1/geo"},
+			wantWrite:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCloudant(t, nil)
+			fake.dbMissing = tt.dbMissing
+			if tt.ddoc != nil {
+				fake.ddocs["view1"] = tt.ddoc
+			}
+			repo := newTestCloudantRepository(t, fake, nil)
+
+			report, err := repo.Provision(context.Background(), repository.ProvisionOptions{Replace: tt.replace})
+			if tt.wantMismatches > 0 {
+				if !errors.Is(err, repository.ErrIndexMismatch) {
+					t.Fatalf("expected ErrIndexMismatch, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if report.CreatedDatabase != tt.wantDB {
+				t.Errorf("CreatedDatabase = %v, want %v", report.CreatedDatabase, tt.wantDB)
+			}
+			if !slices.Equal(report.Created, tt.wantCreated) || !slices.Equal(report.Replaced, tt.wantReplaced) {
+				t.Errorf("Created = %v, Replaced = %v, want %v and %v", report.Created, report.Replaced, tt.wantCreated, tt.wantReplaced)
+			}
+			if len(report.Mismatches) != tt.wantMismatches {
+				t.Errorf("Mismatches = %v, want %d", report.Mismatches, tt.wantMismatches)
+			}
+
+			written := fake.ddocs["view1"]
+			if tt.wantWrite {
+				indexes, _ := written["indexes"].(map[string]any)
+				if got := indexes["geo"].(map[string]any)["index"]; got != expected["index"] {
+					t.Errorf("written index = %v, want the expected function", got)
+				}
+				if tt.ddoc != nil && tt.ddoc["indexes"].(map[string]any)["other"] != nil && indexes["other"] == nil {
+					t.Error("expected other indexes to be kept")
+				}
+			} else if tt.ddoc != nil && written["_rev"] != "1-fake" {
+				t.Errorf("expected the design document to be left alone, got revision %v", written["_rev"])
+			}
+		})
+	}
+}
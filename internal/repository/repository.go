@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"unicode"
 
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
 	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
 )
 
@@ -11,3 +14,58 @@ type Repository interface {
 	// GetByBounds retrieves all hubs within the specified geographic bounds
 	GetByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]model.Hub, error)
 }
+
+// IDGetter is implemented by repositories that can look up a hub by ID
+// directly.
+type IDGetter interface {
+	// GetHub returns the hub with the given ID, or an error wrapping
+	// ErrNotFound if there is none.
+	GetHub(ctx context.Context, id string) (model.Hub, error)
+}
+
+// RadiusSearcher is implemented by repositories that can find the hubs
+// within a distance of a point natively, without fetching the whole
+// bounding box of the circle.
+type RadiusSearcher interface {
+	// GetByRadius returns the hubs within radiusKm of the given point.
+	GetByRadius(ctx context.Context, lat, lon, radiusKm float64) (Result, error)
+}
+
+// PolygonSearcher is implemented by repositories that can find the hubs
+// inside a polygon natively, without fetching its whole bounding box.
+type PolygonSearcher interface {
+	// GetByPolygon returns the hubs inside polygon or on its boundary.
+	GetByPolygon(ctx context.Context, polygon []geo.Point) (Result, error)
+}
+
+// NearestSearcher is implemented by repositories that can find the hubs
+// closest to a point natively, without widening a search radius.
+type NearestSearcher interface {
+	// GetNearest returns up to count hubs within maxRadiusKm of the given
+	// point, closest first.
+	GetNearest(ctx context.Context, lat, lon float64, count int, maxRadiusKm float64) (Result, error)
+}
+
+// TextSearcher is implemented by repositories that can search hub names
+// natively, without scanning every hub.
+type TextSearcher interface {
+	// SearchText returns the hubs whose name contains every term of
+	// TextTerms(text), in any order.
+	SearchText(ctx context.Context, text string) (Result, error)
+}
+
+// Counter is implemented by repositories that can count the hubs within
+// bounds without fetching them.
+type Counter interface {
+	// CountByBounds returns the number of hubs GetByBounds would return
+	// for the same bounds.
+	CountByBounds(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (int, error)
+}
+
+// TextTerms splits text into the lower-case words matched by text
+// searches, the way the standard analyzer of the search index does.
+func TextTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,186 @@
+// Package repositorytest provides a conformance suite that every
+// repository.Repository implementation is expected to pass.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/repository"
+)
+
+// Factory returns a repository that holds exactly the given hubs. It is
+// called once per subtest, so implementations may use t.Cleanup to release
+// any resources they allocate.
+type Factory func(t *testing.T, hubs []model.Hub) repository.Repository
+
+// propertyIterations is the number of random bounding boxes checked against
+// the brute-force filter.
+const propertyIterations = 200
+
+// Fixture returns the hub data set used by the contract tests. Coordinates
+// sit on a 0.001 degree grid so that backends which format bounds with
+// limited precision still see the exact boundary values.
+func Fixture() []model.Hub {
+	return []model.Hub{
+		{ID: "origin", Name: "Null Island", Lat: 0, Lon: 0},
+		{ID: "nyc", Name: "New York", Lat: 40.713, Lon: -74.006},
+		{ID: "jfk", Name: "JFK Airport", Lat: 40.641, Lon: -73.778},
+		{ID: "ewr", Name: "Newark Airport", Lat: 40.69, Lon: -74.175},
+		{ID: "lhr", Name: "Heathrow", Lat: 51.47, Lon: -0.454},
+		{ID: "bud", Name: "Budapest", Lat: 47.437, Lon: 19.261},
+		{ID: "syd", Name: "Sydney", Lat: -33.946, Lon: 151.177},
+		{ID: "fiji-east", Name: "Fiji East", Lat: -17.755, Lon: 179.5},
+		{ID: "fiji-west", Name: "Fiji West", Lat: -17.755, Lon: -179.5},
+		{ID: "antimeridian-east", Name: "Antimeridian East", Lat: 10, Lon: 180},
+		{ID: "antimeridian-west", Name: "Antimeridian West", Lat: -10, Lon: -180},
+		{ID: "north-pole", Name: "North Pole", Lat: 90, Lon: 0},
+		{ID: "south-pole", Name: "South Pole", Lat: -90, Lon: 0},
+		{ID: "mcmurdo", Name: "McMurdo", Lat: -77.846, Lon: 166.676},
+		{ID: "svalbard", Name: "Svalbard", Lat: 78.246, Lon: 15.466},
+		{ID: "corner-sw", Name: "Corner South West", Lat: 20, Lon: 30},
+		{ID: "corner-ne", Name: "Corner North East", Lat: 21, Lon: 31},
+		{ID: "same-name-1", Name: "Twin", Lat: -5.5, Lon: 100.25},
+		{ID: "same-name-2", Name: "Twin", Lat: -5.5, Lon: 100.25},
+	}
+}
+
+// FilterByBounds is the reference implementation of the GetByBounds contract:
+// bounds are inclusive on every side and a minLon greater than maxLon means
+// the box wraps across the antimeridian.
+func FilterByBounds(hubs []model.Hub, minLat, maxLat, minLon, maxLon float64) []model.Hub {
+	filtered := make([]model.Hub, 0)
+	for _, h := range hubs {
+		if h.Lat < minLat || h.Lat > maxLat {
+			continue
+		}
+		if minLon <= maxLon {
+			if h.Lon < minLon || h.Lon > maxLon {
+				continue
+			}
+		} else if h.Lon < minLon && h.Lon > maxLon {
+			continue
+		}
+		filtered = append(filtered, h)
+	}
+	return filtered
+}
+
+// RunContractTests runs the repository conformance suite against the
+// repositories built by factory.
+func RunContractTests(t *testing.T, factory Factory) {
+	t.Helper()
+
+	boxes := []struct {
+		name                           string
+		minLat, maxLat, minLon, maxLon float64
+	}{
+		{"inclusive bounds", 20, 21, 30, 31},
+		{"zero-size box on hub", 40.713, 40.713, -74.006, -74.006},
+		{"zero-size box without hub", 12, 12, 34, 34},
+		{"wrapped longitudes", -20, 20, 179, -179},
+		{"wrapped longitudes touching antimeridian", -20, 20, 180, -180},
+		{"full globe", -90, 90, -180, 180},
+		{"polar cap", 89, 90, -180, 180},
+		{"duplicate coordinates", -6, -5, 100, 101},
+		{"empty result", -60, -50, -40, -30},
+	}
+
+	for _, box := range boxes {
+		t.Run(box.name, func(t *testing.T) {
+			fixture := Fixture()
+			repo := factory(t, fixture)
+			checkBounds(t, repo, fixture, box.minLat, box.maxLat, box.minLon, box.maxLon)
+		})
+	}
+
+	t.Run("empty repository", func(t *testing.T) {
+		repo := factory(t, nil)
+		checkBounds(t, repo, nil, -90, 90, -180, 180)
+	})
+
+	t.Run("random boxes match brute force", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(26, 2026))
+		fixture := randomHubs(rng, 300)
+		repo := factory(t, fixture)
+		for i := range propertyIterations {
+			minLat, maxLat, minLon, maxLon := randomBox(rng)
+			t.Run(fmt.Sprintf("box %d", i), func(t *testing.T) {
+				checkBounds(t, repo, fixture, minLat, maxLat, minLon, maxLon)
+			})
+		}
+	})
+}
+
+func checkBounds(t *testing.T, repo repository.Repository, fixture []model.Hub, minLat, maxLat, minLon, maxLon float64) {
+	t.Helper()
+
+	got, err := repo.GetByBounds(context.Background(), minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		t.Fatalf("GetByBounds(%g, %g, %g, %g) returned error: %v", minLat, maxLat, minLon, maxLon, err)
+	}
+
+	want := FilterByBounds(fixture, minLat, maxLat, minLon, maxLon)
+	sortByID(got)
+	sortByID(want)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("GetByBounds(%g, %g, %g, %g)\ngot:  %v\nwant: %v", minLat, maxLat, minLon, maxLon, got, want)
+	}
+}
+
+func sortByID(hubs []model.Hub) {
+	slices.SortFunc(hubs, func(a, b model.Hub) int {
+		if a.ID < b.ID {
+			return -1
+		}
+		if a.ID > b.ID {
+			return 1
+		}
+		return 0
+	})
+}
+
+// randomHubs returns n hubs on the 0.001 degree grid. Every fifth hub copies
+// the coordinates of an earlier one to exercise ties on box edges.
+func randomHubs(rng *rand.Rand, n int) []model.Hub {
+	hubs := make([]model.Hub, 0, n)
+	for i := range n {
+		lat, lon := gridValue(rng, 90), gridValue(rng, 180)
+		if i > 0 && i%5 == 0 {
+			prev := hubs[rng.IntN(len(hubs))]
+			lat, lon = prev.Lat, prev.Lon
+		}
+		hubs = append(hubs, model.Hub{
+			ID:   fmt.Sprintf("hub-%03d", i),
+			Name: fmt.Sprintf("Hub %d", i),
+			Lat:  lat,
+			Lon:  lon,
+		})
+	}
+	return hubs
+}
+
+// randomBox returns a bounding box on the same grid as randomHubs. Roughly a
+// quarter of the boxes wrap across the antimeridian.
+func randomBox(rng *rand.Rand) (minLat, maxLat, minLon, maxLon float64) {
+	minLat, maxLat = gridValue(rng, 90), gridValue(rng, 90)
+	if minLat > maxLat {
+		minLat, maxLat = maxLat, minLat
+	}
+	minLon, maxLon = gridValue(rng, 180), gridValue(rng, 180)
+	if minLon > maxLon && rng.IntN(4) != 0 {
+		minLon, maxLon = maxLon, minLon
+	}
+	return minLat, maxLat, minLon, maxLon
+}
+
+// gridValue returns a value in [-limit, limit] that is a multiple of 0.001.
+func gridValue(rng *rand.Rand, limit float64) float64 {
+	steps := int(limit * 1000)
+	return float64(rng.IntN(2*steps+1)-steps) / 1000
+}
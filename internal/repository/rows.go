@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// RowPolicy controls what happens to backend rows that cannot be converted
+// into a hub, for example because a field is missing or has the wrong type.
+type RowPolicy int
+
+const (
+	// RowPolicySkip drops malformed rows silently.
+	RowPolicySkip RowPolicy = iota
+	// RowPolicyWarn drops malformed rows and reports them as warnings
+	// alongside the results.
+	RowPolicyWarn
+	// RowPolicyFail aborts the query with a *MalformedRowError.
+	RowPolicyFail
+)
+
+func (p RowPolicy) String() string {
+	switch p {
+	case RowPolicySkip:
+		return "skip"
+	case RowPolicyWarn:
+		return "warn"
+	case RowPolicyFail:
+		return "fail"
+	default:
+		return fmt.Sprintf("RowPolicy(%d)", int(p))
+	}
+}
+
+// ParseRowPolicy parses the textual form of a RowPolicy ("skip", "warn" or "fail").
+func ParseRowPolicy(s string) (RowPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "skip":
+		return RowPolicySkip, nil
+	case "warn":
+		return RowPolicyWarn, nil
+	case "fail":
+		return RowPolicyFail, nil
+	default:
+		return 0, fmt.Errorf("invalid row policy %q: must be skip, warn or fail", s)
+	}
+}
+
+// RowWarning describes a backend row that was left out of the results.
+type RowWarning struct {
+	// ID is the document ID of the row, or empty if the row had none.
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+func (w RowWarning) String() string {
+	id := w.ID
+	if id == "" {
+		id = "<missing id>"
+	}
+	return fmt.Sprintf("row %s: %s", id, w.Reason)
+}
+
+// MalformedRowError is returned under RowPolicyFail when a row cannot be
+// converted into a hub.
+type MalformedRowError struct {
+	RowWarning
+}
+
+func (e *MalformedRowError) Error() string {
+	return "malformed " + e.RowWarning.String()
+}
+
+// ErrorType labels the error in metrics and traces.
+func (e *MalformedRowError) ErrorType() string {
+	return "malformed_row"
+}
+
+// Result is the detailed outcome of a bounds query.
+type Result struct {
+	Hubs []model.Hub
+	// Warnings lists the rows skipped under RowPolicyWarn.
+	Warnings []RowWarning
+	// SourceWarnings lists the sources of a MultiRepository that failed
+	// and were left out under FailurePolicyPartial.
+	SourceWarnings []SourceWarning
+	// Stale reports that the hubs come from a snapshot rather than the live
+	// backend, and SnapshotAge how old that snapshot is.
+	Stale       bool
+	SnapshotAge time.Duration
+}
+
+// DetailedRepository is implemented by repositories that can report more
+// about a bounds query than the hubs it matched.
+type DetailedRepository interface {
+	Repository
+	// GetByBoundsDetailed behaves like GetByBounds but also returns
+	// warnings about rows that were left out.
+	GetByBoundsDetailed(ctx context.Context, minLat, maxLat, minLon, maxLon float64) (Result, error)
+}
+
+// rowDecoder converts raw backend rows into hubs.
+type rowDecoder struct {
+	// coerceStrings accepts coordinates encoded as strings, such as "47.43".
+	coerceStrings bool
+	// keepOutOfRange accepts coordinates outside ±90 latitude or ±180
+	// longitude.
+	keepOutOfRange bool
+}
+
+// rowCollector gathers the hubs of a query, applying a RowPolicy to the
+// rows that cannot be decoded.
+type rowCollector struct {
+	decoder  rowDecoder
+	policy   RowPolicy
+	hubs     []model.Hub
+	warnings []RowWarning
+	skipped  int
+}
+
+// add decodes a row. It returns a *MalformedRowError under RowPolicyFail,
+// and otherwise the warning for a skipped row, if any.
+func (c *rowCollector) add(id *string, fields map[string]any) (*RowWarning, error) {
+	hub, warning, ok := c.decoder.decode(id, fields)
+	if ok {
+		c.hubs = append(c.hubs, hub)
+		return nil, nil
+	}
+
+	switch c.policy {
+	case RowPolicyFail:
+		return nil, &MalformedRowError{RowWarning: warning}
+	case RowPolicyWarn:
+		c.warnings = append(c.warnings, warning)
+	}
+	c.skipped++
+	return &warning, nil
+}
+
+// result returns the collected hubs and warnings.
+func (c *rowCollector) result() Result {
+	hubs := c.hubs
+	if hubs == nil {
+		hubs = make([]model.Hub, 0)
+	}
+	return Result{Hubs: hubs, Warnings: c.warnings}
+}
+
+// decode converts a row with the given document ID and stored fields into a
+// hub. If the row is malformed, ok is false and warning describes why.
+func (d rowDecoder) decode(id *string, fields map[string]any) (hub model.Hub, warning RowWarning, ok bool) {
+	if id == nil || *id == "" {
+		return model.Hub{}, RowWarning{Reason: "missing id"}, false
+	}
+	warning.ID = *id
+	if fields == nil {
+		warning.Reason = "missing fields"
+		return model.Hub{}, warning, false
+	}
+
+	lat, reason := d.coordinate(fields, "lat", 90)
+	if reason != "" {
+		warning.Reason = reason
+		return model.Hub{}, warning, false
+	}
+	lon, reason := d.coordinate(fields, "lon", 180)
+	if reason != "" {
+		warning.Reason = reason
+		return model.Hub{}, warning, false
+	}
+
+	rawName, present := fields["name"]
+	if !present || rawName == nil {
+		warning.Reason = "missing name"
+		return model.Hub{}, warning, false
+	}
+	name, isString := rawName.(string)
+	if !isString {
+		warning.Reason = fmt.Sprintf("name has unexpected type %T", rawName)
+		return model.Hub{}, warning, false
+	}
+
+	// The remaining fields are optional, so values of the wrong type are
+	// ignored rather than making the row malformed.
+	hub = model.Hub{ID: *id, Lat: lat, Lon: lon, Name: name}
+	hub.Type, _ = fields["type"].(string)
+	hub.Country, _ = fields["country"].(string)
+	hub.PassengerClass, _ = fields["passenger_class"].(string)
+	if routes, isNumber := fields["routes"].(float64); isNumber && routes >= 0 && routes <= math.MaxInt32 && routes == math.Trunc(routes) {
+		hub.Routes = int(routes)
+	}
+
+	return hub, RowWarning{}, true
+}
+
+func (d rowDecoder) coordinate(fields map[string]any, key string, limit float64) (float64, string) {
+	raw, present := fields[key]
+	if !present || raw == nil {
+		return 0, "missing " + key
+	}
+
+	var value float64
+	switch v := raw.(type) {
+	case float64:
+		value = v
+	case string:
+		if !d.coerceStrings {
+			return 0, fmt.Sprintf("%s is a string (%q), not a number", key, v)
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Sprintf("%s is not a valid number: %q", key, v)
+		}
+		value = parsed
+	default:
+		return 0, fmt.Sprintf("%s has unexpected type %T", key, raw)
+	}
+
+	if math.IsNaN(value) || !d.keepOutOfRange && (value < -limit || value > limit) {
+		return 0, fmt.Sprintf("%s %g is out of range", key, value)
+	}
+	return value, ""
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+func TestParseRowPolicy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RowPolicy
+		wantErr  bool
+	}{
+		{input: "skip", expected: RowPolicySkip},
+		{input: "warn", expected: RowPolicyWarn},
+		{input: " FAIL ", expected: RowPolicyFail},
+		{input: "ignore", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseRowPolicy(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %v", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("got %v, want %v", result, tt.expected)
+			}
+			if result.String() != tt.expected.String() {
+				t.Errorf("String() = %q, want %q", result.String(), tt.expected.String())
+			}
+		})
+	}
+}
+
+func TestRowDecoderDecode(t *testing.T) {
+	id := "hub1"
+	empty := ""
+
+	tests := []struct {
+		name       string
+		id         *string
+		fields     map[string]any
+		coerce     bool
+		expected   model.Hub
+		wantReason string
+	}{
+		{
+			name:     "valid row",
+			id:       &id,
+			fields:   map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest"},
+			expected: model.Hub{ID: "hub1", Lat: 47.43, Lon: 19.26, Name: "Budapest"},
+		},
+		{
+			name:     "with type and country",
+			id:       &id,
+			fields:   map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest", "type": "large_airport", "country": "HU"},
+			expected: model.Hub{ID: "hub1", Lat: 47.43, Lon: 19.26, Name: "Budapest", Type: "large_airport", Country: "HU"},
+		},
+		{
+			name:     "with routes and passenger class",
+			id:       &id,
+			fields:   map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest", "routes": 140.0, "passenger_class": "medium"},
+			expected: model.Hub{ID: "hub1", Lat: 47.43, Lon: 19.26, Name: "Budapest", Routes: 140, PassengerClass: "medium"},
+		},
+		{
+			name:     "fractional routes are ignored",
+			id:       &id,
+			fields:   map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest", "routes": 1.5},
+			expected: model.Hub{ID: "hub1", Lat: 47.43, Lon: 19.26, Name: "Budapest"},
+		},
+		{
+			name:     "type of the wrong kind is ignored",
+			id:       &id,
+			fields:   map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest", "type": 3.0},
+			expected: model.Hub{ID: "hub1", Lat: 47.43, Lon: 19.26, Name: "Budapest"},
+		},
+		{
+			name:       "missing id",
+			fields:     map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest"},
+			wantReason: "missing id",
+		},
+		{
+			name:       "empty id",
+			id:         &empty,
+			fields:     map[string]any{"lat": 47.43, "lon": 19.26, "name": "Budapest"},
+			wantReason: "missing id",
+		},
+		{
+			name:       "missing fields",
+			id:         &id,
+			wantReason: "missing fields",
+		},
+		{
+			name:       "missing lat",
+			id:         &id,
+			fields:     map[string]any{"lon": 19.26, "name": "Budapest"},
+			wantReason: "missing lat",
+		},
+		{
+			name:       "string lat without coercion",
+			id:         &id,
+			fields:     map[string]any{"lat": "47.43", "lon": 19.26, "name": "Budapest"},
+			wantReason: `lat is a string ("47.43"), not a number`,
+		},
+		{
+			name:     "string coordinates with coercion",
+			id:       &id,
+			fields:   map[string]any{"lat": " 47.43 ", "lon": "19.26", "name": "Budapest"},
+			coerce:   true,
+			expected: model.Hub{ID: "hub1", Lat: 47.43, Lon: 19.26, Name: "Budapest"},
+		},
+		{
+			name:       "non-numeric string with coercion",
+			id:         &id,
+			fields:     map[string]any{"lat": 47.43, "lon": "east", "name": "Budapest"},
+			coerce:     true,
+			wantReason: `lon is not a valid number: "east"`,
+		},
+		{
+			name:       "boolean lon",
+			id:         &id,
+			fields:     map[string]any{"lat": 47.43, "lon": true, "name": "Budapest"},
+			wantReason: "lon has unexpected type bool",
+		},
+		{
+			name:       "lat out of range",
+			id:         &id,
+			fields:     map[string]any{"lat": 147.43, "lon": 19.26, "name": "Budapest"},
+			wantReason: "lat 147.43 is out of range",
+		},
+		{
+			name:       "coerced lon out of range",
+			id:         &id,
+			fields:     map[string]any{"lat": 47.43, "lon": "-180.5", "name": "Budapest"},
+			coerce:     true,
+			wantReason: "lon -180.5 is out of range",
+		},
+		{
+			name:       "missing name",
+			id:         &id,
+			fields:     map[string]any{"lat": 47.43, "lon": 19.26},
+			wantReason: "missing name",
+		},
+		{
+			name:       "numeric name",
+			id:         &id,
+			fields:     map[string]any{"lat": 47.43, "lon": 19.26, "name": 42.0},
+			wantReason: "name has unexpected type float64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub, warning, ok := rowDecoder{coerceStrings: tt.coerce}.decode(tt.id, tt.fields)
+			if tt.wantReason != "" {
+				if ok {
+					t.Fatalf("expected row to be rejected, got %+v", hub)
+				}
+				if warning.Reason != tt.wantReason {
+					t.Errorf("reason = %q, want %q", warning.Reason, tt.wantReason)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("unexpected rejection: %s", warning)
+			}
+			if hub != tt.expected {
+				t.Errorf("got %+v, want %+v", hub, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// Snapshot is a point-in-time copy of the hub database, stored as JSON so
+// that searches can be answered while the backend is unreachable.
+type Snapshot struct {
+	// CreatedAt is when the data was read from the backend.
+	CreatedAt time.Time `json:"created_at"`
+	// Seq is the changes feed sequence the snapshot is current to, if it
+	// is kept up to date by "hubfinder sync".
+	Seq  string      `json:"seq,omitempty"`
+	Hubs []model.Hub `json:"hubs"`
+}
+
+// LoadSnapshot reads a snapshot written by WriteSnapshot.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	if snapshot.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("parse snapshot %s: missing created_at", path)
+	}
+	return &snapshot, nil
+}
+
+// WriteSnapshot stores snapshot at path. The file is replaced atomically, so
+// readers never see a partially written snapshot.
+func WriteSnapshot(path string, snapshot *Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
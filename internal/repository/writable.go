@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/geo"
+	"github.com/osvathbotond/cloudant-airportdb-go/internal/model"
+)
+
+// WritableRepository is implemented by repositories that can create, update
+// and delete hubs. Writes use optimistic concurrency: each hub has a
+// revision that changes on every write, and a write based on an outdated
+// revision fails with ErrConflict.
+type WritableRepository interface {
+	// Revision returns the current revision of the hub with the given ID,
+	// or an error wrapping ErrNotFound if there is none.
+	Revision(ctx context.Context, id string) (string, error)
+	// Put stores hub and returns its new revision. An empty rev creates the
+	// hub and fails with ErrConflict if it already exists; otherwise rev
+	// must be the current revision of the hub being replaced.
+	Put(ctx context.Context, hub model.Hub, rev string) (string, error)
+	// Delete removes the hub with the given ID at revision rev.
+	Delete(ctx context.Context, id, rev string) error
+	// BulkUpsert creates or replaces hubs regardless of their current
+	// revision, so repeating it with the same hubs has no further effect.
+	// The result reports the outcome for each hub, in order; the
+	// error is only set if the request as a whole failed.
+	BulkUpsert(ctx context.Context, hubs []model.Hub) ([]WriteResult, error)
+}
+
+// WriteResult is the outcome of writing one hub in a bulk request.
+type WriteResult struct {
+	ID string
+	// Rev is the new revision of the hub if the write succeeded.
+	Rev string
+	// Unchanged reports that the stored hub already matched, so nothing
+	// was written and Rev is the existing revision.
+	Unchanged bool
+	// Err is set if the hub was not written. It wraps ErrConflict if the
+	// hub was changed concurrently.
+	Err error
+}
+
+// ValidateHub checks that hub has an ID and valid coordinates, so it can
+// be written to a repository.
+func ValidateHub(hub model.Hub) error {
+	if hub.ID == "" {
+		return ErrMissingID
+	}
+	return geo.ValidateCoordinates(hub.Lat, hub.Lon)
+}
@@ -0,0 +1,130 @@
+// Package telemetry holds the Prometheus metrics and OpenTelemetry helpers
+// shared by the finder and repository packages.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hubfinder"
+
+// Error types used as the "type" label of the errors counter.
+const (
+	ErrorTypeCanceled = "canceled"
+	ErrorTypeTimeout  = "timeout"
+	ErrorTypeOther    = "other"
+)
+
+// Metrics records query latency, backend pagination, errors and cache
+// lookups. A nil *Metrics is valid and records nothing, so callers never
+// need to check whether metrics are enabled.
+type Metrics struct {
+	queryDuration *prometheus.HistogramVec
+	backendPages  *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	cacheLookups  *prometheus.CounterVec
+}
+
+// NewMetrics creates the collectors and registers them with reg.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Latency of hub queries by operation and outcome.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"operation", "outcome"}),
+		backendPages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "backend_pages_fetched_total",
+			Help:      "Number of result pages fetched from the backend.",
+		}, []string{"backend"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of failed operations by operation and error type.",
+		}, []string{"operation", "type"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Number of cache lookups by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.queryDuration, m.backendPages, m.errors, m.cacheLookups} {
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("register metric: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveQuery records the duration and outcome of an operation and, if err
+// is not nil, counts it under its error type.
+func (m *Metrics) ObserveQuery(operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		m.errors.WithLabelValues(operation, ClassifyError(err)).Inc()
+	}
+	m.queryDuration.WithLabelValues(operation, outcome).Observe(duration.Seconds())
+}
+
+// AddBackendPages counts pages fetched from the named backend.
+func (m *Metrics) AddBackendPages(backend string, pages int) {
+	if m == nil {
+		return
+	}
+	m.backendPages.WithLabelValues(backend).Add(float64(pages))
+}
+
+// ObserveCache counts a lookup in the named cache as a hit or a miss. The
+// hit rate is the share of hits among all lookups.
+func (m *Metrics) ObserveCache(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// ErrorTyper is implemented by errors that know their metric error type.
+type ErrorTyper interface {
+	ErrorType() string
+}
+
+// ClassifyError maps err to a low-cardinality error type label.
+func ClassifyError(err error) string {
+	var typed ErrorTyper
+	switch {
+	case errors.As(err, &typed):
+		return typed.ErrorType()
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTypeTimeout
+	default:
+		return ErrorTypeOther
+	}
+}
+
+// Handler serves the metrics registered with gatherer in the Prometheus
+// exposition format.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,110 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type typedError struct{}
+
+func (typedError) Error() string     { return "typed" }
+func (typedError) ErrorType() string { return "custom" }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"canceled", context.Canceled, ErrorTypeCanceled},
+		{"wrapped deadline", fmt.Errorf("post search: %w", context.DeadlineExceeded), ErrorTypeTimeout},
+		{"typed error", fmt.Errorf("wrapped: %w", typedError{}), "custom"},
+		{"plain error", errors.New("boom"), ErrorTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.expected {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetrics_ObserveQuery(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m.ObserveQuery("find_nearby", 10*time.Millisecond, nil)
+	m.ObserveQuery("find_nearby", 20*time.Millisecond, context.DeadlineExceeded)
+	m.ObserveQuery("find_nearby", 20*time.Millisecond, errors.New("boom"))
+	m.AddBackendPages("cloudant", 3)
+	m.ObserveCache("bounds", true)
+	m.ObserveCache("bounds", true)
+	m.ObserveCache("bounds", false)
+
+	if got := testutil.CollectAndCount(m.queryDuration); got != 2 {
+		t.Errorf("expected 2 latency series, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("find_nearby", ErrorTypeTimeout)); got != 1 {
+		t.Errorf("expected 1 timeout error, got %g", got)
+	}
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("find_nearby", ErrorTypeOther)); got != 1 {
+		t.Errorf("expected 1 other error, got %g", got)
+	}
+	if got := testutil.ToFloat64(m.backendPages.WithLabelValues("cloudant")); got != 3 {
+		t.Errorf("expected 3 pages, got %g", got)
+	}
+	if got := testutil.ToFloat64(m.cacheLookups.WithLabelValues("bounds", "hit")); got != 2 {
+		t.Errorf("expected 2 cache hits, got %g", got)
+	}
+	if got := testutil.ToFloat64(m.cacheLookups.WithLabelValues("bounds", "miss")); got != 1 {
+		t.Errorf("expected 1 cache miss, got %g", got)
+	}
+}
+
+func TestMetrics_DuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewMetrics(reg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewMetrics(reg); err == nil {
+		t.Error("expected error registering metrics twice")
+	}
+}
+
+func TestMetrics_NilIsNoOp(t *testing.T) {
+	var m *Metrics
+	m.ObserveQuery("find_nearby", time.Second, errors.New("boom"))
+	m.AddBackendPages("cloudant", 1)
+	m.ObserveCache("bounds", true)
+}
+
+func TestHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.AddBackendPages("cloudant", 2)
+
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `hubfinder_backend_pages_fetched_total{backend="cloudant"} 2`) {
+		t.Errorf("metrics output missing page counter:\n%s", body)
+	}
+}
@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/osvathbotond/cloudant-airportdb-go"
+
+// Span exporters accepted by NewTracerProvider.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// NewTracerProvider returns a tracer provider that batches spans to the
+// named exporter: ExporterOTLP sends them over OTLP/HTTP, configured by the
+// standard OTEL_EXPORTER_OTLP_* environment variables, and ExporterStdout
+// writes them to w as JSON. ExporterNone returns nil. Callers must shut the
+// provider down to flush the last spans.
+func NewTracerProvider(ctx context.Context, exporter, serviceName string, w io.Writer) (*sdktrace.TracerProvider, error) {
+	var (
+		spanExporter sdktrace.SpanExporter
+		err          error
+	)
+	switch exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("invalid trace exporter %q: must be none, stdout or otlp", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", exporter, err)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	), nil
+}
+
+// Tracer returns the tracer used for hubfinder spans, taken from tp or, if tp
+// is nil, from the global OpenTelemetry tracer provider.
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// BoundsAttributes describes a bounding box as span attributes.
+func BoundsAttributes(minLat, maxLat, minLon, maxLon float64) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Float64("hubfinder.bbox.min_lat", minLat),
+		attribute.Float64("hubfinder.bbox.max_lat", maxLat),
+		attribute.Float64("hubfinder.bbox.min_lon", minLon),
+		attribute.Float64("hubfinder.bbox.max_lon", maxLon),
+	}
+}
+
+// EndSpan records err on span, if any, and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("hubfinder.error.type", ClassifyError(err)))
+	}
+	span.End()
+}
@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	tp, err := NewTracerProvider(ctx, ExporterStdout, "hubfinder-test", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, span := Tracer(tp).Start(ctx, "Finder.FindNearby")
+	span.End()
+	if err := tp.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Finder.FindNearby") || !strings.Contains(out, "hubfinder-test") {
+		t.Errorf("expected the span and service name in the output, got %q", out)
+	}
+
+	if tp, err := NewTracerProvider(ctx, ExporterNone, "hubfinder-test", &buf); tp != nil || err != nil {
+		t.Errorf("got %v, %v for the none exporter, want nil, nil", tp, err)
+	}
+	if _, err := NewTracerProvider(ctx, "jaeger", "hubfinder-test", &buf); err == nil {
+		t.Error("expected error for an unknown exporter")
+	}
+}
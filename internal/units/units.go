@@ -0,0 +1,96 @@
+// Package units converts distances between the units users can choose for
+// input and output.
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unit is a unit of length.
+type Unit int
+
+const (
+	Kilometers Unit = iota
+	Miles
+	NauticalMiles
+	Meters
+)
+
+// Lengths of one unit in kilometers.
+const (
+	kmPerMile         = 1.609344
+	kmPerNauticalMile = 1.852
+	kmPerMeter        = 0.001
+)
+
+// ParseUnit parses a unit symbol: km, mi, nmi or m.
+func ParseUnit(s string) (Unit, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "km":
+		return Kilometers, nil
+	case "mi":
+		return Miles, nil
+	case "nmi":
+		return NauticalMiles, nil
+	case "m":
+		return Meters, nil
+	default:
+		return 0, fmt.Errorf("invalid unit %q: must be km, mi, nmi or m", s)
+	}
+}
+
+// Symbol returns the abbreviation used in column headers, such as "km".
+func (u Unit) Symbol() string {
+	switch u {
+	case Miles:
+		return "mi"
+	case NauticalMiles:
+		return "nmi"
+	case Meters:
+		return "m"
+	default:
+		return "km"
+	}
+}
+
+// Name returns the plural unit name used in prompts, such as "kilometers".
+func (u Unit) Name() string {
+	switch u {
+	case Miles:
+		return "miles"
+	case NauticalMiles:
+		return "nautical miles"
+	case Meters:
+		return "meters"
+	default:
+		return "kilometers"
+	}
+}
+
+func (u Unit) String() string {
+	return u.Symbol()
+}
+
+// FromKm converts a distance in kilometers into u.
+func (u Unit) FromKm(km float64) float64 {
+	return km / u.kilometers()
+}
+
+// ToKm converts a distance in u into kilometers.
+func (u Unit) ToKm(value float64) float64 {
+	return value * u.kilometers()
+}
+
+func (u Unit) kilometers() float64 {
+	switch u {
+	case Miles:
+		return kmPerMile
+	case NauticalMiles:
+		return kmPerNauticalMile
+	case Meters:
+		return kmPerMeter
+	default:
+		return 1
+	}
+}
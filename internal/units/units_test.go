@@ -0,0 +1,80 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Unit
+		wantErr  bool
+	}{
+		{input: "km", expected: Kilometers},
+		{input: "MI", expected: Miles},
+		{input: " nmi ", expected: NauticalMiles},
+		{input: "m", expected: Meters},
+		{input: "ft", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseUnit(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %v", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("got %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		unit   Unit
+		km     float64
+		value  float64
+		symbol string
+	}{
+		{Kilometers, 100, 100, "km"},
+		{Miles, 1.609344, 1, "mi"},
+		{NauticalMiles, 1.852, 1, "nmi"},
+		{Meters, 1.5, 1500, "m"},
+		{Miles, 40075, 24901.4505, "mi"},
+		{NauticalMiles, 40075, 21638.769, "nmi"},
+		{Meters, 40075, 40075000, "m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit.Name(), func(t *testing.T) {
+			if got := tt.unit.FromKm(tt.km); math.Abs(got-tt.value) > 1e-3 {
+				t.Errorf("FromKm(%g) = %f, want %f", tt.km, got, tt.value)
+			}
+			if got := tt.unit.ToKm(tt.value); math.Abs(got-tt.km) > 1e-3 {
+				t.Errorf("ToKm(%g) = %f, want %f", tt.value, got, tt.km)
+			}
+			if tt.unit.Symbol() != tt.symbol {
+				t.Errorf("Symbol() = %q, want %q", tt.unit.Symbol(), tt.symbol)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, u := range []Unit{Kilometers, Miles, NauticalMiles, Meters} {
+		for _, km := range []float64{0, 0.001, 12.5, 40075} {
+			if got := u.ToKm(u.FromKm(km)); math.Abs(got-km) > 1e-9 {
+				t.Errorf("%s round trip of %g km gave %g", u, km, got)
+			}
+		}
+	}
+}